@@ -26,6 +26,9 @@ import (
 
 const (
 	OdooDeploymentKind = "OdooDeployment"
+	OdooBackupKind     = "OdooBackup"
+	OdooRestoreKind    = "OdooRestore"
+	OdooMigrationKind  = "OdooMigration"
 )
 
 var (
@@ -0,0 +1,337 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/objectstore"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+const (
+	BackupDatabaseDumpFileName     = "db.dump"
+	BackupFilestoreArchiveFileName = "filestore.tar.zst"
+	BackupManifestFileName         = "manifest.json"
+)
+
+// backupScript dumps the database and archives the filestore of the database into /backup,
+// then writes a manifest.json describing both artifacts. The manifest without its modules is
+// also written to the termination log so the operator can read it back from the pod status,
+// the termination message is truncated at 4096 bytes.
+const backupScript = `set -eu
+cd /backup
+pg_dump --format=custom --no-owner --no-acl --file="${DB_DUMP_FILE}"
+if [ -d "/filestore/${PGDATABASE}" ]; then
+  tar --zstd -cf "${FILESTORE_ARCHIVE_FILE}" -C "/filestore/${PGDATABASE}" .
+else
+  tar --zstd -cf "${FILESTORE_ARCHIVE_FILE}" -T /dev/null
+fi
+modules=$(printf '%s' "${ODOO_MODULES}" | sed -e 's/[^,][^,]*/"&"/g')
+summary=$(cat <<EOF
+"createdAt":"$(date -u +%Y-%m-%dT%H:%M:%SZ)","database":"${PGDATABASE}","odooImage":"${ODOO_IMAGE}","databaseDump":{"name":"${DB_DUMP_FILE}","size":$(stat -c %s "${DB_DUMP_FILE}"),"sha256":"$(sha256sum "${DB_DUMP_FILE}" | cut -d' ' -f1)"},"filestoreArchive":{"name":"${FILESTORE_ARCHIVE_FILE}","size":$(stat -c %s "${FILESTORE_ARCHIVE_FILE}"),"sha256":"$(sha256sum "${FILESTORE_ARCHIVE_FILE}" | cut -d' ' -f1)"}
+EOF
+)
+printf '{%s,"modules":[%s]}\n' "${summary}" "${modules}" > "${MANIFEST_FILE}"
+printf '{%s}\n' "${summary}" > /dev/termination-log
+`
+
+func (b *OdooBackup) GetBackupPvcName() string {
+	return b.Name
+}
+
+func (b *OdooBackup) GetBackupJobName() string {
+	return fmt.Sprintf("%s-backup", b.Name)
+}
+
+func (b *OdooBackup) GetBackupPvcTemplate() corev1.PersistentVolumeClaim {
+	pvc := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.GetBackupPvcName(),
+			Namespace: b.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &b.Spec.Storage.StorageClassName,
+			AccessModes:      b.Spec.Storage.AccessModes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: b.Spec.Storage.Size,
+				},
+			},
+		},
+	}
+	return pvc
+}
+
+// GetBackupObjectKeyPrefix returns the object store prefix of the backup artifacts,
+// <prefix>/<namespace>/<odoodeployment>/<timestamp>
+func (b *OdooBackup) GetBackupObjectKeyPrefix() string {
+	keyPrefix := []string{}
+	if b.Spec.S3 != nil {
+		keyPrefix = append(keyPrefix, strings.Trim(b.Spec.S3.Prefix, "/"))
+	}
+	keyPrefix = append(keyPrefix,
+		b.Namespace,
+		b.Spec.OdooDeploymentRef.Name,
+		b.CreationTimestamp.UTC().Format("20060102T150405Z"),
+	)
+	return strings.TrimPrefix(strings.Join(keyPrefix, "/"), "/")
+}
+
+// GetBackupLocation returns the s3:// URL of the backup artifacts, it is empty when the
+// backup is not uploaded to an object store
+func (b *OdooBackup) GetBackupLocation() string {
+	if b.Spec.S3 == nil {
+		return ""
+	}
+	return fmt.Sprintf("s3://%s/%s", b.Spec.S3.Bucket, b.GetBackupObjectKeyPrefix())
+}
+
+// GetObjectStoreConfig returns the configuration of a client for the object store, the
+// credentials are read from their secrets in the given namespace
+func (s *S3Config) GetObjectStoreConfig(client client.Client, ctx context.Context, namespace string) (objectstore.Config, error) {
+	accessKey, err := utils.GetSecretValue(client, ctx, namespace, s.AccessKeyFromSecret.Name, s.AccessKeyFromSecret.Key)
+	if err != nil {
+		return objectstore.Config{}, fmt.Errorf("reading the S3 access key: %w", err)
+	}
+	secretKey, err := utils.GetSecretValue(client, ctx, namespace, s.SecretKeyFromSecret.Name, s.SecretKeyFromSecret.Key)
+	if err != nil {
+		return objectstore.Config{}, fmt.Errorf("reading the S3 secret key: %w", err)
+	}
+	return objectstore.Config{
+		Endpoint:  s.Endpoint,
+		Bucket:    s.Bucket,
+		Region:    s.Region,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}, nil
+}
+
+// GetS3EnvVars returns the environment of a container talking to the object store
+func (s *S3Config) GetS3EnvVars() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "S3_ENDPOINT", Value: s.Endpoint},
+		{Name: "S3_BUCKET", Value: s.Bucket},
+		{Name: "S3_REGION", Value: s.Region},
+		{
+			Name: "AWS_ACCESS_KEY_ID",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &s.AccessKeyFromSecret,
+			},
+		},
+		{
+			Name: "AWS_SECRET_ACCESS_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &s.SecretKeyFromSecret,
+			},
+		},
+	}
+}
+
+// GetBackupJobTemplate returns the job that dumps the database of the given OdooDeployment
+// and archives its filestore into the backup PVC. The job runs on filestoreNode, the node the
+// filestore is attached to, when the filestore can only be mounted from a single node.
+// When the backup has an S3 target, the artifacts are written to a scratch volume instead
+// and uploaded by a second container running uploaderImage. The pod restarts the failed
+// containers in place, so an interrupted upload resumes without dumping the database again.
+func (b *OdooBackup) GetBackupJobTemplate(
+	odooDeployment *OdooDeployment,
+	dbConnectionDetails DatabaseConnectionDetails,
+	filestoreNode string,
+	uploaderImage string,
+) batchv1.Job {
+	env := odooDeployment.Spec.Database.GetDbEnvVars(dbConnectionDetails)
+	env = append(env,
+		corev1.EnvVar{Name: "ODOO_IMAGE", Value: odooDeployment.Spec.Image},
+		corev1.EnvVar{Name: "ODOO_MODULES", Value: strings.Join(odooDeployment.Status.InitModulesInstalled, ",")},
+		corev1.EnvVar{Name: "DB_DUMP_FILE", Value: BackupDatabaseDumpFileName},
+		corev1.EnvVar{Name: "FILESTORE_ARCHIVE_FILE", Value: BackupFilestoreArchiveFileName},
+		corev1.EnvVar{Name: "MANIFEST_FILE", Value: BackupManifestFileName},
+	)
+
+	spec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:            "backup",
+				Image:           b.Spec.Image,
+				ImagePullPolicy: b.Spec.ImagePullPolicy,
+				Command:         []string{"/bin/sh", "-c", backupScript},
+				Env:             env,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      "odoo-data",
+						MountPath: "/filestore",
+						SubPath:   "filestore",
+						ReadOnly:  true,
+					},
+					{
+						Name:      "backup",
+						MountPath: "/backup",
+					},
+				},
+				TerminationMessagePath:   "/dev/termination-log",
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			},
+		},
+		Volumes: []corev1.Volume{
+			{
+				Name: "odoo-data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: odooDeployment.Status.OdooDataPvcName,
+						ReadOnly:  true,
+					},
+				},
+			},
+			{
+				Name: "backup",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: b.GetBackupPvcName(),
+					},
+				},
+			},
+		},
+		ImagePullSecrets: odooDeployment.Spec.ImagePullSecrets,
+		RestartPolicy:    corev1.RestartPolicyNever,
+	}
+	odooDeployment.Spec.Database.MountSSLFiles(&spec, &spec.Containers[0])
+	odooDeployment.applyPodTemplate(&spec)
+	odooDeployment.applyFilestoreAffinity(&spec, filestoreNode)
+
+	if b.Spec.S3 != nil {
+		scratch := &corev1.EmptyDirVolumeSource{}
+		if !b.Spec.Storage.Size.IsZero() {
+			scratch.SizeLimit = &b.Spec.Storage.Size
+		}
+		spec.Volumes[1].VolumeSource = corev1.VolumeSource{EmptyDir: scratch}
+
+		env := b.Spec.S3.GetS3EnvVars()
+		env = append(env,
+			corev1.EnvVar{Name: "S3_KEY_PREFIX", Value: b.GetBackupObjectKeyPrefix()},
+			corev1.EnvVar{Name: "BACKUP_DIR", Value: "/backup"},
+		)
+		spec.InitContainers = spec.Containers
+		spec.Containers = []corev1.Container{
+			{
+				Name:            "upload",
+				Image:           uploaderImage,
+				ImagePullPolicy: b.Spec.ImagePullPolicy,
+				Command:         []string{"/odoo-operator", "upload-backup"},
+				Env:             env,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      "backup",
+						MountPath: "/backup",
+						ReadOnly:  true,
+					},
+				},
+				TerminationMessagePath:   "/dev/termination-log",
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			},
+		}
+		spec.RestartPolicy = corev1.RestartPolicyOnFailure
+	}
+
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.GetBackupJobName(),
+			Namespace: b.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: odooDeployment.getPodTemplateMetadata(nil),
+				Spec:       spec,
+			},
+			Parallelism:  func(i int32) *int32 { return &i }(1),
+			BackoffLimit: func(i int32) *int32 { return &i }(2),
+		},
+	}
+}
+
+// ParseBackupManifest parses the manifest.json written by a backup job
+func ParseBackupManifest(data string) (BackupManifest, error) {
+	manifest := BackupManifest{}
+	if err := json.Unmarshal([]byte(data), &manifest); err != nil {
+		return manifest, err
+	}
+	if manifest.DatabaseDump.Name == "" || manifest.FilestoreArchive.Name == "" {
+		return manifest, fmt.Errorf("backup manifest is missing its artifacts")
+	}
+	return manifest, nil
+}
+
+// GetTerminationMessage returns the manifest without its modules, as written to the termination
+// log of backup jobs. A termination message is truncated at 4096 bytes, the modules are recorded
+// in the OdooBackup status when the job is created instead.
+func (m BackupManifest) GetTerminationMessage() ([]byte, error) {
+	m.Modules = nil
+	return json.Marshal(m)
+}
+
+// GetBackupSchedule returns the time of the most recent scheduled backup that is due at now and
+// has not been taken since lastScheduled, and the time of the next scheduled backup after now.
+// The returned due time is zero if no backup is due.
+func (c *OdooBackupConfig) GetBackupSchedule(lastScheduled time.Time, now time.Time) (time.Time, time.Time, error) {
+	schedule, err := cron.ParseStandard(c.Schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	due := time.Time{}
+	for t := schedule.Next(lastScheduled.UTC()); !t.After(now.UTC()); t = schedule.Next(t) {
+		due = t
+	}
+	return due, schedule.Next(now.UTC()), nil
+}
+
+func (c *OdooBackupConfig) GetRetentionPolicy() utils.RetentionPolicy {
+	return utils.RetentionPolicy{
+		Daily:   int(c.KeepDailyBackups),
+		Weekly:  int(c.KeepWeeklyBackups),
+		Monthly: int(c.KeepMonthlyBackups),
+	}
+}
+
+func (o *OdooDeployment) GetScheduledBackupName(scheduledTime time.Time) string {
+	return fmt.Sprintf("%s-%s", o.Name, scheduledTime.UTC().Format("200601021504"))
+}
+
+// GetScheduledBackupTemplate returns the OdooBackup taken by the backup schedule at scheduledTime
+func (o *OdooDeployment) GetScheduledBackupTemplate(scheduledTime time.Time) OdooBackup {
+	return o.getBackupTemplate(o.GetScheduledBackupName(scheduledTime), ScheduledBackupLabel)
+}
+
+// GetPreUpgradeBackupTemplate returns the OdooBackup taken at t before a job changing the database schema
+func (o *OdooDeployment) GetPreUpgradeBackupTemplate(t time.Time) OdooBackup {
+	return o.getBackupTemplate(fmt.Sprintf("%s-pre-upgrade-%s", o.Name, t.UTC().Format("20060102150405")), PreUpgradeBackupLabel)
+}
+
+// getBackupTemplate returns an OdooBackup using the backup configuration of the OdooDeployment.
+// It has no owner reference so the backup outlives the OdooDeployment.
+func (o *OdooDeployment) getBackupTemplate(name string, kindLabel string) OdooBackup {
+	return OdooBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: o.Namespace,
+			Labels: map[string]string{
+				OdooDeploymentLabel: o.Name,
+				kindLabel:           "true",
+			},
+		},
+		Spec: OdooBackupSpec{
+			OdooDeploymentRef: corev1.LocalObjectReference{Name: o.Name},
+			Image:             o.Spec.Backup.Image,
+			ImagePullPolicy:   corev1.PullIfNotPresent,
+			Storage:           o.Spec.Backup.Storage,
+			S3:                o.Spec.Backup.S3,
+		},
+	}
+}
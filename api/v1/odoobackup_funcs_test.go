@@ -0,0 +1,351 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func minimalOdooBackup() *OdooBackup {
+	return &OdooBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backup",
+			Namespace: "default",
+		},
+		Spec: OdooBackupSpec{
+			OdooDeploymentRef: corev1.LocalObjectReference{Name: "test-odoo"},
+			Image:             "postgres:17",
+		},
+	}
+}
+
+func TestGetBackupJobTemplate(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base", "web"}, []string{"base", "web"})
+	o.Spec.Database.PasswordFromSecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "db-secret"},
+		Key:                  "password",
+	}
+	b := minimalOdooBackup()
+
+	job := b.GetBackupJobTemplate(o, DatabaseConnectionDetails{
+		Host:     "db-host",
+		Port:     5432,
+		User:     "odoo",
+		Password: "secret",
+		Name:     "odoo",
+	}, "", "uploader:latest")
+
+	if job.Name != "test-backup-backup" {
+		t.Errorf("job name = %q, want %q", job.Name, "test-backup-backup")
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	env := map[string]corev1.EnvVar{}
+	for _, e := range container.Env {
+		env[e.Name] = e
+	}
+	if env["PGHOST"].Value != "db-host" || env["PGPORT"].Value != "5432" || env["PGDATABASE"].Value != "odoo" {
+		t.Errorf("unexpected connection env: %v", container.Env)
+	}
+	if env["PGPASSWORD"].Value != "" || env["PGPASSWORD"].ValueFrom == nil || env["PGPASSWORD"].ValueFrom.SecretKeyRef.Name != "db-secret" {
+		t.Errorf("PGPASSWORD must be read from the password secret, got %+v", env["PGPASSWORD"])
+	}
+	if env["ODOO_MODULES"].Value != "base,web" {
+		t.Errorf("ODOO_MODULES = %q, want %q", env["ODOO_MODULES"].Value, "base,web")
+	}
+	if _, ok := env["PGSSLMODE"]; ok {
+		t.Errorf("PGSSLMODE must not be set without an SSL mode")
+	}
+
+	claims := map[string]bool{}
+	for _, v := range job.Spec.Template.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claims[v.PersistentVolumeClaim.ClaimName] = v.PersistentVolumeClaim.ReadOnly
+		}
+	}
+	if readOnly, ok := claims["test-odoo"]; !ok || !readOnly {
+		t.Errorf("filestore PVC must be mounted read-only, got %v", claims)
+	}
+	if readOnly, ok := claims["test-backup"]; !ok || readOnly {
+		t.Errorf("backup PVC must be mounted read-write, got %v", claims)
+	}
+	if job.Spec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("restart policy = %q, want Never", job.Spec.Template.Spec.RestartPolicy)
+	}
+}
+
+func TestGetFilestoreAffinity(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessModes []corev1.PersistentVolumeAccessMode
+		nodeName    string
+		wantNode    string
+	}{
+		{
+			name:        "ReadWriteOnce requires the node the filestore is attached to",
+			accessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			nodeName:    "node-1",
+			wantNode:    "node-1",
+		},
+		{
+			name:        "ReadWriteOncePod requires the node the filestore is attached to",
+			accessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
+			nodeName:    "node-1",
+			wantNode:    "node-1",
+		},
+		{
+			name:        "ReadWriteOnce not attached does not constrain scheduling",
+			accessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		},
+		{
+			name:        "ReadWriteMany does not constrain scheduling",
+			accessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			nodeName:    "node-1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+			o.Spec.OdooFilestore.AccessModes = tc.accessModes
+			got := o.GetFilestoreAffinity(tc.nodeName)
+			if tc.wantNode == "" {
+				if got != nil {
+					t.Errorf("GetFilestoreAffinity() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.NodeAffinity == nil || got.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+				t.Fatalf("GetFilestoreAffinity() = %v, want a required node affinity", got)
+			}
+			terms := got.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			if len(terms) != 1 || len(terms[0].MatchFields) != 1 || terms[0].MatchFields[0].Key != "metadata.name" ||
+				strings.Join(terms[0].MatchFields[0].Values, ",") != tc.wantNode {
+				t.Errorf("GetFilestoreAffinity() terms = %+v, want node %q", terms, tc.wantNode)
+			}
+		})
+	}
+}
+
+func TestParseBackupManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid manifest",
+			data: `{"createdAt":"2025-01-01T00:00:00Z","database":"odoo","odooImage":"odoo:18","modules":["base","web"],` +
+				`"databaseDump":{"name":"db.dump","size":1024,"sha256":"abc"},` +
+				`"filestoreArchive":{"name":"filestore.tar.zst","size":2048,"sha256":"def"}}`,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"database":`,
+			wantErr: true,
+		},
+		{
+			name:    "missing artifacts",
+			data:    `{"database":"odoo"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest, err := ParseBackupManifest(tc.data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseBackupManifest() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if manifest.DatabaseDump.Size != 1024 || manifest.FilestoreArchive.SHA256 != "def" {
+				t.Errorf("unexpected manifest: %+v", manifest)
+			}
+			if strings.Join(manifest.Modules, ",") != "base,web" {
+				t.Errorf("modules = %v, want [base web]", manifest.Modules)
+			}
+		})
+	}
+}
+
+func TestBackupManifestGetTerminationMessage(t *testing.T) {
+	modules := make([]string, 2000)
+	for i := range modules {
+		modules[i] = fmt.Sprintf("custom_module_%d", i)
+	}
+	manifest := BackupManifest{
+		CreatedAt:        "2025-01-01T00:00:00Z",
+		Database:         "odoo",
+		OdooImage:        "registry.example.com/odoo/odoo:18.0-20250101",
+		Modules:          modules,
+		DatabaseDump:     BackupArtifact{Name: BackupDatabaseDumpFileName, Size: 1024, SHA256: strings.Repeat("a", 64)},
+		FilestoreArchive: BackupArtifact{Name: BackupFilestoreArchiveFileName, Size: 2048, SHA256: strings.Repeat("b", 64)},
+	}
+
+	message, err := manifest.GetTerminationMessage()
+	if err != nil {
+		t.Fatalf("GetTerminationMessage() error = %v", err)
+	}
+	if len(message) > 4096 {
+		t.Errorf("termination message has %d bytes, want at most 4096", len(message))
+	}
+	parsed, err := ParseBackupManifest(string(message))
+	if err != nil {
+		t.Fatalf("ParseBackupManifest() error = %v", err)
+	}
+	if parsed.DatabaseDump != manifest.DatabaseDump || parsed.FilestoreArchive != manifest.FilestoreArchive || parsed.OdooImage != manifest.OdooImage {
+		t.Errorf("termination message = %+v, want the artifacts of %+v", parsed, manifest)
+	}
+	if len(parsed.Modules) != 0 {
+		t.Errorf("termination message modules = %d, want none", len(parsed.Modules))
+	}
+	if len(manifest.Modules) != 2000 {
+		t.Errorf("GetTerminationMessage() modified the modules of the manifest")
+	}
+}
+
+func TestGetBackupSchedule(t *testing.T) {
+	lastScheduled := time.Date(2025, time.March, 15, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule string
+		now      time.Time
+		wantDue  time.Time
+		wantNext time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "not due yet",
+			schedule: "0 2 * * *",
+			now:      time.Date(2025, time.March, 15, 12, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2025, time.March, 16, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "due",
+			schedule: "0 2 * * *",
+			now:      time.Date(2025, time.March, 16, 2, 5, 0, 0, time.UTC),
+			wantDue:  time.Date(2025, time.March, 16, 2, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2025, time.March, 17, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "missed schedules only take the most recent one",
+			schedule: "0 2 * * *",
+			now:      time.Date(2025, time.March, 19, 3, 0, 0, 0, time.UTC),
+			wantDue:  time.Date(2025, time.March, 19, 2, 0, 0, 0, time.UTC),
+			wantNext: time.Date(2025, time.March, 20, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "invalid schedule",
+			schedule: "every day",
+			now:      time.Date(2025, time.March, 16, 2, 5, 0, 0, time.UTC),
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := OdooBackupConfig{Schedule: tc.schedule}
+			due, next, err := c.GetBackupSchedule(lastScheduled, tc.now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GetBackupSchedule() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if !due.Equal(tc.wantDue) {
+				t.Errorf("due = %v, want %v", due, tc.wantDue)
+			}
+			if !next.Equal(tc.wantNext) {
+				t.Errorf("next = %v, want %v", next, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestGetBackupJobTemplateWithS3(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+	b := minimalOdooBackup()
+	b.CreationTimestamp = metav1.NewTime(time.Date(2025, time.March, 16, 2, 0, 0, 0, time.UTC))
+	b.Spec.S3 = &S3Config{
+		Endpoint: "http://minio:9000",
+		Bucket:   "backups",
+		Prefix:   "/odoo/",
+		Region:   "us-east-1",
+		AccessKeyFromSecret: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  "accessKey",
+		},
+		SecretKeyFromSecret: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "s3"},
+			Key:                  "secretKey",
+		},
+	}
+
+	wantPrefix := "odoo/default/test-odoo/20250316T020000Z"
+	if got := b.GetBackupObjectKeyPrefix(); got != wantPrefix {
+		t.Errorf("GetBackupObjectKeyPrefix() = %q, want %q", got, wantPrefix)
+	}
+	if got := b.GetBackupLocation(); got != "s3://backups/"+wantPrefix {
+		t.Errorf("GetBackupLocation() = %q, want %q", got, "s3://backups/"+wantPrefix)
+	}
+
+	job := b.GetBackupJobTemplate(o, DatabaseConnectionDetails{Host: "db-host", Port: 5432, Name: "odoo"}, "", "uploader:latest")
+	spec := job.Spec.Template.Spec
+	if len(spec.InitContainers) != 1 || spec.InitContainers[0].Name != "backup" {
+		t.Fatalf("the backup must run as init container, got %v", spec.InitContainers)
+	}
+	if len(spec.Containers) != 1 || spec.Containers[0].Image != "uploader:latest" {
+		t.Fatalf("the upload container must run the uploader image, got %v", spec.Containers)
+	}
+	env := map[string]corev1.EnvVar{}
+	for _, e := range spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	if env["S3_KEY_PREFIX"].Value != wantPrefix || env["S3_BUCKET"].Value != "backups" {
+		t.Errorf("unexpected upload env: %v", spec.Containers[0].Env)
+	}
+	if env["AWS_SECRET_ACCESS_KEY"].ValueFrom == nil || env["AWS_SECRET_ACCESS_KEY"].ValueFrom.SecretKeyRef.Key != "secretKey" {
+		t.Errorf("AWS_SECRET_ACCESS_KEY must be read from the secret, got %+v", env["AWS_SECRET_ACCESS_KEY"])
+	}
+	for _, v := range spec.Volumes {
+		if v.Name == "backup" && v.EmptyDir == nil {
+			t.Errorf("the backup volume must be scratch space, got %+v", v.VolumeSource)
+		}
+	}
+	if spec.RestartPolicy != corev1.RestartPolicyOnFailure {
+		t.Errorf("restart policy = %q, want OnFailure so uploads resume in place", spec.RestartPolicy)
+	}
+}
+
+func TestGetPreUpgradeBackupTemplate(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+	o.Spec.Backup = OdooBackupConfig{
+		PreUpgrade: true,
+		Image:      "postgres:16",
+		S3:         &S3Config{Bucket: "backups"},
+	}
+
+	backup := o.GetPreUpgradeBackupTemplate(time.Date(2025, time.March, 16, 2, 30, 15, 0, time.UTC))
+
+	if backup.Name != "test-odoo-pre-upgrade-20250316023015" {
+		t.Errorf("name = %q, want %q", backup.Name, "test-odoo-pre-upgrade-20250316023015")
+	}
+	if backup.Labels[OdooDeploymentLabel] != "test-odoo" || backup.Labels[PreUpgradeBackupLabel] != "true" {
+		t.Errorf("labels = %v, want the OdooDeployment and pre-upgrade labels", backup.Labels)
+	}
+	if _, ok := backup.Labels[ScheduledBackupLabel]; ok {
+		t.Errorf("labels = %v, a pre-upgrade backup must not be pruned by the schedule", backup.Labels)
+	}
+	if len(backup.OwnerReferences) != 0 {
+		t.Errorf("owner references = %v, want none so the backup outlives the OdooDeployment", backup.OwnerReferences)
+	}
+	if backup.Spec.OdooDeploymentRef.Name != "test-odoo" || backup.Spec.Image != "postgres:16" || backup.Spec.S3 == nil || backup.Spec.S3.Bucket != "backups" {
+		t.Errorf("spec = %+v, want the backup configuration of the OdooDeployment", backup.Spec)
+	}
+}
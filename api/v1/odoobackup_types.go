@@ -0,0 +1,181 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonOdooDeploymentNotFound = "OdooDeploymentNotFound"
+	ReasonOdooDeploymentNotReady = "OdooDeploymentNotReady"
+
+	ReasonBackupPvcCreationFailed = "BackupPvcCreationFailed"
+	ReasonFailedGetFilestoreNode  = "FailedGetFilestoreNode"
+	ReasonS3ConfigInvalid         = "S3ConfigInvalid"
+
+	ReasonBackupJobCreationFailed = "BackupJobCreationFailed"
+	ReasonBackupJobCreated        = "BackupJobCreated"
+	ReasonBackupJobFailed         = "BackupJobFailed"
+	ReasonBackupSucceeded         = "BackupSucceeded"
+	ReasonBackupManifestInvalid   = "BackupManifestInvalid"
+
+	ReasonFailedDeleteBackupObjects = "FailedDeleteBackupObjects"
+)
+
+// BackupObjectsFinalizer is held by OdooBackups uploaded to S3 until their objects are deleted
+const BackupObjectsFinalizer = "odoo.abugharbia.com/backup-objects"
+
+// BackupPhase is the lifecycle phase of a single backup run
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type BackupPhase string
+
+const (
+	BackupPhasePending   BackupPhase = "Pending"
+	BackupPhaseRunning   BackupPhase = "Running"
+	BackupPhaseSucceeded BackupPhase = "Succeeded"
+	BackupPhaseFailed    BackupPhase = "Failed"
+)
+
+// BackupArtifact describes a single file produced by a backup run
+type BackupArtifact struct {
+	// The file name of the artifact inside the backup
+	Name string `json:"name,omitempty"`
+	// The size of the artifact in bytes
+	Size int64 `json:"size,omitempty"`
+	// The hex encoded sha256 checksum of the artifact
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// BackupManifest is the manifest.json written next to the artifacts of every backup
+type BackupManifest struct {
+	// The time the backup was taken, in RFC3339 format
+	CreatedAt string `json:"createdAt,omitempty"`
+	// The name of the database that was dumped
+	Database string `json:"database,omitempty"`
+	// The Odoo image the OdooDeployment was running when the backup was taken
+	OdooImage string `json:"odooImage,omitempty"`
+	// The modules installed in the database when the backup was taken
+	Modules []string `json:"modules,omitempty"`
+	// The pg_dump custom format dump of the database
+	DatabaseDump BackupArtifact `json:"databaseDump,omitempty"`
+	// The archive of the Odoo filestore
+	FilestoreArchive BackupArtifact `json:"filestoreArchive,omitempty"`
+}
+
+// OdooBackupSpec defines the desired state of OdooBackup
+type OdooBackupSpec struct {
+	// The OdooDeployment to back up, in the same namespace as the OdooBackup
+	OdooDeploymentRef corev1.LocalObjectReference `json:"odooDeploymentRef"`
+
+	// The image used to run the backup job, it must provide pg_dump, tar, zstd and sha256sum
+	// +kubebuilder:default="postgres:17"
+	Image string `json:"image,omitempty"`
+
+	// Image pull policy for the backup job
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="IfNotPresent"
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// The persistent volume claim the backup artifacts are written to. When S3 is set,
+	// its size limits the scratch space used before uploading
+	// +kubebuilder:validation:Optional
+	Storage PersistentVolumeClaimSpec `json:"storage,omitempty"`
+
+	// The S3 compatible object store the backup artifacts are uploaded to
+	// +kubebuilder:validation:Optional
+	S3 *S3Config `json:"s3,omitempty"`
+}
+
+// OdooBackupStatus defines the observed state of OdooBackup
+type OdooBackupStatus struct {
+	// The current phase of the backup
+	// +kubebuilder:validation:Optional
+	Phase BackupPhase `json:"phase,omitempty"`
+
+	// The name of the job running the backup
+	// +kubebuilder:validation:Optional
+	JobName string `json:"jobName,omitempty"`
+
+	// The name of the PVC holding the backup artifacts
+	// +kubebuilder:validation:Optional
+	PvcName string `json:"pvcName,omitempty"`
+
+	// The s3:// URL of the object store prefix holding the backup artifacts
+	// +kubebuilder:validation:Optional
+	Location string `json:"location,omitempty"`
+
+	// The time the backup job was created
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// The time the backup job finished
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// The name of the database that was dumped
+	// +kubebuilder:validation:Optional
+	Database string `json:"database,omitempty"`
+
+	// The Odoo image the OdooDeployment was running when the backup was taken
+	// +kubebuilder:validation:Optional
+	OdooImage string `json:"odooImage,omitempty"`
+
+	// The modules installed in the database when the backup was taken
+	// +kubebuilder:validation:Optional
+	InstalledModules []string `json:"installedModules,omitempty"`
+
+	// The database dump produced by the backup
+	// +kubebuilder:validation:Optional
+	DatabaseDump BackupArtifact `json:"databaseDump,omitempty"`
+
+	// The filestore archive produced by the backup
+	// +kubebuilder:validation:Optional
+	FilestoreArchive BackupArtifact `json:"filestoreArchive,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="OdooDeployment",type=string,JSONPath=`.spec.odooDeploymentRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OdooBackup is the Schema for the odoobackups API
+type OdooBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OdooBackupSpec   `json:"spec,omitempty"`
+	Status OdooBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OdooBackupList contains a list of OdooBackup
+type OdooBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OdooBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OdooBackup{}, &OdooBackupList{})
+}
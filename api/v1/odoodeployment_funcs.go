@@ -1,21 +1,34 @@
 package v1
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 
 	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+	jsonpatch "github.com/evanphx/json-patch/v5"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 func (odooDbConfig *OdooDatabaseConfig) GetHost(client client.Client, ctx context.Context, namespace string) (string, error) {
@@ -25,6 +38,9 @@ func (odooDbConfig *OdooDatabaseConfig) GetHost(client client.Client, ctx contex
 		return host, err
 	}
 	// If HostFromSecret is not provided, use the default host, which can also be given by the user
+	if odooDbConfig.Host == "" {
+		return DefaultDatabaseHost, nil
+	}
 	return odooDbConfig.Host, nil
 }
 
@@ -34,6 +50,9 @@ func (odooDbConfig *OdooDatabaseConfig) GetPort(client client.Client, ctx contex
 		return port, err
 	}
 	// If port is not provided, use the OdooDatabaseConfig Port
+	if odooDbConfig.Port == 0 {
+		return DefaultDatabasePort, nil
+	}
 	return odooDbConfig.Port, nil
 }
 
@@ -44,6 +63,9 @@ func (odooDbConfig *OdooDatabaseConfig) GetUser(client client.Client, ctx contex
 		return user, err
 	}
 	// If UserFromSecret is not provided, use the default user, which can also be given by the user
+	if odooDbConfig.User == "" {
+		return DefaultDatabaseUser, nil
+	}
 	return odooDbConfig.User, nil
 }
 func (odooDbConfig *OdooDatabaseConfig) GetPassword(client client.Client, ctx context.Context, namespace string) (string, error) {
@@ -63,16 +85,105 @@ func (odooDbConfig *OdooDatabaseConfig) GetDatabase(client client.Client, ctx co
 		return database, err
 	}
 	// If DatabaseFromSecret is not provided, use the default database, which can also be given by the user
+	if odooDbConfig.Name == "" {
+		return DefaultDatabaseName, nil
+	}
 	return odooDbConfig.Name, nil
 }
 
-func (odooDbConfig *OdooDatabaseConfig) GetSSL(client client.Client, ctx context.Context, namespace string) (bool, error) {
-	if odooDbConfig.SSLFromSecret.Name != "" && odooDbConfig.SSLFromSecret.Key != "" {
-		ssl, err := utils.GetBoolSecretValue(client, ctx, namespace, odooDbConfig.SSLFromSecret.Name, odooDbConfig.SSLFromSecret.Key)
-		return ssl, err
+func (odooDbConfig *OdooDatabaseConfig) GetSSLMode(client client.Client, ctx context.Context, namespace string) (SSLMode, error) {
+	if odooDbConfig.SSLModeFromSecret.Name != "" && odooDbConfig.SSLModeFromSecret.Key != "" {
+		sslMode, err := utils.GetSecretValue(client, ctx, namespace, odooDbConfig.SSLModeFromSecret.Name, odooDbConfig.SSLModeFromSecret.Key)
+		if err != nil {
+			return "", err
+		}
+		switch mode := SSLMode(sslMode); mode {
+		case SSLModeDisable, SSLModeAllow, SSLModePrefer, SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull:
+			return mode, nil
+		}
+		return "", fmt.Errorf("invalid ssl mode %q in secret %s", sslMode, odooDbConfig.SSLModeFromSecret.Name)
 	}
 	// If SSLModeFromSecret is not provided, use the default SSLMode
-	return odooDbConfig.SSL, nil
+	if odooDbConfig.SSLMode == "" {
+		return SSLModePrefer, nil
+	}
+	return odooDbConfig.SSLMode, nil
+}
+
+// dbSSLFile is an SSL certificate of the database connection mounted from a secret
+type dbSSLFile struct {
+	selector corev1.SecretKeySelector
+	// The name of the file in DatabaseSSLMountPath
+	fileName string
+	// The libpq environment variable pointing to the file
+	envVar string
+}
+
+// getSSLFiles returns the SSL certificates of the database connection that are set
+func (odooDbConfig *OdooDatabaseConfig) getSSLFiles() []dbSSLFile {
+	files := []dbSSLFile{
+		{selector: odooDbConfig.SSLRootCertFromSecret, fileName: "root.crt", envVar: "PGSSLROOTCERT"},
+		{selector: odooDbConfig.SSLCertFromSecret, fileName: "client.crt", envVar: "PGSSLCERT"},
+		{selector: odooDbConfig.SSLKeyFromSecret, fileName: "client.key", envVar: "PGSSLKEY"},
+	}
+	return slices.DeleteFunc(files, func(f dbSSLFile) bool {
+		return f.selector.Name == "" || f.selector.Key == ""
+	})
+}
+
+// GetSSLRootCertPath returns the path the CA certificate of the database connection is mounted at,
+// or an empty string when sslRootCertFromSecret is not set
+func (odooDbConfig *OdooDatabaseConfig) GetSSLRootCertPath() string {
+	if odooDbConfig.SSLRootCertFromSecret.Name == "" || odooDbConfig.SSLRootCertFromSecret.Key == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/root.crt", DatabaseSSLMountPath)
+}
+
+// GetSSLEnvVars returns the libpq environment variables pointing to the mounted SSL certificates
+func (odooDbConfig *OdooDatabaseConfig) GetSSLEnvVars() []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+	for _, file := range odooDbConfig.getSSLFiles() {
+		envVars = append(envVars, corev1.EnvVar{Name: file.envVar, Value: fmt.Sprintf("%s/%s", DatabaseSSLMountPath, file.fileName)})
+	}
+	return envVars
+}
+
+// MountSSLFiles mounts the SSL certificates of the database connection into the container at
+// DatabaseSSLMountPath. The files are owned by root and readable by the group of the pod, as libpq
+// refuses private keys readable by others.
+func (odooDbConfig *OdooDatabaseConfig) MountSSLFiles(spec *corev1.PodSpec, container *corev1.Container) {
+	files := odooDbConfig.getSSLFiles()
+	if len(files) == 0 {
+		return
+	}
+	sources := []corev1.VolumeProjection{}
+	for _, file := range files {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: file.selector.LocalObjectReference,
+				Items: []corev1.KeyToPath{
+					{Key: file.selector.Key, Path: file.fileName},
+				},
+			},
+		})
+	}
+	if !slices.ContainsFunc(spec.Volumes, func(v corev1.Volume) bool { return v.Name == "db-ssl" }) {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: "db-ssl",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources:     sources,
+					DefaultMode: func(i int32) *int32 { return &i }(0640),
+				},
+			},
+		})
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "db-ssl",
+		MountPath: DatabaseSSLMountPath,
+		ReadOnly:  true,
+	})
 }
 
 func (odooDbConfig *OdooDatabaseConfig) GetMaxConn(client client.Client, ctx context.Context, namespace string) (int32, error) {
@@ -81,14 +192,113 @@ func (odooDbConfig *OdooDatabaseConfig) GetMaxConn(client client.Client, ctx con
 		return maxConnections, err
 	}
 	// If MaxConnectionsFromSecret is not provided, use the default MaxConnections
+	if odooDbConfig.MaxConn == 0 {
+		return DefaultDatabaseMaxConn, nil
+	}
 	return odooDbConfig.MaxConn, nil
 }
 
+func (odooDbConfig *OdooDatabaseConfig) GetReplicaHost(client client.Client, ctx context.Context, namespace string) (string, error) {
+	if odooDbConfig.ReplicaHostFromSecret.Name != "" && odooDbConfig.ReplicaHostFromSecret.Key != "" {
+		host, err := utils.GetSecretValue(client, ctx, namespace, odooDbConfig.ReplicaHostFromSecret.Name, odooDbConfig.ReplicaHostFromSecret.Key)
+		return host, err
+	}
+	// If ReplicaHostFromSecret is not provided, use the ReplicaHost, no replica is used when it is empty
+	return odooDbConfig.ReplicaHost, nil
+}
+
+func (odooDbConfig *OdooDatabaseConfig) GetReplicaPort(client client.Client, ctx context.Context, namespace string) (int32, error) {
+	if odooDbConfig.ReplicaPortFromSecret.Name != "" && odooDbConfig.ReplicaPortFromSecret.Key != "" {
+		port, err := utils.GetInt32SecretValue(client, ctx, namespace, odooDbConfig.ReplicaPortFromSecret.Name, odooDbConfig.ReplicaPortFromSecret.Key)
+		return port, err
+	}
+	// If ReplicaPortFromSecret is not provided, use the ReplicaPort, Odoo uses the port of the database when it is 0
+	return odooDbConfig.ReplicaPort, nil
+}
+
+// GetCNPGAppSecretName returns the name of the secret CloudNativePG stores the credentials of the
+// application database of the Cluster in, the initdb secret when one is given
+func GetCNPGAppSecretName(cluster *unstructured.Unstructured) string {
+	if name, found, _ := unstructured.NestedString(cluster.Object, "spec", "bootstrap", "initdb", "secret", "name"); found && name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-app", cluster.GetName())
+}
+
+// getCNPGConnectionDetails returns the connection details of the application database of the
+// CloudNativePG Cluster of CNPGClusterRef, through its -rw service and its -ro service for the replica
+func (o *OdooDatabaseConfig) getCNPGConnectionDetails(
+	c client.Client,
+	ctx context.Context,
+	namespace string,
+) (DatabaseConnectionDetails, error) {
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(CNPGClusterGroupVersionKind)
+	err := c.Get(ctx, types.NamespacedName{Name: o.CNPGClusterRef.Name, Namespace: namespace}, cluster)
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetCNPGCluster})
+	}
+	appSecretName := GetCNPGAppSecretName(cluster)
+
+	dbUser, err := utils.GetSecretValue(c, ctx, namespace, appSecretName, "username")
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbUser})
+	}
+	dbPassword, err := utils.GetSecretValue(c, ctx, namespace, appSecretName, "password")
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbPassword})
+	}
+	dbName, err := utils.GetSecretValue(c, ctx, namespace, appSecretName, "dbname")
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbName})
+	}
+	dbSSLMode, err := o.GetSSLMode(c, ctx, namespace)
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbSslMode})
+	}
+	dbMaxConn, err := o.GetMaxConn(c, ctx, namespace)
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbMaxConns})
+	}
+
+	details := DatabaseConnectionDetails{
+		Host:     fmt.Sprintf("%s-rw", o.CNPGClusterRef.Name),
+		Port:     5432,
+		User:     dbUser,
+		Password: dbPassword,
+		Name:     dbName,
+		SSLMode:  dbSSLMode,
+		MaxConn:  dbMaxConn,
+		PasswordSecret: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: appSecretName},
+			Key:                  "password",
+		},
+	}
+	if o.CNPGClusterRef.UseReplica {
+		details.ReplicaHost = fmt.Sprintf("%s-ro", o.CNPGClusterRef.Name)
+		return details, nil
+	}
+
+	details.ReplicaHost, err = o.GetReplicaHost(c, ctx, namespace)
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbReplicaHost})
+	}
+	details.ReplicaPort, err = o.GetReplicaPort(c, ctx, namespace)
+	if err != nil {
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, utils.ErrFailedToGetDbReplicaPort})
+	}
+	return details, nil
+}
+
 func (o *OdooDatabaseConfig) GetDbConnectionDetails(
 	client client.Client,
 	ctx context.Context,
 	namespace string,
 ) (DatabaseConnectionDetails, error) {
+	if o.CNPGClusterRef != nil {
+		return o.getCNPGConnectionDetails(client, ctx, namespace)
+	}
+
 	dbHost, err := o.GetHost(client, ctx, namespace)
 	if err != nil {
 		specifiedError := utils.ErrFailedToGetDbHost
@@ -115,7 +325,7 @@ func (o *OdooDatabaseConfig) GetDbConnectionDetails(
 		specifiedError := utils.ErrFailedToGetDbName
 		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, specifiedError})
 	}
-	dbSsl, err := o.GetSSL(client, ctx, namespace)
+	dbSSLMode, err := o.GetSSLMode(client, ctx, namespace)
 	if err != nil {
 		specifiedError := utils.ErrFailedToGetDbSslMode
 		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, specifiedError})
@@ -127,17 +337,63 @@ func (o *OdooDatabaseConfig) GetDbConnectionDetails(
 		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, specifiedError})
 	}
 
+	dbReplicaHost, err := o.GetReplicaHost(client, ctx, namespace)
+	if err != nil {
+		specifiedError := utils.ErrFailedToGetDbReplicaHost
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, specifiedError})
+	}
+
+	dbReplicaPort, err := o.GetReplicaPort(client, ctx, namespace)
+	if err != nil {
+		specifiedError := utils.ErrFailedToGetDbReplicaPort
+		return DatabaseConnectionDetails{}, utilerrors.NewAggregate([]error{err, specifiedError})
+	}
+
 	return DatabaseConnectionDetails{
 		Host:     dbHost,
 		Port:     dbPort,
 		User:     dbUser,
 		Password: dbPassword,
 		Name:     dbName,
-		SSL:      dbSsl,
+		SSLMode:  dbSSLMode,
 		MaxConn:  dbMaxConn,
+
+		ReplicaHost: dbReplicaHost,
+		ReplicaPort: dbReplicaPort,
+
+		PasswordSecret: o.PasswordFromSecret,
 	}, nil
 }
 
+// GetDbEnvVars returns the libpq environment variables needed to connect to the database
+// from a job container. The password is always read from its secret instead of being inlined,
+// the SSL certificates have to be mounted with MountSSLFiles.
+func (o *OdooDatabaseConfig) GetDbEnvVars(dbConnectionDetails DatabaseConnectionDetails) []corev1.EnvVar {
+	passwordSecret := dbConnectionDetails.PasswordSecret
+	if passwordSecret.Name == "" {
+		passwordSecret = o.PasswordFromSecret
+	}
+	envVars := []corev1.EnvVar{
+		{Name: "PGHOST", Value: dbConnectionDetails.Host},
+		{Name: "PGPORT", Value: fmt.Sprintf("%d", dbConnectionDetails.Port)},
+		{Name: "PGUSER", Value: dbConnectionDetails.User},
+		{Name: "PGDATABASE", Value: dbConnectionDetails.Name},
+		{
+			Name: "PGPASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: passwordSecret.LocalObjectReference,
+					Key:                  passwordSecret.Key,
+				},
+			},
+		},
+	}
+	if dbConnectionDetails.SSLMode != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "PGSSLMODE", Value: string(dbConnectionDetails.SSLMode)})
+	}
+	return append(envVars, o.GetSSLEnvVars()...)
+}
+
 func (o *OdooDeployment) GetPodSpec() corev1.PodSpec {
 	podRestartPolicy := corev1.RestartPolicyAlways
 	podDNSPolicy := corev1.DNSClusterFirst
@@ -160,7 +416,7 @@ func (o *OdooDeployment) GetPodSpec() corev1.PodSpec {
 					},
 					{
 						Name:          "poll",
-						ContainerPort: 8072,
+						ContainerPort: o.Spec.Config.PollPort,
 						Protocol:      "TCP",
 					},
 				},
@@ -183,6 +439,7 @@ func (o *OdooDeployment) GetPodSpec() corev1.PodSpec {
 						ReadOnly:  true,
 					},
 				},
+				Resources:                o.Spec.Resources,
 				TerminationMessagePath:   "/dev/termination-log",
 				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
 			},
@@ -224,9 +481,179 @@ func (o *OdooDeployment) GetPodSpec() corev1.PodSpec {
 		TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
 		SchedulerName:                 schedulerName,
 	}
+	// psycopg2 reads the certificates from the libpq environment variables, odoo.conf only has db_sslmode
+	if sslEnvVars := o.Spec.Database.GetSSLEnvVars(); len(sslEnvVars) > 0 {
+		podSpec.Containers[0].Env = sslEnvVars
+		o.Spec.Database.MountSSLFiles(&podSpec, &podSpec.Containers[0])
+	}
+	// A native sidecar starts before Odoo and does not keep the pods of the jobs from completing
+	if o.GetPoolerMode() == PoolerModeSidecar {
+		restartPolicy := corev1.ContainerRestartPolicyAlways
+		pooler := o.getPoolerContainer()
+		pooler.RestartPolicy = &restartPolicy
+		o.Spec.Database.MountSSLFiles(&podSpec, &pooler)
+		podSpec.InitContainers = append(podSpec.InitContainers, pooler)
+		podSpec.Volumes = append(podSpec.Volumes, o.getPoolerConfigVolume())
+	}
+	o.applyPodTemplate(&podSpec)
 	return podSpec
 }
 
+// applyPodTemplate lays the scheduling fields of spec.podTemplate over the pod spec
+func (o *OdooDeployment) applyPodTemplate(podSpec *corev1.PodSpec) {
+	podTemplate := o.Spec.PodTemplate
+	if podTemplate == nil {
+		return
+	}
+	if podTemplate.NodeSelector != nil {
+		podSpec.NodeSelector = maps.Clone(podTemplate.NodeSelector)
+	}
+	if affinity := podTemplate.Affinity.DeepCopy(); affinity != nil {
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		if affinity.NodeAffinity != nil {
+			podSpec.Affinity.NodeAffinity = affinity.NodeAffinity
+		}
+		if affinity.PodAffinity != nil {
+			podSpec.Affinity.PodAffinity = affinity.PodAffinity
+		}
+		if affinity.PodAntiAffinity != nil {
+			podSpec.Affinity.PodAntiAffinity = affinity.PodAntiAffinity
+		}
+	}
+	for _, toleration := range podTemplate.Tolerations {
+		podSpec.Tolerations = append(podSpec.Tolerations, *toleration.DeepCopy())
+	}
+	for _, constraint := range podTemplate.TopologySpreadConstraints {
+		podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, *constraint.DeepCopy())
+	}
+	if podTemplate.PriorityClassName != "" {
+		podSpec.PriorityClassName = podTemplate.PriorityClassName
+	}
+	if podTemplate.RuntimeClassName != nil {
+		runtimeClassName := *podTemplate.RuntimeClassName
+		podSpec.RuntimeClassName = &runtimeClassName
+	}
+}
+
+// Apply patches the pod spec in place, a nil patch leaves it unchanged
+func (p *PodSpecPatch) Apply(podSpec *corev1.PodSpec) error {
+	if p == nil {
+		return nil
+	}
+	patch, err := yaml.YAMLToJSON([]byte(p.Patch))
+	if err != nil {
+		return fmt.Errorf("parsing the patch: %w", err)
+	}
+	original, err := json.Marshal(podSpec)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch p.Type {
+	case PodSpecPatchTypeJSONPatch:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return fmt.Errorf("decoding the JSON patch: %w", err)
+		}
+		if patched, err = operations.Apply(original); err != nil {
+			return fmt.Errorf("applying the JSON patch: %w", err)
+		}
+	default:
+		if patched, err = strategicpatch.StrategicMergePatch(original, patch, corev1.PodSpec{}); err != nil {
+			return fmt.Errorf("applying the strategic merge patch: %w", err)
+		}
+	}
+
+	// A misspelled field would otherwise be dropped without notice
+	result := corev1.PodSpec{}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return fmt.Errorf("decoding the patched pod spec: %w", err)
+	}
+	*podSpec = result
+	return nil
+}
+
+// PatchDeploymentPodSpec applies spec.podTemplatePatch.deployment to the pod spec of the Deployment
+func (o *OdooDeployment) PatchDeploymentPodSpec(podSpec *corev1.PodSpec) error {
+	if o.Spec.PodTemplatePatch == nil {
+		return nil
+	}
+	return o.Spec.PodTemplatePatch.Deployment.Apply(podSpec)
+}
+
+// PatchJobPodSpec applies spec.podTemplatePatch.jobs to the pod spec of a job
+func (o *OdooDeployment) PatchJobPodSpec(podSpec *corev1.PodSpec) error {
+	if o.Spec.PodTemplatePatch == nil {
+		return nil
+	}
+	return o.Spec.PodTemplatePatch.Jobs.Apply(podSpec)
+}
+
+// getPodTemplateMetadata returns the metadata of a pod template with the labels and annotations of
+// spec.podTemplate, the labels given take precedence. Every pod carries the OdooDeploymentLabel,
+// the operator only caches pods with that label.
+func (o *OdooDeployment) getPodTemplateMetadata(labels map[string]string) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{Labels: map[string]string{}}
+	if podTemplate := o.Spec.PodTemplate; podTemplate != nil {
+		maps.Copy(meta.Labels, podTemplate.Labels)
+		meta.Annotations = maps.Clone(podTemplate.Annotations)
+	}
+	meta.Labels[OdooDeploymentLabel] = o.Name
+	maps.Copy(meta.Labels, labels)
+	return meta
+}
+
+// GetFilestoreAffinity returns an affinity that requires scheduling on nodeName, the node the
+// filestore is attached to, when the filestore can only be mounted from a single node.
+// It returns nil for shared filestores and for filestores that are not attached to any node,
+// those are attached wherever the pod is scheduled.
+func (o *OdooDeployment) GetFilestoreAffinity(nodeName string) *corev1.Affinity {
+	readWriteOnce := false
+	for _, accessMode := range o.Spec.OdooFilestore.AccessModes {
+		if accessMode == corev1.ReadWriteOnce || accessMode == corev1.ReadWriteOncePod {
+			readWriteOnce = true
+		}
+	}
+	if !readWriteOnce || nodeName == "" {
+		return nil
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchFields: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "metadata.name",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{nodeName},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// applyFilestoreAffinity pins the pod to the node the filestore is attached to, the node
+// affinity of spec.podTemplate is replaced as the pod cannot run anywhere else
+func (o *OdooDeployment) applyFilestoreAffinity(podSpec *corev1.PodSpec, nodeName string) {
+	affinity := o.GetFilestoreAffinity(nodeName)
+	if affinity == nil {
+		return
+	}
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	podSpec.Affinity.NodeAffinity = affinity.NodeAffinity
+}
+
 // DeduplicateModules removes duplicate entries from Spec.Modules in place,
 // preserving the original order of first occurrences.
 func (o *OdooDeployment) DeduplicateModules() {
@@ -267,7 +694,8 @@ func (o *OdooDeployment) GetDbInitJobTemplate() (batchv1.Job, []string) {
 		},
 		Spec: batchv1.JobSpec{
 			Template: corev1.PodTemplateSpec{
-				Spec: spec,
+				ObjectMeta: o.getPodTemplateMetadata(nil),
+				Spec:       spec,
 			},
 			Parallelism:  func(i int32) *int32 { return &i }(1),
 			BackoffLimit: func(i int32) *int32 { return &i }(2),
@@ -276,6 +704,236 @@ func (o *OdooDeployment) GetDbInitJobTemplate() (batchv1.Job, []string) {
 	return job, modulesToInstall
 }
 
+// uninstallScript is run by `odoo shell`, it uninstalls the modules in UNINSTALL_MODULES
+// that are still installed in the database
+const uninstallScript = `import os
+names = os.environ["UNINSTALL_MODULES"].split(",")
+modules = env["ir.module.module"].search([("name", "in", names), ("state", "in", ["installed", "to upgrade"])])
+if modules:
+    modules.button_immediate_uninstall()
+env.cr.commit()
+`
+
+// GetDbUninstallJobTemplate returns the job uninstalling the modules removed from spec.modules
+// and the list of those modules. No modules are returned unless the removal policy is Uninstall.
+func (o *OdooDeployment) GetDbUninstallJobTemplate() (batchv1.Job, []string) {
+	if o.Spec.ModuleRemovalPolicy != ModuleRemovalPolicyUninstall {
+		return batchv1.Job{}, []string{}
+	}
+	modulesToUninstall := utils.Difference(o.Status.InitModulesInstalled, o.Spec.Modules)
+	if len(modulesToUninstall) == 0 {
+		return batchv1.Job{}, []string{}
+	}
+
+	spec := o.GetPodSpec()
+	// odoo shell executes the script it reads from stdin when stdin is not a terminal
+	spec.Containers[0].Command = append([]string{"/bin/sh", "-c", `printf '%s' "$UNINSTALL_SCRIPT" | exec "$@"`, "sh"}, o.Spec.OdooCommand...)
+	spec.Containers[0].Command = append(spec.Containers[0].Command, "shell", "-c", "/opt/odoo/odoo.conf", "--no-http")
+	spec.Containers[0].Env = append(spec.Containers[0].Env,
+		corev1.EnvVar{Name: "UNINSTALL_SCRIPT", Value: uninstallScript},
+		corev1.EnvVar{Name: "UNINSTALL_MODULES", Value: strings.Join(modulesToUninstall, ",")},
+	)
+	spec.Containers[0].Ports = []corev1.ContainerPort{}
+	spec.RestartPolicy = corev1.RestartPolicyNever
+
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-uninstall", o.Name),
+			Namespace: o.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: o.getPodTemplateMetadata(nil),
+				Spec:       spec,
+			},
+			Parallelism:  func(i int32) *int32 { return &i }(1),
+			BackoffLimit: func(i int32) *int32 { return &i }(2),
+		},
+	}
+	return job, modulesToUninstall
+}
+
+// GetUpgradePolicy returns the upgrade policy, Auto when it is not set
+func (o *OdooDeployment) GetUpgradePolicy() UpgradePolicy {
+	if o.Spec.Upgrade.Policy == "" {
+		return UpgradePolicyAuto
+	}
+	return o.Spec.Upgrade.Policy
+}
+
+// IsImageChanged returns true when spec.image differs from the image the database was last migrated
+// with, or when it is the same tag but resolves to another digest
+func (o *OdooDeployment) IsImageChanged() bool {
+	return o.Status.LastUpgrade.Image != "" && (o.Status.LastUpgrade.Image != o.Spec.Image || o.IsImageDigestChanged())
+}
+
+// IsImageDigestChanged returns true when spec.image is the image the database was last migrated with
+// but was pushed again since, i.e. it resolves to another digest than the one the database was migrated with
+func (o *OdooDeployment) IsImageDigestChanged() bool {
+	last, resolved := o.Status.LastUpgrade, o.Status.ResolvedImage
+	return last.Image == o.Spec.Image && last.ImageDigest != "" &&
+		resolved.Image == o.Spec.Image && resolved.Digest != "" && resolved.Digest != last.ImageDigest
+}
+
+// GetUpgradeApprovalValue returns the value of the UpgradeApprovalAnnotation approving the upgrade to
+// spec.image, <image>@<digest> when only the digest of the image changed
+func (o *OdooDeployment) GetUpgradeApprovalValue() string {
+	if o.Status.LastUpgrade.Image == o.Spec.Image && o.IsImageDigestChanged() {
+		return fmt.Sprintf("%s@%s", o.Spec.Image, o.Status.ResolvedImage.Digest)
+	}
+	return o.Spec.Image
+}
+
+// IsUpgradeAwaitingApproval returns true when the image changed, the upgrade policy is Manual
+// and the new image is not approved with the UpgradeApprovalAnnotation yet
+func (o *OdooDeployment) IsUpgradeAwaitingApproval() bool {
+	if len(o.Status.InitModulesInstalled) == 0 || !o.IsImageChanged() || o.GetUpgradePolicy() != UpgradePolicyManual {
+		return false
+	}
+	return o.Annotations[UpgradeApprovalAnnotation] != o.GetUpgradeApprovalValue()
+}
+
+// IsImageDigestResolved returns true when the digest of spec.image is resolved by the image digest
+// pod. Only images pulled on every start can change their digest under the same reference.
+func (o *OdooDeployment) IsImageDigestResolved() bool {
+	return o.Spec.ImagePullPolicy == corev1.PullAlways && !strings.Contains(o.Spec.Image, "@")
+}
+
+// GetImageDigestCheckDelay returns the time until the digest of spec.image has to be resolved again,
+// 0 when it is due
+func (o *OdooDeployment) GetImageDigestCheckDelay(now time.Time) time.Duration {
+	resolved := o.Status.ResolvedImage
+	if resolved.Image != o.Spec.Image || resolved.ResolvedAt == nil {
+		return 0
+	}
+	return max(resolved.ResolvedAt.Add(ImageDigestCheckInterval).Sub(now), 0)
+}
+
+// GetImageDigestPodName returns the name of the pod pulling spec.image to resolve its digest
+func (o *OdooDeployment) GetImageDigestPodName() string {
+	return fmt.Sprintf("%s-image-digest", o.Name)
+}
+
+// GetImageDigestPodTemplate returns the pod pulling spec.image to resolve its digest, it only prints
+// the Odoo version
+func (o *OdooDeployment) GetImageDigestPodTemplate() corev1.Pod {
+	activeDeadlineSeconds := int64(300)
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:            "odoo",
+				Image:           o.Spec.Image,
+				ImagePullPolicy: corev1.PullAlways,
+				Command:         append(slices.Clone(o.Spec.OdooCommand), "--version"),
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10m"),
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+				},
+				TerminationMessagePath:   "/dev/termination-log",
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			},
+		},
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsUser:    func(i int64) *int64 { return &i }(100),
+			RunAsGroup:   func(i int64) *int64 { return &i }(101),
+			RunAsNonRoot: func(i bool) *bool { return &i }(true),
+		},
+		ImagePullSecrets:      o.Spec.ImagePullSecrets,
+		RestartPolicy:         corev1.RestartPolicyNever,
+		ActiveDeadlineSeconds: &activeDeadlineSeconds,
+	}
+	o.applyPodTemplate(&podSpec)
+
+	meta := o.getPodTemplateMetadata(nil)
+	meta.Name = o.GetImageDigestPodName()
+	meta.Namespace = o.Namespace
+	return corev1.Pod{ObjectMeta: meta, Spec: podSpec}
+}
+
+// GetPendingUpgrade returns the module upgrade the database still needs and whether one is needed.
+// All modules are upgraded when the image changed, unless the policy is Skip or the new image is
+// awaiting approval. Otherwise the modules of spec.upgrade are upgraded when the token changed or
+// modules not upgraded yet were added. A database that was never initialised is not upgraded,
+// the init job installs the modules with the current image.
+func (o *OdooDeployment) GetPendingUpgrade() (OdooUpgrade, bool) {
+	if len(o.Status.InitModulesInstalled) == 0 {
+		return OdooUpgrade{}, false
+	}
+	upgrade := OdooUpgrade{
+		Image:   o.Spec.Image,
+		Modules: o.Spec.Upgrade.Modules,
+		Token:   o.Spec.Upgrade.Token,
+	}
+	if o.Status.ResolvedImage.Image == o.Spec.Image {
+		upgrade.ImageDigest = o.Status.ResolvedImage.Digest
+	}
+
+	if o.IsImageChanged() {
+		switch o.GetUpgradePolicy() {
+		case UpgradePolicyAuto:
+			upgrade.Modules = []string{"all"}
+			return upgrade, true
+		case UpgradePolicyManual:
+			if o.IsUpgradeAwaitingApproval() {
+				return OdooUpgrade{}, false
+			}
+			upgrade.Modules = []string{"all"}
+			return upgrade, true
+		}
+	}
+
+	last := o.Status.LastUpgrade
+	if len(upgrade.Modules) == 0 {
+		return OdooUpgrade{}, false
+	}
+	if upgrade.Token != last.Token {
+		return upgrade, true
+	}
+	if !slices.Contains(last.Modules, "all") && len(utils.Difference(upgrade.Modules, last.Modules)) > 0 {
+		return upgrade, true
+	}
+	return OdooUpgrade{}, false
+}
+
+// IsSameUpgrade returns true when both upgrades have the same image, token and set of modules, and
+// the same image digest when both are known
+func (u *OdooUpgrade) IsSameUpgrade(other OdooUpgrade) bool {
+	if u.ImageDigest != "" && other.ImageDigest != "" && u.ImageDigest != other.ImageDigest {
+		return false
+	}
+	return u.Image == other.Image && u.Token == other.Token &&
+		len(utils.Difference(u.Modules, other.Modules)) == 0 && len(utils.Difference(other.Modules, u.Modules)) == 0
+}
+
+// GetDbUpgradeJobTemplate returns the job upgrading the modules of the given upgrade with the image of the upgrade
+func (o *OdooDeployment) GetDbUpgradeJobTemplate(upgrade OdooUpgrade) batchv1.Job {
+	spec := o.GetPodSpec()
+	spec.Containers[0].Image = upgrade.Image
+	spec.Containers[0].Command = append(o.Spec.OdooCommand, "-c", "/opt/odoo/odoo.conf", "--stop-after-init", "--no-http", "-u", strings.Join(upgrade.Modules, ","))
+	spec.Containers[0].Ports = []corev1.ContainerPort{}
+	spec.RestartPolicy = corev1.RestartPolicyNever
+
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-upgrade", o.Name),
+			Namespace: o.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: o.getPodTemplateMetadata(nil),
+				Spec:       spec,
+			},
+			Parallelism:  func(i int32) *int32 { return &i }(1),
+			BackoffLimit: func(i int32) *int32 { return &i }(2),
+		},
+	}
+}
+
 func (o *OdooConfig) GetSerializedOdooConfig(
 	adminPassword string,
 	dbHost string,
@@ -284,7 +942,12 @@ func (o *OdooConfig) GetSerializedOdooConfig(
 	dbPassword string,
 	dbMaxConn int32,
 	dbName string,
+	dbSSLMode SSLMode,
+	dbSSLRootCert string,
+	dbReplicaHost string,
+	dbReplicaPort int32,
 	extraAddonsPaths []string,
+	odooMajorVersion int,
 ) string {
 
 	serializedConfig := fmt.Sprintf(
@@ -308,6 +971,19 @@ func (o *OdooConfig) GetSerializedOdooConfig(
 		o.LimitTimeReal,
 		o.MaxCronThreads,
 	)
+	serializedConfig += fmt.Sprintf("%s = %d\n", GetPollPortOption(odooMajorVersion), o.PollPort)
+	if dbSSLMode != "" {
+		serializedConfig += fmt.Sprintf("db_sslmode = %s\n", dbSSLMode)
+	}
+	if dbSSLRootCert != "" {
+		serializedConfig += fmt.Sprintf("db_sslrootcert = %s\n", dbSSLRootCert)
+	}
+	if dbReplicaHost != "" {
+		serializedConfig += fmt.Sprintf("db_replica_host = %s\n", dbReplicaHost)
+		if dbReplicaPort != 0 {
+			serializedConfig += fmt.Sprintf("db_replica_port = %d\n", dbReplicaPort)
+		}
+	}
 	if len(extraAddonsPaths) > 0 {
 		serializedConfig += fmt.Sprintf("addons_path = %s\n", strings.Join(extraAddonsPaths, ","))
 	}
@@ -443,8 +1119,8 @@ func (o *OdooDeployment) GetPollServiceTemplate() corev1.Service {
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "http",
-					Port:       8072,
-					TargetPort: intstr.FromInt(8072),
+					Port:       o.Spec.Config.PollPort,
+					TargetPort: intstr.FromInt32(o.Spec.Config.PollPort),
 					Protocol:   "TCP",
 				},
 			},
@@ -456,26 +1132,738 @@ func (o *OdooDeployment) GetPollServiceTemplate() corev1.Service {
 	return service
 }
 
-func (o *OdooDeployment) GetDeploymentTemplate() appsv1.Deployment {
-	maxUnavailable := intstr.FromString("25%")
-	maxSurge := intstr.FromString("25%")
-	revisionHistoryLimit := int32(10)
-	progressDeadlineSeconds := int32(600)
-	return appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      o.Name,
+// GetPoolerMode returns the mode of spec.database.pooler, none when it is unset
+func (o *OdooDeployment) GetPoolerMode() PoolerMode {
+	if o.Spec.Database.Pooler == nil || o.Spec.Database.Pooler.Mode == "" {
+		return PoolerModeNone
+	}
+	return o.Spec.Database.Pooler.Mode
+}
+
+func (o *OdooDeployment) UsesPooler() bool {
+	return o.GetPoolerMode() != PoolerModeNone
+}
+
+// GetPoolerName returns the name of the PgBouncer Deployment and Service of the deployment mode
+func (o *OdooDeployment) GetPoolerName() string {
+	return fmt.Sprintf("%s-pooler", o.Name)
+}
+
+func (o *OdooDeployment) GetPoolerLabels() map[string]string {
+	return map[string]string{
+		"app": o.GetPoolerName(),
+	}
+}
+
+// GetPoolerHost returns the host Odoo connects to PgBouncer on, localhost for a sidecar
+func (o *OdooDeployment) GetPoolerHost() string {
+	if o.GetPoolerMode() == PoolerModeSidecar {
+		return "127.0.0.1"
+	}
+	return o.GetPoolerName()
+}
+
+// quotePgBouncerValue quotes a value of userlist.txt, double quotes are doubled
+func quotePgBouncerValue(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+// GetSerializedPgBouncerUserlist returns the userlist.txt PgBouncer authenticates Odoo with, the
+// password is also used for logging in to the database server
+func GetSerializedPgBouncerUserlist(user, password string) string {
+	return fmt.Sprintf("%s %s\n", quotePgBouncerValue(user), quotePgBouncerValue(password))
+}
+
+// GetSerializedPgBouncerConfig returns the pgbouncer.ini forwarding every database to the database
+// server, Odoo connects to the postgres database for listing and creating databases
+func (o *OdooDeployment) GetSerializedPgBouncerConfig(dbConnectionDetails DatabaseConnectionDetails) string {
+	pooler := o.Spec.Database.Pooler
+	listenAddr := "0.0.0.0"
+	if o.GetPoolerMode() == PoolerModeSidecar {
+		listenAddr = "127.0.0.1"
+	}
+
+	serializedConfig := fmt.Sprintf(
+		"[databases]\n* = host=%s port=%d\n\n[pgbouncer]\nlisten_addr = %s\nlisten_port = %d\nunix_socket_dir =\nauth_type = scram-sha-256\nauth_file = %s/userlist.txt\npool_mode = %s\ndefault_pool_size = %d\nmax_client_conn = %d\nignore_startup_parameters = extra_float_digits,options\n",
+		dbConnectionDetails.Host,
+		dbConnectionDetails.Port,
+		listenAddr,
+		PoolerPort,
+		PoolerConfigMountPath,
+		pooler.PoolMode,
+		pooler.DefaultPoolSize,
+		pooler.MaxClientConn,
+	)
+	if dbConnectionDetails.SSLMode != "" {
+		serializedConfig += fmt.Sprintf("server_tls_sslmode = %s\n", dbConnectionDetails.SSLMode)
+	}
+	options := map[string]string{
+		"root.crt":   "server_tls_ca_file",
+		"client.crt": "server_tls_cert_file",
+		"client.key": "server_tls_key_file",
+	}
+	for _, file := range o.Spec.Database.getSSLFiles() {
+		serializedConfig += fmt.Sprintf("%s = %s/%s\n", options[file.fileName], DatabaseSSLMountPath, file.fileName)
+	}
+	return serializedConfig
+}
+
+// getPoolerContainer returns the PgBouncer container reading its configuration from the pooler-config
+// volume, the SSL certificates of the database connection are mounted by the caller
+func (o *OdooDeployment) getPoolerContainer() corev1.Container {
+	return corev1.Container{
+		Name:            "pgbouncer",
+		Image:           o.Spec.Database.Pooler.Image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"pgbouncer", fmt.Sprintf("%s/pgbouncer.ini", PoolerConfigMountPath)},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "pgbouncer",
+				ContainerPort: PoolerPort,
+				Protocol:      "TCP",
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "pooler-config",
+				MountPath: PoolerConfigMountPath,
+				ReadOnly:  true,
+			},
+		},
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	}
+}
+
+// getPoolerConfigVolume returns the volume of pgbouncer.ini and userlist.txt from the config secret
+func (o *OdooDeployment) getPoolerConfigVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: "pooler-config",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: o.Status.OdooConfigSecretName,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  "pgbouncer.ini",
+						Path: "pgbouncer.ini",
+					},
+					{
+						Key:  "userlist.txt",
+						Path: "userlist.txt",
+					},
+				},
+				DefaultMode: func(i int32) *int32 { return &i }(0440),
+			},
+		},
+	}
+}
+
+// GetPoolerDeploymentTemplate returns the PgBouncer Deployment of the deployment mode
+func (o *OdooDeployment) GetPoolerDeploymentTemplate() appsv1.Deployment {
+	maxUnavailable := intstr.FromString("25%")
+	maxSurge := intstr.FromString("25%")
+	revisionHistoryLimit := int32(10)
+	progressDeadlineSeconds := int32(600)
+	terminationGracePeriodSeconds := int64(30)
+
+	container := o.getPoolerContainer()
+	container.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt32(PoolerPort),
+			},
+		},
+		TimeoutSeconds:   1,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	podSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{o.getPoolerConfigVolume()},
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsUser:    func(i int64) *int64 { return &i }(100),
+			RunAsGroup:   func(i int64) *int64 { return &i }(101),
+			RunAsNonRoot: func(i bool) *bool { return &i }(true),
+			FSGroup:      func(i int64) *int64 { return &i }(101),
+		},
+		ImagePullSecrets:              o.Spec.ImagePullSecrets,
+		RestartPolicy:                 corev1.RestartPolicyAlways,
+		DNSPolicy:                     corev1.DNSClusterFirst,
+		TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+		SchedulerName:                 "default-scheduler",
+	}
+	o.Spec.Database.MountSSLFiles(&podSpec, &container)
+	podSpec.Containers = []corev1.Container{container}
+
+	return appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.GetPoolerName(),
 			Namespace: o.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &o.Spec.Replicas,
+			Replicas: &o.Spec.Database.Pooler.Replicas,
 			Selector: &metav1.LabelSelector{
-				MatchLabels: o.GetServiceSelectorLabels(),
+				MatchLabels: o.GetPoolerLabels(),
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: o.GetServiceSelectorLabels(),
+					Labels: o.GetPoolerLabels(),
+				},
+				Spec: podSpec,
+			},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: &maxUnavailable,
+					MaxSurge:       &maxSurge,
+				},
+			},
+			RevisionHistoryLimit:    &revisionHistoryLimit,
+			ProgressDeadlineSeconds: &progressDeadlineSeconds,
+		},
+	}
+}
+
+// GetPoolerServiceTemplate returns the Service of the PgBouncer Deployment of the deployment mode
+func (o *OdooDeployment) GetPoolerServiceTemplate() corev1.Service {
+	internalTrafficPolicy := corev1.ServiceInternalTrafficPolicyCluster
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.GetPoolerName(),
+			Namespace: o.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: o.GetPoolerLabels(),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "pgbouncer",
+					Port:       PoolerPort,
+					TargetPort: intstr.FromInt32(PoolerPort),
+					Protocol:   "TCP",
+				},
+			},
+			Type:                  corev1.ServiceTypeClusterIP,
+			SessionAffinity:       corev1.ServiceAffinityNone,
+			InternalTrafficPolicy: &internalTrafficPolicy,
+		},
+	}
+}
+
+func (o *OdooDeployment) GetIngressName() string {
+	return o.Name
+}
+
+// GetOdooMajorVersion returns spec.odooVersion, or the major Odoo version of the image tag when it
+// is unset, e.g. 17 for odoo:17.0-20240101. It is 0 when the tag does not start with a version
+func (o *OdooDeployment) GetOdooMajorVersion() int {
+	if o.Spec.OdooVersion != 0 {
+		return int(o.Spec.OdooVersion)
+	}
+	return parseOdooMajorVersion(o.Spec.Image)
+}
+
+func parseOdooMajorVersion(image string) int {
+	image = strings.SplitN(image, "@", 2)[0]
+	image = image[strings.LastIndex(image, "/")+1:]
+	_, tag, found := strings.Cut(image, ":")
+	if !found {
+		return 0
+	}
+	digits := len(tag) - len(strings.TrimLeft(tag, "0123456789"))
+	version, err := strconv.Atoi(tag[:digits])
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// usesLongpolling reports whether the major Odoo version serves the bus with longpolling, it was
+// replaced by websockets in Odoo 16. Unknown versions are assumed to be recent
+func usesLongpolling(odooMajorVersion int) bool {
+	return odooMajorVersion != 0 && odooMajorVersion < 16
+}
+
+// GetConnectionBudget returns the connections all pods may open with maxConn connections per process,
+// every worker, cron thread and the main process of every replica holds its own connection pool.
+// Behind a pooler the connections to the database server are capped by GetPoolerConnections.
+func (o *OdooDeployment) GetConnectionBudget(maxConn int32) int32 {
+	budget := maxConn * (o.Spec.Config.Workers + o.Spec.Config.MaxCronThreads + 1) * o.Spec.Replicas
+	if o.UsesPooler() {
+		return min(budget, o.GetPoolerConnections())
+	}
+	return budget
+}
+
+// GetPoolerConnections returns the connections the PgBouncer instances open to the database server,
+// default_pool_size for every instance, one sidecar per replica or the replicas of the pooler Deployment
+func (o *OdooDeployment) GetPoolerConnections() int32 {
+	switch o.GetPoolerMode() {
+	case PoolerModeSidecar:
+		return o.Spec.Database.Pooler.DefaultPoolSize * o.Spec.Replicas
+	case PoolerModeDeployment:
+		return o.Spec.Database.Pooler.DefaultPoolSize * o.Spec.Database.Pooler.Replicas
+	}
+	return 0
+}
+
+// autoTuneProcessMemory is the memory every Odoo process is sized for by autoTune, between the
+// 150MiB of a light and the 1GiB of a heavy request of the sizing guidance of Odoo
+const autoTuneProcessMemory = 512 * 1024 * 1024
+
+// GetAutoTunedConfig computes the settings of spec.config from the resources of the Odoo container, the
+// limits or else the requests. Following the sizing guidance of Odoo there are 2 workers per CPU plus
+// one, as many as the memory fits at autoTuneProcessMemory each, and 2 cron threads from 2 CPUs. The
+// memory is split evenly between the workers, the cron threads and the main process, the hard memory
+// limit of a process is its share and the soft limit 80% of it. It returns false without a memory.
+func GetAutoTunedConfig(resources corev1.ResourceRequirements) (AutoTunedConfig, bool) {
+	getResource := func(name corev1.ResourceName) (resource.Quantity, bool) {
+		if quantity, ok := resources.Limits[name]; ok && !quantity.IsZero() {
+			return quantity, true
+		}
+		quantity, ok := resources.Requests[name]
+		return quantity, ok && !quantity.IsZero()
+	}
+	memory, ok := getResource(corev1.ResourceMemory)
+	if !ok {
+		return AutoTunedConfig{}, false
+	}
+	cpu, hasCPU := getResource(corev1.ResourceCPU)
+
+	maxCronThreads := int32(1)
+	if hasCPU && cpu.MilliValue() >= 2000 {
+		maxCronThreads = 2
+	}
+	workers := int32(memory.Value()/autoTuneProcessMemory) - maxCronThreads - 1
+	if hasCPU {
+		workers = min(workers, int32(cpu.MilliValue()*2/1000)+1)
+	}
+	workers = max(workers, 1)
+
+	limitMemoryHard := memory.Value() / int64(workers+maxCronThreads+1)
+	return AutoTunedConfig{
+		Workers:         workers,
+		MaxCronThreads:  maxCronThreads,
+		LimitMemorySoft: limitMemoryHard * 4 / 5,
+		LimitMemoryHard: limitMemoryHard,
+	}, true
+}
+
+// GetMinPostgresVersion returns the oldest PostgreSQL release supported by the major Odoo version as
+// a server_version_num, e.g. 130000 for PostgreSQL 13. Unknown versions are assumed to be recent
+func GetMinPostgresVersion(odooMajorVersion int) int {
+	switch {
+	case odooMajorVersion == 0 || odooMajorVersion >= 18:
+		return 130000
+	case odooMajorVersion >= 16:
+		return 120000
+	case odooMajorVersion >= 14:
+		return 100000
+	default:
+		return 90500
+	}
+}
+
+// GetPollPortOption returns the odoo.conf option of the port of the poll server,
+// longpolling_port before Odoo 16 and gevent_port since
+func GetPollPortOption(odooMajorVersion int) string {
+	if usesLongpolling(odooMajorVersion) {
+		return "longpolling_port"
+	}
+	return "gevent_port"
+}
+
+// GetPollPath returns the path served by the poll service, /longpolling before Odoo 16 and /websocket since
+func (o *OdooDeployment) GetPollPath() string {
+	if usesLongpolling(o.GetOdooMajorVersion()) {
+		return "/longpolling"
+	}
+	return "/websocket"
+}
+
+// GetPollBackendService returns the service the poll path is routed to. Without workers Odoo runs
+// threaded and serves the poll path on the http port, nothing listens on the poll port.
+func (o *OdooDeployment) GetPollBackendService() corev1.Service {
+	if o.Spec.Config.Workers == 0 {
+		return o.GetHttpServiceTemplate()
+	}
+	return o.GetPollServiceTemplate()
+}
+
+func (o *OdooDeployment) GetIngressTemplate() networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	paths := []networkingv1.HTTPIngressPath{
+		{
+			Path:     o.GetPollPath(),
+			PathType: &pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: o.GetPollBackendService().Name,
+					Port: networkingv1.ServiceBackendPort{Name: "http"},
+				},
+			},
+		},
+		{
+			Path:     "/",
+			PathType: &pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: o.GetHttpServiceName(),
+					Port: networkingv1.ServiceBackendPort{Name: "http"},
+				},
+			},
+		},
+	}
+
+	ingress := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        o.GetIngressName(),
+			Namespace:   o.Namespace,
+			Annotations: o.Spec.Ingress.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: o.Spec.Ingress.IngressClassName,
+		},
+	}
+	for _, host := range o.Spec.Ingress.Hosts {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: paths,
 				},
-				Spec: o.GetPodSpec(),
+			},
+		})
+	}
+	tlsSecretName := o.Spec.Ingress.TLSSecretName
+	if tlsSecretName == "" && o.Spec.Exposure != nil && o.Spec.Exposure.Certificate != nil {
+		tlsSecretName = o.GetCertificateSecretName()
+	}
+	if tlsSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      o.Spec.Ingress.Hosts,
+				SecretName: tlsSecretName,
+			},
+		}
+	}
+	return ingress
+}
+
+func (o *OdooDeployment) GetHTTPRouteName() string {
+	return o.Name
+}
+
+// getHTTPRouteRule returns an HTTPRoute rule sending the requests with the path prefix to the
+// first port of the service, with the fields defaulted by the Gateway API set to their defaults
+func getHTTPRouteRule(pathPrefix string, service corev1.Service) map[string]interface{} {
+	return map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "PathPrefix",
+					"value": pathPrefix,
+				},
+			},
+		},
+		"backendRefs": []interface{}{
+			map[string]interface{}{
+				"group":  "",
+				"kind":   "Service",
+				"name":   service.Name,
+				"port":   int64(service.Spec.Ports[0].Port),
+				"weight": int64(1),
+			},
+		},
+	}
+}
+
+func (o *OdooDeployment) GetHTTPRouteTemplate() *unstructured.Unstructured {
+	parentRefs := []interface{}{}
+	for _, ref := range o.Spec.Exposure.Gateway.ParentRefs {
+		parentRef := map[string]interface{}{
+			"group": HTTPRouteGroupVersionKind.Group,
+			"kind":  "Gateway",
+			"name":  ref.Name,
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		if ref.Port != nil {
+			parentRef["port"] = int64(*ref.Port)
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules": []interface{}{
+			getHTTPRouteRule(o.GetPollPath(), o.GetPollBackendService()),
+			getHTTPRouteRule("/", o.GetHttpServiceTemplate()),
+		},
+	}
+	if len(o.Spec.Exposure.Gateway.Hostnames) > 0 {
+		hostnames := []interface{}{}
+		for _, hostname := range o.Spec.Exposure.Gateway.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		spec["hostnames"] = hostnames
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HTTPRouteGroupVersionKind)
+	route.SetName(o.GetHTTPRouteName())
+	route.SetNamespace(o.Namespace)
+	route.Object["spec"] = spec
+	return route
+}
+
+// GetHTTPRouteStatus returns the Accepted and ResolvedRefs conditions the Gateway controllers
+// reported on the HTTPRoute
+func GetHTTPRouteStatus(route *unstructured.Unstructured) (*HTTPRouteStatus, error) {
+	routeStatus := struct {
+		Parents []struct {
+			ParentRef struct {
+				Name        string `json:"name"`
+				Namespace   string `json:"namespace"`
+				SectionName string `json:"sectionName"`
+			} `json:"parentRef"`
+			Conditions []metav1.Condition `json:"conditions"`
+		} `json:"parents"`
+	}{}
+	if status, ok := route.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(status, &routeStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	status := &HTTPRouteStatus{Name: route.GetName()}
+	for _, parent := range routeStatus.Parents {
+		parentStatus := HTTPRouteParentStatus{
+			Name:        parent.ParentRef.Name,
+			Namespace:   parent.ParentRef.Namespace,
+			SectionName: parent.ParentRef.SectionName,
+		}
+		for _, condition := range parent.Conditions {
+			if condition.Type == "Accepted" || condition.Type == "ResolvedRefs" {
+				parentStatus.Conditions = append(parentStatus.Conditions, condition)
+			}
+		}
+		status.Parents = append(status.Parents, parentStatus)
+	}
+	return status, nil
+}
+
+func (o *OdooDeployment) GetCertificateName() string {
+	return o.Name
+}
+
+func (o *OdooDeployment) GetCertificateSecretName() string {
+	if o.Spec.Exposure != nil && o.Spec.Exposure.Certificate != nil && o.Spec.Exposure.Certificate.SecretName != "" {
+		return o.Spec.Exposure.Certificate.SecretName
+	}
+	return fmt.Sprintf("%s-tls", o.Name)
+}
+
+// GetHostnames returns the hosts of the Ingress and the hostnames of the HTTPRoute, sorted and without duplicates
+func (o *OdooDeployment) GetHostnames() []string {
+	hostnames := []string{}
+	if o.Spec.Ingress != nil {
+		hostnames = append(hostnames, o.Spec.Ingress.Hosts...)
+	}
+	if o.Spec.Exposure != nil && o.Spec.Exposure.Gateway != nil {
+		hostnames = append(hostnames, o.Spec.Exposure.Gateway.Hostnames...)
+	}
+	slices.Sort(hostnames)
+	return slices.Compact(hostnames)
+}
+
+func (o *OdooDeployment) GetCertificateTemplate() *unstructured.Unstructured {
+	dnsNames := []interface{}{}
+	for _, hostname := range o.GetHostnames() {
+		dnsNames = append(dnsNames, hostname)
+	}
+	issuerRef := o.Spec.Exposure.Certificate.IssuerRef
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGroupVersionKind)
+	certificate.SetName(o.GetCertificateName())
+	certificate.SetNamespace(o.Namespace)
+	certificate.Object["spec"] = map[string]interface{}{
+		"secretName": o.GetCertificateSecretName(),
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"name":  issuerRef.Name,
+			"kind":  issuerRef.Kind,
+			"group": issuerRef.Group,
+		},
+	}
+	return certificate
+}
+
+// GetCertificateReadyCondition returns the Ready condition cert-manager reported on the Certificate,
+// or a pending condition while there is none
+func GetCertificateReadyCondition(certificate *unstructured.Unstructured) (metav1.Condition, error) {
+	certificateStatus := struct {
+		Conditions []metav1.Condition `json:"conditions"`
+	}{}
+	if status, ok := certificate.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(status, &certificateStatus); err != nil {
+			return metav1.Condition{}, err
+		}
+	}
+	for _, condition := range certificateStatus.Conditions {
+		if condition.Type == "Ready" && condition.Reason != "" {
+			return condition, nil
+		}
+	}
+	return metav1.Condition{
+		Status:  metav1.ConditionUnknown,
+		Reason:  ReasonCertificatePending,
+		Message: fmt.Sprintf("Certificate %s has not been issued yet", certificate.GetName()),
+	}, nil
+}
+
+// GetDeploymentReplicas returns the desired replicas of the Deployment, it is scaled to zero
+// while a restore or a migration replaces the database and the filestore
+func (o *OdooDeployment) GetDeploymentReplicas() *int32 {
+	if o.Status.CurrentRestore != "" || o.Status.CurrentMigration != "" {
+		return func(i int32) *int32 { return &i }(0)
+	}
+	return &o.Spec.Replicas
+}
+
+// getHealthProbeHandler returns the check of the default probes, /web/health exists since Odoo 16
+func (o *OdooDeployment) getHealthProbeHandler() corev1.ProbeHandler {
+	if odooMajorVersion := o.GetOdooMajorVersion(); odooMajorVersion != 0 && odooMajorVersion < 16 {
+		return corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromString("http"),
+			},
+		}
+	}
+	return corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   "/web/health",
+			Port:   intstr.FromString("http"),
+			Scheme: corev1.URISchemeHTTP,
+		},
+	}
+}
+
+// withProbeDefaults returns a copy of the probe with the fields the API server defaults set, or nil
+func withProbeDefaults(probe *corev1.Probe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	probe = probe.DeepCopy()
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme == "" {
+		probe.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+	return probe
+}
+
+// GetProbes returns the liveness, readiness and startup probes of the Odoo container, the ones of
+// spec.probes or else the health check of the http port
+func (o *OdooDeployment) GetProbes() (liveness, readiness, startup *corev1.Probe) {
+	liveness = &corev1.Probe{
+		ProbeHandler:     o.getHealthProbeHandler(),
+		TimeoutSeconds:   10,
+		PeriodSeconds:    30,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	readiness = &corev1.Probe{
+		ProbeHandler:     o.getHealthProbeHandler(),
+		TimeoutSeconds:   5,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	// Loading the registry of a database with many modules takes minutes
+	startup = &corev1.Probe{
+		ProbeHandler:     o.getHealthProbeHandler(),
+		TimeoutSeconds:   5,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 60,
+	}
+
+	if probes := o.Spec.Probes; probes != nil {
+		if probes.Liveness != nil {
+			liveness = withProbeDefaults(probes.Liveness)
+		}
+		if probes.Readiness != nil {
+			readiness = withProbeDefaults(probes.Readiness)
+		}
+		if probes.Startup != nil {
+			startup = withProbeDefaults(probes.Startup)
+		}
+	}
+	return liveness, readiness, startup
+}
+
+// getDeploymentPodSpec returns the pod spec of the Deployment, the pod spec of the jobs with the probes
+// and a preStop hook. Terminating pods keep serving until they are removed from the endpoints and get
+// limitTimeReal to finish the requests in flight
+func (o *OdooDeployment) getDeploymentPodSpec() corev1.PodSpec {
+	podSpec := o.GetPodSpec()
+	container := &podSpec.Containers[0]
+	container.LivenessProbe, container.ReadinessProbe, container.StartupProbe = o.GetProbes()
+	container.Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sleep", strconv.Itoa(PreStopDelaySeconds)},
+			},
+		},
+	}
+	if o.Spec.Config.LimitTimeReal > 0 {
+		terminationGracePeriodSeconds := int64(PreStopDelaySeconds + o.Spec.Config.LimitTimeReal)
+		podSpec.TerminationGracePeriodSeconds = &terminationGracePeriodSeconds
+	}
+	return podSpec
+}
+
+func (o *OdooDeployment) GetDeploymentTemplate() appsv1.Deployment {
+	maxUnavailable := intstr.FromString("25%")
+	maxSurge := intstr.FromString("25%")
+	revisionHistoryLimit := int32(10)
+	progressDeadlineSeconds := int32(600)
+	podTemplateMetadata := o.getPodTemplateMetadata(o.GetServiceSelectorLabels())
+	// The pods pull the tag again once the database is migrated to its new digest
+	if last := o.Status.LastUpgrade; o.IsImageDigestResolved() && last.Image == o.Spec.Image && last.ImageDigest != "" {
+		if podTemplateMetadata.Annotations == nil {
+			podTemplateMetadata.Annotations = map[string]string{}
+		}
+		podTemplateMetadata.Annotations[ImageDigestAnnotation] = last.ImageDigest
+	}
+	return appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.Name,
+			Namespace: o.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: o.GetDeploymentReplicas(),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: o.GetServiceSelectorLabels(),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: podTemplateMetadata,
+				Spec:       o.getDeploymentPodSpec(),
 			},
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RollingUpdateDeploymentStrategyType,
@@ -500,18 +1888,57 @@ func (o *OdooDeployment) CreateOdooConfigSecretObj(
 		return corev1.Secret{}, err
 	}
 
+	dbHost, dbPort := dbConnectionDetails.Host, dbConnectionDetails.Port
+	dbSSLMode, dbSSLRootCert := dbConnectionDetails.SSLMode, o.Spec.Database.GetSSLRootCertPath()
+	dbReplicaHost, dbReplicaPort := dbConnectionDetails.ReplicaHost, dbConnectionDetails.ReplicaPort
+	if o.UsesPooler() {
+		// PgBouncer connects to the database server with the SSL settings, Odoo to PgBouncer in plain text.
+		// db_sslmode applies to the replica as well, so it is not used behind the pooler
+		dbHost, dbPort = o.GetPoolerHost(), PoolerPort
+		dbSSLMode, dbSSLRootCert = SSLModeDisable, ""
+		dbReplicaHost, dbReplicaPort = "", 0
+	}
+
 	serializedOdooConfig := o.Spec.Config.GetSerializedOdooConfig(
 		string(adminPassword),
-		dbConnectionDetails.Host,
-		dbConnectionDetails.Port,
+		dbHost,
+		dbPort,
 		dbConnectionDetails.User,
 		dbConnectionDetails.Password,
 		dbConnectionDetails.MaxConn,
 		dbConnectionDetails.Name,
+		dbSSLMode,
+		dbSSLRootCert,
+		dbReplicaHost,
+		dbReplicaPort,
 		o.Spec.Config.ExtraAddonsPaths,
+		o.GetOdooMajorVersion(),
 	)
 
-	return o.GetOdooConfigSecretTemplate(serializedOdooConfig), nil
+	secret := o.GetOdooConfigSecretTemplate(serializedOdooConfig)
+	if o.UsesPooler() {
+		secret.Data["pgbouncer.ini"] = []byte(o.GetSerializedPgBouncerConfig(dbConnectionDetails))
+		secret.Data["userlist.txt"] = []byte(GetSerializedPgBouncerUserlist(dbConnectionDetails.User, dbConnectionDetails.Password))
+	}
+	return secret, nil
+}
+
+// GetCNPGDefaultAppSecretName returns the name of the app secret of the referenced CloudNativePG
+// Cluster when it is generated by CloudNativePG, or an empty string when no Cluster is referenced
+func (o *OdooDeployment) GetCNPGDefaultAppSecretName() string {
+	if o.Spec.Database.CNPGClusterRef == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-app", o.Spec.Database.CNPGClusterRef.Name)
+}
+
+// GetSuperuserSecretName returns the name of the superuser secret of spec.database.provisioning, or
+// an empty string when the database is not provisioned by the operator
+func (o *OdooDeployment) GetSuperuserSecretName() string {
+	if o.Spec.Database.Provisioning == nil {
+		return ""
+	}
+	return o.Spec.Database.Provisioning.SuperuserSecretName
 }
 
 // UsesSecret checks whether a given secret is used by a Cluster.
@@ -530,7 +1957,21 @@ func (o *OdooDeployment) UsesSecret(secret string) bool {
 		return true
 	case o.Spec.Database.NameFromSecret.Name:
 		return true
-	case o.Spec.Database.SSLFromSecret.Name:
+	case o.Spec.Database.SSLModeFromSecret.Name:
+		return true
+	case o.Spec.Database.SSLRootCertFromSecret.Name:
+		return true
+	case o.Spec.Database.SSLCertFromSecret.Name:
+		return true
+	case o.Spec.Database.SSLKeyFromSecret.Name:
+		return true
+	case o.Spec.Database.ReplicaHostFromSecret.Name:
+		return true
+	case o.Spec.Database.ReplicaPortFromSecret.Name:
+		return true
+	case o.GetCNPGDefaultAppSecretName():
+		return true
+	case o.GetSuperuserSecretName():
 		return true
 	case o.Spec.Database.MaxConnFromSecret.Name:
 		return true
@@ -546,9 +1987,18 @@ func (o *OdooDeployment) UsesPVC(pvcName string) bool {
 }
 
 func (o *OdooDeployment) UsesDeployment(deploymentName string) bool {
-	return o.Name == deploymentName
+	return o.Name == deploymentName || (o.GetPoolerMode() == PoolerModeDeployment && o.GetPoolerName() == deploymentName)
 }
 
 func (o *OdooDeployment) UsesService(serviceName string) bool {
-	return o.GetHttpServiceName() == serviceName || o.GetPollServiceName() == serviceName
+	return o.GetHttpServiceName() == serviceName || o.GetPollServiceName() == serviceName ||
+		(o.GetPoolerMode() == PoolerModeDeployment && o.GetPoolerName() == serviceName)
+}
+
+func (o *OdooDeployment) UsesIngress(ingressName string) bool {
+	return o.GetIngressName() == ingressName
+}
+
+func (o *OdooDeployment) UsesCNPGCluster(clusterName string) bool {
+	return o.Spec.Database.CNPGClusterRef != nil && o.Spec.Database.CNPGClusterRef.Name == clusterName
 }
@@ -1,10 +1,19 @@
 package v1
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // minimalOdooDeployment returns an OdooDeployment with just enough fields
@@ -23,6 +32,7 @@ func minimalOdooDeployment(specModules, installedModules []string) *OdooDeployme
 			Config: OdooConfig{
 				DataDir:        "/var/lib/odoo",
 				MaxCronThreads: 1,
+				PollPort:       8072,
 			},
 			Modules: specModules,
 		},
@@ -121,8 +131,8 @@ func TestGetSerializedOdooConfig_ExtraAddonsPaths(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			got := baseConfig.GetSerializedOdooConfig(
-				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo",
-				tc.extraAddonsPaths,
+				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo", SSLModePrefer, "", "", 0,
+				tc.extraAddonsPaths, 18,
 			)
 			if tc.wantContains != "" && !strings.Contains(got, tc.wantContains) {
 				t.Errorf("config missing %q\ngot:\n%s", tc.wantContains, got)
@@ -159,8 +169,8 @@ func TestGetSerializedOdooConfig_MaxCronThreads(t *testing.T) {
 				MaxCronThreads: tc.maxCronThreads,
 			}
 			got := cfg.GetSerializedOdooConfig(
-				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo",
-				[]string{},
+				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo", SSLModePrefer, "", "", 0,
+				[]string{}, 18,
 			)
 			if !strings.Contains(got, tc.wantContains) {
 				t.Errorf("config missing %q\ngot:\n%s", tc.wantContains, got)
@@ -297,3 +307,1585 @@ func TestGetDbInitJobTemplate_OdooCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPendingUpgrade(t *testing.T) {
+	tests := []struct {
+		name        string
+		installed   []string
+		upgrade     OdooUpgradeConfig
+		annotations map[string]string
+		lastUpgrade OdooUpgrade
+		resolved    ResolvedImage
+		wantPending bool
+		wantModules []string
+		wantDigest  string
+	}{
+		{
+			name:        "nothing changed",
+			installed:   []string{"base"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18"},
+			wantPending: false,
+		},
+		{
+			name:        "database not initialised",
+			installed:   []string{},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale"}},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17"},
+			wantPending: false,
+		},
+		{
+			name:        "never upgraded",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale"}},
+			wantPending: true,
+			wantModules: []string{"sale"},
+		},
+		{
+			name:        "up to date",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale", "web"}, Token: "1"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", Modules: []string{"web", "sale"}, Token: "1"},
+			wantPending: false,
+		},
+		{
+			name:        "token changed",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale"}, Token: "2"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", Modules: []string{"sale"}, Token: "1"},
+			wantPending: true,
+			wantModules: []string{"sale"},
+		},
+		{
+			name:        "modules added",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale", "stock"}, Token: "1"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", Modules: []string{"sale"}, Token: "1"},
+			wantPending: true,
+			wantModules: []string{"sale", "stock"},
+		},
+		{
+			name:        "modules removed",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale"}, Token: "1"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", Modules: []string{"sale", "stock"}, Token: "1"},
+			wantPending: false,
+		},
+		{
+			name:        "modules covered by all",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Modules: []string{"sale"}, Token: "1"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", Modules: []string{"all"}, Token: "1"},
+			wantPending: false,
+		},
+		{
+			name:        "image changed with default policy",
+			installed:   []string{"base"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17"},
+			wantPending: true,
+			wantModules: []string{"all"},
+		},
+		{
+			name:        "image changed with auto policy",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicyAuto, Modules: []string{"sale"}},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17", Modules: []string{"sale"}},
+			wantPending: true,
+			wantModules: []string{"all"},
+		},
+		{
+			name:        "image changed with manual policy not approved",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicyManual},
+			annotations: map[string]string{UpgradeApprovalAnnotation: "odoo:17"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17"},
+			wantPending: false,
+		},
+		{
+			name:        "image changed with manual policy approved",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicyManual},
+			annotations: map[string]string{UpgradeApprovalAnnotation: "odoo:18"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17"},
+			wantPending: true,
+			wantModules: []string{"all"},
+		},
+		{
+			name:        "tag pushed again",
+			installed:   []string{"base"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", ImageDigest: "sha256:old"},
+			resolved:    ResolvedImage{Image: "odoo:18", Digest: "sha256:new"},
+			wantPending: true,
+			wantModules: []string{"all"},
+			wantDigest:  "sha256:new",
+		},
+		{
+			name:        "tag resolves to the same digest",
+			installed:   []string{"base"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", ImageDigest: "sha256:old"},
+			resolved:    ResolvedImage{Image: "odoo:18", Digest: "sha256:old"},
+			wantPending: false,
+		},
+		{
+			name:        "digest resolved for another image",
+			installed:   []string{"base"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", ImageDigest: "sha256:old"},
+			resolved:    ResolvedImage{Image: "odoo:17", Digest: "sha256:new"},
+			wantPending: false,
+		},
+		{
+			name:        "digest of the last upgrade unknown",
+			installed:   []string{"base"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18"},
+			resolved:    ResolvedImage{Image: "odoo:18", Digest: "sha256:new"},
+			wantPending: false,
+		},
+		{
+			name:        "tag pushed again with manual policy approved for the tag",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicyManual},
+			annotations: map[string]string{UpgradeApprovalAnnotation: "odoo:18"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", ImageDigest: "sha256:old"},
+			resolved:    ResolvedImage{Image: "odoo:18", Digest: "sha256:new"},
+			wantPending: false,
+		},
+		{
+			name:        "tag pushed again with manual policy approved for the digest",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicyManual},
+			annotations: map[string]string{UpgradeApprovalAnnotation: "odoo:18@sha256:new"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:18", ImageDigest: "sha256:old"},
+			resolved:    ResolvedImage{Image: "odoo:18", Digest: "sha256:new"},
+			wantPending: true,
+			wantModules: []string{"all"},
+			wantDigest:  "sha256:new",
+		},
+		{
+			name:        "image changed with skip policy",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicySkip, Modules: []string{"sale"}},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17", Modules: []string{"sale"}},
+			wantPending: false,
+		},
+		{
+			name:        "image changed with skip policy and token changed",
+			installed:   []string{"base"},
+			upgrade:     OdooUpgradeConfig{Policy: UpgradePolicySkip, Modules: []string{"sale"}, Token: "2"},
+			lastUpgrade: OdooUpgrade{Image: "odoo:17", Modules: []string{"sale"}, Token: "1"},
+			wantPending: true,
+			wantModules: []string{"sale"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, tc.installed)
+			o.Annotations = tc.annotations
+			o.Spec.Upgrade = tc.upgrade
+			o.Status.LastUpgrade = tc.lastUpgrade
+			o.Status.ResolvedImage = tc.resolved
+
+			upgrade, pending := o.GetPendingUpgrade()
+			if pending != tc.wantPending {
+				t.Fatalf("GetPendingUpgrade() pending = %t, want %t", pending, tc.wantPending)
+			}
+			if !pending {
+				return
+			}
+			if upgrade.Image != o.Spec.Image || upgrade.Token != tc.upgrade.Token {
+				t.Errorf("GetPendingUpgrade() = %+v, want image %q and token %q", upgrade, o.Spec.Image, tc.upgrade.Token)
+			}
+			if strings.Join(upgrade.Modules, ",") != strings.Join(tc.wantModules, ",") {
+				t.Errorf("GetPendingUpgrade() modules = %v, want %v", upgrade.Modules, tc.wantModules)
+			}
+			if tc.wantDigest != "" && upgrade.ImageDigest != tc.wantDigest {
+				t.Errorf("GetPendingUpgrade() digest = %q, want %q", upgrade.ImageDigest, tc.wantDigest)
+			}
+		})
+	}
+}
+
+func TestIsUpgradeAwaitingApproval(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      UpgradePolicy
+		annotations map[string]string
+		lastImage   string
+		want        bool
+	}{
+		{name: "auto policy", policy: UpgradePolicyAuto, lastImage: "odoo:17", want: false},
+		{name: "image unchanged", policy: UpgradePolicyManual, lastImage: "odoo:18", want: false},
+		{name: "image unknown", policy: UpgradePolicyManual, lastImage: "", want: false},
+		{name: "not approved", policy: UpgradePolicyManual, lastImage: "odoo:17", want: true},
+		{name: "approved for another image", policy: UpgradePolicyManual, annotations: map[string]string{UpgradeApprovalAnnotation: "odoo:19"}, lastImage: "odoo:17", want: true},
+		{name: "approved", policy: UpgradePolicyManual, annotations: map[string]string{UpgradeApprovalAnnotation: "odoo:18"}, lastImage: "odoo:17", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+			o.Annotations = tc.annotations
+			o.Spec.Upgrade.Policy = tc.policy
+			o.Status.LastUpgrade.Image = tc.lastImage
+
+			if got := o.IsUpgradeAwaitingApproval(); got != tc.want {
+				t.Errorf("IsUpgradeAwaitingApproval() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetImageDigestCheckDelay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	resolvedAt := func(ago time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-ago))
+		return &t
+	}
+	tests := []struct {
+		name     string
+		resolved ResolvedImage
+		want     time.Duration
+	}{
+		{name: "never resolved", want: 0},
+		{name: "resolved for another image", resolved: ResolvedImage{Image: "odoo:17", Digest: "sha256:a", ResolvedAt: resolvedAt(time.Minute)}, want: 0},
+		{name: "resolved recently", resolved: ResolvedImage{Image: "odoo:18", Digest: "sha256:a", ResolvedAt: resolvedAt(time.Minute)}, want: ImageDigestCheckInterval - time.Minute},
+		{name: "check due", resolved: ResolvedImage{Image: "odoo:18", Digest: "sha256:a", ResolvedAt: resolvedAt(ImageDigestCheckInterval + time.Minute)}, want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+			o.Status.ResolvedImage = tc.resolved
+
+			if got := o.GetImageDigestCheckDelay(now); got != tc.want {
+				t.Errorf("GetImageDigestCheckDelay() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentTemplate_ImageDigestAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		pullPolicy corev1.PullPolicy
+		image      string
+		want       string
+	}{
+		{name: "pull always", pullPolicy: corev1.PullAlways, image: "odoo:18", want: "sha256:old"},
+		{name: "pull if not present", pullPolicy: corev1.PullIfNotPresent, image: "odoo:18", want: ""},
+		{name: "image pinned by digest", pullPolicy: corev1.PullAlways, image: "odoo@sha256:old", want: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+			o.Spec.Image = tc.image
+			o.Spec.ImagePullPolicy = tc.pullPolicy
+			o.Status.LastUpgrade = OdooUpgrade{Image: tc.image, ImageDigest: "sha256:old"}
+
+			deployment := o.GetDeploymentTemplate()
+			if got := deployment.Spec.Template.Annotations[ImageDigestAnnotation]; got != tc.want {
+				t.Errorf("annotation %s = %q, want %q", ImageDigestAnnotation, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetDbUpgradeJobTemplate(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+	job := o.GetDbUpgradeJobTemplate(OdooUpgrade{Image: "odoo:18.0-20250101", Modules: []string{"sale", "stock"}})
+
+	if job.Name != "test-odoo-upgrade" {
+		t.Errorf("job name = %q, want %q", job.Name, "test-odoo-upgrade")
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	if container.Image != "odoo:18.0-20250101" {
+		t.Errorf("image = %q, want the image of the upgrade", container.Image)
+	}
+	cmd := strings.Join(container.Command, " ")
+	if !strings.HasSuffix(cmd, "--stop-after-init --no-http -u sale,stock") {
+		t.Errorf("command = %q, want it to upgrade sale,stock", cmd)
+	}
+	if len(container.Ports) != 0 {
+		t.Errorf("ports = %v, want none", container.Ports)
+	}
+}
+
+func TestGetDbUninstallJobTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      ModuleRemovalPolicy
+		specModules []string
+		installed   []string
+		want        []string
+	}{
+		{
+			name:        "ignore policy",
+			policy:      ModuleRemovalPolicyIgnore,
+			specModules: []string{"base"},
+			installed:   []string{"base", "sale"},
+			want:        []string{},
+		},
+		{
+			name:        "default policy",
+			specModules: []string{"base"},
+			installed:   []string{"base", "sale"},
+			want:        []string{},
+		},
+		{
+			name:        "nothing removed",
+			policy:      ModuleRemovalPolicyUninstall,
+			specModules: []string{"base", "sale", "stock"},
+			installed:   []string{"base", "sale"},
+			want:        []string{},
+		},
+		{
+			name:        "modules removed",
+			policy:      ModuleRemovalPolicyUninstall,
+			specModules: []string{"base"},
+			installed:   []string{"base", "sale", "stock"},
+			want:        []string{"sale", "stock"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment(tc.specModules, tc.installed)
+			o.Spec.ModuleRemovalPolicy = tc.policy
+
+			job, modules := o.GetDbUninstallJobTemplate()
+			if strings.Join(modules, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("modules = %v, want %v", modules, tc.want)
+			}
+			if len(tc.want) == 0 {
+				if job.Name != "" {
+					t.Errorf("job name = %q, want no job", job.Name)
+				}
+				return
+			}
+
+			if job.Name != "test-odoo-uninstall" {
+				t.Errorf("job name = %q, want %q", job.Name, "test-odoo-uninstall")
+			}
+			container := job.Spec.Template.Spec.Containers[0]
+			cmd := strings.Join(container.Command, " ")
+			if !strings.HasSuffix(cmd, "sh odoo shell -c /opt/odoo/odoo.conf --no-http") {
+				t.Errorf("command = %q, want it to run odoo shell", cmd)
+			}
+			env := map[string]string{}
+			for _, e := range container.Env {
+				env[e.Name] = e.Value
+			}
+			if env["UNINSTALL_MODULES"] != "sale,stock" {
+				t.Errorf("UNINSTALL_MODULES = %q, want %q", env["UNINSTALL_MODULES"], "sale,stock")
+			}
+			if !strings.Contains(env["UNINSTALL_SCRIPT"], "button_immediate_uninstall") {
+				t.Errorf("UNINSTALL_SCRIPT = %q, want it to call button_immediate_uninstall", env["UNINSTALL_SCRIPT"])
+			}
+		})
+	}
+}
+
+func TestGetPollPath(t *testing.T) {
+	tests := []struct {
+		image   string
+		version int
+		want    string
+	}{
+		{image: "odoo:18", version: 18, want: "/websocket"},
+		{image: "odoo:16.0", version: 16, want: "/websocket"},
+		{image: "odoo:15.0-20230101", version: 15, want: "/longpolling"},
+		{image: "registry.example.com:5000/odoo:14.0@sha256:abc", version: 14, want: "/longpolling"},
+		{image: "registry.example.com:5000/odoo", version: 0, want: "/websocket"},
+		{image: "odoo:latest", version: 0, want: "/websocket"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.image, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, nil)
+			o.Spec.Image = tc.image
+
+			if got := o.GetOdooMajorVersion(); got != tc.version {
+				t.Errorf("GetOdooMajorVersion() = %d, want %d", got, tc.version)
+			}
+			if got := o.GetPollPath(); got != tc.want {
+				t.Errorf("GetPollPath() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetIngressTemplate(t *testing.T) {
+	className := "nginx"
+	o := minimalOdooDeployment([]string{"base"}, nil)
+	o.Spec.Image = "odoo:15.0"
+	o.Spec.Config.Workers = 2
+	o.Spec.Ingress = &OdooIngressConfig{
+		Hosts:            []string{"odoo.example.com", "erp.example.com"},
+		TLSSecretName:    "odoo-tls",
+		IngressClassName: &className,
+		Annotations:      map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "100m"},
+	}
+
+	ingress := o.GetIngressTemplate()
+
+	if ingress.Name != "test-odoo" {
+		t.Errorf("ingress name = %q, want %q", ingress.Name, "test-odoo")
+	}
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("ingressClassName = %v, want nginx", ingress.Spec.IngressClassName)
+	}
+	if ingress.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"] != "100m" {
+		t.Errorf("annotations = %v, want the annotations of spec.ingress", ingress.Annotations)
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "odoo-tls" || len(ingress.Spec.TLS[0].Hosts) != 2 {
+		t.Errorf("tls = %v, want odoo-tls for both hosts", ingress.Spec.TLS)
+	}
+	if len(ingress.Spec.Rules) != 2 {
+		t.Fatalf("rules = %d, want one per host", len(ingress.Spec.Rules))
+	}
+	for _, rule := range ingress.Spec.Rules {
+		paths := rule.HTTP.Paths
+		if len(paths) != 2 {
+			t.Fatalf("host %s: paths = %d, want 2", rule.Host, len(paths))
+		}
+		if paths[0].Path != "/longpolling" || paths[0].Backend.Service.Name != "test-odoo-poll" {
+			t.Errorf("host %s: first path = %s -> %s, want /longpolling -> test-odoo-poll", rule.Host, paths[0].Path, paths[0].Backend.Service.Name)
+		}
+		if paths[1].Path != "/" || paths[1].Backend.Service.Name != "test-odoo-http" {
+			t.Errorf("host %s: second path = %s -> %s, want / -> test-odoo-http", rule.Host, paths[1].Path, paths[1].Backend.Service.Name)
+		}
+	}
+
+	o.Spec.Ingress.TLSSecretName = ""
+	if ingress := o.GetIngressTemplate(); len(ingress.Spec.TLS) != 0 {
+		t.Errorf("tls = %v, want none without a TLS secret", ingress.Spec.TLS)
+	}
+
+	// Without workers nothing listens on the poll port, the poll path goes to the http service
+	o.Spec.Config.Workers = 0
+	for _, rule := range o.GetIngressTemplate().Spec.Rules {
+		if backend := rule.HTTP.Paths[0].Backend.Service.Name; backend != "test-odoo-http" {
+			t.Errorf("host %s: /longpolling -> %s without workers, want test-odoo-http", rule.Host, backend)
+		}
+	}
+}
+
+func TestGetHTTPRouteTemplate(t *testing.T) {
+	port := int32(443)
+	o := minimalOdooDeployment([]string{"base"}, nil)
+	o.Spec.Config.Workers = 2
+	o.Spec.Exposure = &OdooExposureConfig{
+		Gateway: &OdooGatewayConfig{
+			ParentRefs: []GatewayParentReference{
+				{Name: "public", Namespace: "gateways", SectionName: "https", Port: &port},
+				{Name: "internal"},
+			},
+			Hostnames: []string{"odoo.example.com"},
+		},
+	}
+
+	route := o.GetHTTPRouteTemplate()
+
+	if route.GroupVersionKind() != HTTPRouteGroupVersionKind {
+		t.Errorf("gvk = %v, want %v", route.GroupVersionKind(), HTTPRouteGroupVersionKind)
+	}
+	if route.GetName() != "test-odoo" || route.GetNamespace() != "default" {
+		t.Errorf("route = %s/%s, want default/test-odoo", route.GetNamespace(), route.GetName())
+	}
+
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parentRefs) != 2 {
+		t.Fatalf("parentRefs = %d, want 2", len(parentRefs))
+	}
+	public := parentRefs[0].(map[string]interface{})
+	if public["namespace"] != "gateways" || public["sectionName"] != "https" || public["port"] != int64(443) || public["kind"] != "Gateway" {
+		t.Errorf("first parentRef = %v, want gateways/public https:443", public)
+	}
+	if _, ok := parentRefs[1].(map[string]interface{})["namespace"]; ok {
+		t.Errorf("second parentRef = %v, want no namespace", parentRefs[1])
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if strings.Join(hostnames, ",") != "odoo.example.com" {
+		t.Errorf("hostnames = %v, want odoo.example.com", hostnames)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if len(rules) != 2 {
+		t.Fatalf("rules = %d, want 2", len(rules))
+	}
+	want := []struct {
+		path    string
+		service string
+		port    int64
+	}{
+		{path: "/websocket", service: "test-odoo-poll", port: 8072},
+		{path: "/", service: "test-odoo-http", port: 8069},
+	}
+	for i, w := range want {
+		rule := rules[i].(map[string]interface{})
+		matches, _, _ := unstructured.NestedSlice(rule, "matches")
+		path, _, _ := unstructured.NestedString(matches[0].(map[string]interface{}), "path", "value")
+		backendRefs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		backend := backendRefs[0].(map[string]interface{})
+		if path != w.path || backend["name"] != w.service || backend["port"] != w.port {
+			t.Errorf("rule %d = %s -> %v:%v, want %s -> %s:%d", i, path, backend["name"], backend["port"], w.path, w.service, w.port)
+		}
+	}
+
+	// The template must survive a deep copy, as done by the client
+	route.DeepCopy()
+
+	// Without workers nothing listens on the poll port, the poll path goes to the http service
+	o.Spec.Config.Workers = 0
+	rules, _, _ = unstructured.NestedSlice(o.GetHTTPRouteTemplate().Object, "spec", "rules")
+	backendRefs, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "backendRefs")
+	if backend := backendRefs[0].(map[string]interface{}); backend["name"] != "test-odoo-http" || backend["port"] != int64(8069) {
+		t.Errorf("/websocket -> %v:%v without workers, want test-odoo-http:8069", backend["name"], backend["port"])
+	}
+}
+
+func TestGetHTTPRouteStatus(t *testing.T) {
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "test-odoo"},
+		"status": map[string]interface{}{
+			"parents": []interface{}{
+				map[string]interface{}{
+					"controllerName": "example.com/gateway-controller",
+					"parentRef":      map[string]interface{}{"name": "public", "namespace": "gateways"},
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "True", "reason": "Accepted", "message": "", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+						map[string]interface{}{"type": "ResolvedRefs", "status": "False", "reason": "BackendNotFound", "message": "service not found", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+						map[string]interface{}{"type": "Programmed", "status": "True", "reason": "Programmed", "message": "", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+					},
+				},
+			},
+		},
+	}}
+
+	status, err := GetHTTPRouteStatus(route)
+	if err != nil {
+		t.Fatalf("GetHTTPRouteStatus() error = %v", err)
+	}
+	if status.Name != "test-odoo" || len(status.Parents) != 1 {
+		t.Fatalf("status = %+v, want one parent of test-odoo", status)
+	}
+	parent := status.Parents[0]
+	if parent.Name != "public" || parent.Namespace != "gateways" {
+		t.Errorf("parent = %s/%s, want gateways/public", parent.Namespace, parent.Name)
+	}
+	if len(parent.Conditions) != 2 {
+		t.Fatalf("conditions = %v, want Accepted and ResolvedRefs only", parent.Conditions)
+	}
+	if parent.Conditions[1].Type != "ResolvedRefs" || parent.Conditions[1].Status != metav1.ConditionFalse || parent.Conditions[1].Reason != "BackendNotFound" {
+		t.Errorf("ResolvedRefs = %+v, want False BackendNotFound", parent.Conditions[1])
+	}
+
+	status, err = GetHTTPRouteStatus(&unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "new"}}})
+	if err != nil || len(status.Parents) != 0 {
+		t.Errorf("GetHTTPRouteStatus() of a new route = %+v, %v, want no parents", status, err)
+	}
+}
+
+func TestGetCertificateTemplate(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, nil)
+	o.Spec.Ingress = &OdooIngressConfig{Hosts: []string{"odoo.example.com", "erp.example.com"}}
+	o.Spec.Exposure = &OdooExposureConfig{
+		Gateway: &OdooGatewayConfig{
+			ParentRefs: []GatewayParentReference{{Name: "public"}},
+			Hostnames:  []string{"odoo.example.com", "www.example.com"},
+		},
+		Certificate: &OdooCertificateConfig{
+			IssuerRef: CertificateIssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+		},
+	}
+
+	certificate := o.GetCertificateTemplate()
+
+	if certificate.GroupVersionKind() != CertificateGroupVersionKind {
+		t.Errorf("gvk = %v, want %v", certificate.GroupVersionKind(), CertificateGroupVersionKind)
+	}
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	if strings.Join(dnsNames, ",") != "erp.example.com,odoo.example.com,www.example.com" {
+		t.Errorf("dnsNames = %v, want the sorted hostnames of the ingress and the route", dnsNames)
+	}
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	if secretName != "test-odoo-tls" {
+		t.Errorf("secretName = %q, want %q", secretName, "test-odoo-tls")
+	}
+	issuerKind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind")
+	if issuerKind != "ClusterIssuer" {
+		t.Errorf("issuerRef.kind = %q, want ClusterIssuer", issuerKind)
+	}
+	certificate.DeepCopy()
+
+	// The Ingress terminates TLS with the certificate unless it has its own secret
+	if ingress := o.GetIngressTemplate(); len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "test-odoo-tls" {
+		t.Errorf("ingress tls = %v, want test-odoo-tls", ingress.Spec.TLS)
+	}
+	o.Spec.Ingress.TLSSecretName = "custom-tls"
+	o.Spec.Exposure.Certificate.SecretName = "odoo-cert"
+	if ingress := o.GetIngressTemplate(); ingress.Spec.TLS[0].SecretName != "custom-tls" {
+		t.Errorf("ingress tls = %v, want custom-tls", ingress.Spec.TLS)
+	}
+	if got := o.GetCertificateSecretName(); got != "odoo-cert" {
+		t.Errorf("GetCertificateSecretName() = %q, want %q", got, "odoo-cert")
+	}
+}
+
+func TestGetCertificateReadyCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []interface{}
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "not issued yet",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: ReasonCertificatePending,
+		},
+		{
+			name: "ready",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "reason": "Ready", "message": "Certificate is up to date and has not expired", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Ready",
+		},
+		{
+			name: "issuing",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Issuing", "status": "True", "reason": "DoesNotExist", "message": "", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "DoesNotExist", "message": "Issuing certificate as Secret does not exist", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "DoesNotExist",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "test-odoo"},
+			}}
+			if tc.conditions != nil {
+				certificate.Object["status"] = map[string]interface{}{"conditions": tc.conditions}
+			}
+
+			condition, err := GetCertificateReadyCondition(certificate)
+			if err != nil {
+				t.Fatalf("GetCertificateReadyCondition() error = %v", err)
+			}
+			if condition.Status != tc.wantStatus || condition.Reason != tc.wantReason {
+				t.Errorf("condition = %s/%s, want %s/%s", condition.Status, condition.Reason, tc.wantStatus, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestPollServerPerOdooVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		image       string
+		odooVersion int32
+		wantVersion int
+		wantOption  string
+		wantPath    string
+	}{
+		{name: "14 from the tag", image: "odoo:14.0", wantVersion: 14, wantOption: "longpolling_port", wantPath: "/longpolling"},
+		{name: "15 from the tag", image: "odoo:15", wantVersion: 15, wantOption: "longpolling_port", wantPath: "/longpolling"},
+		{name: "16 from the tag", image: "odoo:16.0-20240101", wantVersion: 16, wantOption: "gevent_port", wantPath: "/websocket"},
+		{name: "17 from the tag", image: "odoo:17.0", wantVersion: 17, wantOption: "gevent_port", wantPath: "/websocket"},
+		{name: "18 from the tag", image: "odoo:18", wantVersion: 18, wantOption: "gevent_port", wantPath: "/websocket"},
+		{name: "15 set explicitly", image: "registry.example.com/custom-odoo:stable", odooVersion: 15, wantVersion: 15, wantOption: "longpolling_port", wantPath: "/longpolling"},
+		{name: "explicit version wins over the tag", image: "odoo:14.0", odooVersion: 17, wantVersion: 17, wantOption: "gevent_port", wantPath: "/websocket"},
+		{name: "unknown version", image: "registry.example.com/custom-odoo:stable", wantVersion: 0, wantOption: "gevent_port", wantPath: "/websocket"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, nil)
+			o.Spec.Image = tc.image
+			o.Spec.OdooVersion = tc.odooVersion
+			o.Spec.Config.PollPort = 8090
+
+			version := o.GetOdooMajorVersion()
+			if version != tc.wantVersion {
+				t.Errorf("GetOdooMajorVersion() = %d, want %d", version, tc.wantVersion)
+			}
+			if got := GetPollPortOption(version); got != tc.wantOption {
+				t.Errorf("GetPollPortOption() = %q, want %q", got, tc.wantOption)
+			}
+			if got := o.GetPollPath(); got != tc.wantPath {
+				t.Errorf("GetPollPath() = %q, want %q", got, tc.wantPath)
+			}
+
+			config := o.Spec.Config.GetSerializedOdooConfig(
+				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo", SSLModePrefer, "", "", 0,
+				[]string{}, version,
+			)
+			if want := tc.wantOption + " = 8090\n"; !strings.Contains(config, want) {
+				t.Errorf("config missing %q\ngot:\n%s", want, config)
+			}
+
+			var pollPort int32
+			for _, port := range o.GetPodSpec().Containers[0].Ports {
+				if port.Name == "poll" {
+					pollPort = port.ContainerPort
+				}
+			}
+			if pollPort != 8090 {
+				t.Errorf("poll container port = %d, want 8090", pollPort)
+			}
+			if service := o.GetPollServiceTemplate(); service.Spec.Ports[0].TargetPort.IntVal != 8090 {
+				t.Errorf("poll service target port = %v, want 8090", service.Spec.Ports[0].TargetPort)
+			}
+		})
+	}
+}
+
+func TestGetSerializedOdooConfig_SSL(t *testing.T) {
+	tests := []struct {
+		name         string
+		sslMode      SSLMode
+		sslRootCert  string
+		wantContains []string
+		wantAbsent   []string
+	}{
+		{
+			name:         "no ssl mode",
+			wantAbsent:   []string{"db_sslmode", "db_sslrootcert"},
+			wantContains: []string{},
+		},
+		{
+			name:         "require without a root certificate",
+			sslMode:      SSLModeRequire,
+			wantContains: []string{"db_sslmode = require\n"},
+			wantAbsent:   []string{"db_sslrootcert"},
+		},
+		{
+			name:         "verify-full with a root certificate",
+			sslMode:      SSLModeVerifyFull,
+			sslRootCert:  "/etc/odoo-db-ssl/root.crt",
+			wantContains: []string{"db_sslmode = verify-full\n", "db_sslrootcert = /etc/odoo-db-ssl/root.crt\n"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &OdooConfig{DataDir: "/var/lib/odoo", PollPort: 8072}
+			got := cfg.GetSerializedOdooConfig(
+				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo", tc.sslMode, tc.sslRootCert, "", 0,
+				[]string{}, 18,
+			)
+			for _, want := range tc.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("config missing %q\ngot:\n%s", want, got)
+				}
+			}
+			for _, absent := range tc.wantAbsent {
+				if strings.Contains(got, absent) {
+					t.Errorf("config unexpectedly contains %q\ngot:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPodSpec_SSLFiles(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, nil)
+
+	spec := o.GetPodSpec()
+	for _, v := range spec.Volumes {
+		if v.Name == "db-ssl" {
+			t.Fatalf("db-ssl volume must not be mounted without certificates")
+		}
+	}
+
+	o.Spec.Database.SSLRootCertFromSecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "db-ca"},
+		Key:                  "ca.crt",
+	}
+	o.Spec.Database.SSLCertFromSecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "db-client"},
+		Key:                  "tls.crt",
+	}
+	o.Spec.Database.SSLKeyFromSecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "db-client"},
+		Key:                  "tls.key",
+	}
+	if got := o.Spec.Database.GetSSLRootCertPath(); got != "/etc/odoo-db-ssl/root.crt" {
+		t.Errorf("GetSSLRootCertPath() = %q, want %q", got, "/etc/odoo-db-ssl/root.crt")
+	}
+
+	spec = o.GetPodSpec()
+	var volume *corev1.Volume
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == "db-ssl" {
+			volume = &spec.Volumes[i]
+		}
+	}
+	if volume == nil || volume.Projected == nil || len(volume.Projected.Sources) != 3 {
+		t.Fatalf("db-ssl volume = %+v, want a projection of the three certificates", volume)
+	}
+	if *volume.Projected.DefaultMode != 0640 {
+		t.Errorf("db-ssl default mode = %o, want 0640", *volume.Projected.DefaultMode)
+	}
+	paths := []string{}
+	for _, source := range volume.Projected.Sources {
+		paths = append(paths, source.Secret.Name+":"+source.Secret.Items[0].Key+"="+source.Secret.Items[0].Path)
+	}
+	if got := strings.Join(paths, ","); got != "db-ca:ca.crt=root.crt,db-client:tls.crt=client.crt,db-client:tls.key=client.key" {
+		t.Errorf("projected files = %s", got)
+	}
+
+	container := spec.Containers[0]
+	mounted := false
+	for _, m := range container.VolumeMounts {
+		if m.Name == "db-ssl" && m.MountPath == DatabaseSSLMountPath && m.ReadOnly {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("db-ssl must be mounted read-only at %s, got %v", DatabaseSSLMountPath, container.VolumeMounts)
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if env["PGSSLROOTCERT"] != "/etc/odoo-db-ssl/root.crt" || env["PGSSLCERT"] != "/etc/odoo-db-ssl/client.crt" || env["PGSSLKEY"] != "/etc/odoo-db-ssl/client.key" {
+		t.Errorf("unexpected libpq ssl env: %v", container.Env)
+	}
+
+	// Jobs built from the pod spec keep the certificates, the backup job mounts them itself
+	backup := minimalOdooBackup().GetBackupJobTemplate(o, DatabaseConnectionDetails{SSLMode: SSLModeVerifyFull}, "", "uploader:latest")
+	env = map[string]string{}
+	for _, e := range backup.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["PGSSLMODE"] != "verify-full" || env["PGSSLROOTCERT"] != "/etc/odoo-db-ssl/root.crt" {
+		t.Errorf("unexpected backup ssl env: %v", backup.Spec.Template.Spec.Containers[0].Env)
+	}
+	if len(backup.Spec.Template.Spec.Containers[0].VolumeMounts) != 3 {
+		t.Errorf("backup mounts = %v, want the filestore, the backup and db-ssl", backup.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestGetSerializedOdooConfig_Replica(t *testing.T) {
+	tests := []struct {
+		name         string
+		replicaHost  string
+		replicaPort  int32
+		wantContains []string
+		wantAbsent   []string
+	}{
+		{
+			name:       "no replica",
+			wantAbsent: []string{"db_replica_host", "db_replica_port"},
+		},
+		{
+			name:         "replica on the port of the primary",
+			replicaHost:  "cluster-1-ro",
+			wantContains: []string{"db_replica_host = cluster-1-ro\n"},
+			wantAbsent:   []string{"db_replica_port"},
+		},
+		{
+			name:         "replica on its own port",
+			replicaHost:  "cluster-1-ro",
+			replicaPort:  5433,
+			wantContains: []string{"db_replica_host = cluster-1-ro\n", "db_replica_port = 5433\n"},
+		},
+		{
+			name:        "port without a host",
+			replicaPort: 5433,
+			wantAbsent:  []string{"db_replica_host", "db_replica_port"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &OdooConfig{DataDir: "/var/lib/odoo", PollPort: 8072}
+			got := cfg.GetSerializedOdooConfig(
+				"adminpass", "localhost", 5432, "odoo", "dbpass", 20, "odoo", SSLModePrefer, "", tc.replicaHost, tc.replicaPort,
+				[]string{}, 18,
+			)
+			for _, want := range tc.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("config missing %q\ngot:\n%s", want, got)
+				}
+			}
+			for _, absent := range tc.wantAbsent {
+				if strings.Contains(got, absent) {
+					t.Errorf("config unexpectedly contains %q\ngot:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
+
+func newCNPGCluster(name string, initdbSecret string) *unstructured.Unstructured {
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(CNPGClusterGroupVersionKind)
+	cluster.SetName(name)
+	cluster.SetNamespace("default")
+	if initdbSecret != "" {
+		_ = unstructured.SetNestedField(cluster.Object, initdbSecret, "spec", "bootstrap", "initdb", "secret", "name")
+	}
+	return cluster
+}
+
+func TestGetCNPGAppSecretName(t *testing.T) {
+	if got := GetCNPGAppSecretName(newCNPGCluster("cluster-1", "")); got != "cluster-1-app" {
+		t.Errorf("GetCNPGAppSecretName() = %q, want %q", got, "cluster-1-app")
+	}
+	if got := GetCNPGAppSecretName(newCNPGCluster("cluster-1", "odoo-credentials")); got != "odoo-credentials" {
+		t.Errorf("GetCNPGAppSecretName() = %q, want %q", got, "odoo-credentials")
+	}
+}
+
+func TestGetDbConnectionDetails_CNPG(t *testing.T) {
+	appSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1-app", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte("app"),
+			"password": []byte("secret"),
+			"dbname":   []byte("odoo"),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		ref             CNPGClusterReference
+		replicaHost     string
+		wantReplicaHost string
+	}{
+		{
+			name: "primary only",
+			ref:  CNPGClusterReference{Name: "cluster-1"},
+		},
+		{
+			name:            "replica through the -ro service",
+			ref:             CNPGClusterReference{Name: "cluster-1", UseReplica: true},
+			replicaHost:     "other-replica",
+			wantReplicaHost: "cluster-1-ro",
+		},
+		{
+			name:            "replica from the database config",
+			ref:             CNPGClusterReference{Name: "cluster-1"},
+			replicaHost:     "other-replica",
+			wantReplicaHost: "other-replica",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(newCNPGCluster("cluster-1", ""), appSecret).Build()
+			dbConfig := &OdooDatabaseConfig{
+				CNPGClusterRef: &tc.ref,
+				SSLMode:        SSLModeRequire,
+				MaxConn:        32,
+				ReplicaHost:    tc.replicaHost,
+			}
+
+			got, err := dbConfig.GetDbConnectionDetails(c, context.Background(), "default")
+			if err != nil {
+				t.Fatalf("GetDbConnectionDetails() error = %v", err)
+			}
+			if got.Host != "cluster-1-rw" || got.Port != 5432 {
+				t.Errorf("primary = %s:%d, want cluster-1-rw:5432", got.Host, got.Port)
+			}
+			if got.User != "app" || got.Password != "secret" || got.Name != "odoo" {
+				t.Errorf("credentials = %s/%s/%s, want app/secret/odoo", got.User, got.Password, got.Name)
+			}
+			if got.SSLMode != SSLModeRequire || got.MaxConn != 32 {
+				t.Errorf("sslmode/maxconn = %s/%d, want require/32", got.SSLMode, got.MaxConn)
+			}
+			if got.ReplicaHost != tc.wantReplicaHost {
+				t.Errorf("ReplicaHost = %q, want %q", got.ReplicaHost, tc.wantReplicaHost)
+			}
+			if got.PasswordSecret.Name != "cluster-1-app" || got.PasswordSecret.Key != "password" {
+				t.Errorf("PasswordSecret = %+v, want cluster-1-app/password", got.PasswordSecret)
+			}
+		})
+	}
+}
+
+func TestGetDbConnectionDetails_CNPGClusterMissing(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	dbConfig := &OdooDatabaseConfig{CNPGClusterRef: &CNPGClusterReference{Name: "cluster-1"}}
+
+	if _, err := dbConfig.GetDbConnectionDetails(c, context.Background(), "default"); err == nil {
+		t.Fatal("GetDbConnectionDetails() expected an error for a missing Cluster")
+	}
+}
+
+func TestGetMinPostgresVersion(t *testing.T) {
+	tests := []struct {
+		odooMajorVersion int
+		want             int
+	}{
+		{odooMajorVersion: 0, want: 130000},
+		{odooMajorVersion: 13, want: 90500},
+		{odooMajorVersion: 14, want: 100000},
+		{odooMajorVersion: 16, want: 120000},
+		{odooMajorVersion: 17, want: 120000},
+		{odooMajorVersion: 18, want: 130000},
+		{odooMajorVersion: 19, want: 130000},
+	}
+
+	for _, tc := range tests {
+		if got := GetMinPostgresVersion(tc.odooMajorVersion); got != tc.want {
+			t.Errorf("GetMinPostgresVersion(%d) = %d, want %d", tc.odooMajorVersion, got, tc.want)
+		}
+	}
+}
+
+func TestGetConnectionBudget(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxConn        int32
+		workers        int32
+		maxCronThreads int32
+		replicas       int32
+		pooler         *OdooDatabasePooler
+		want           int32
+	}{
+		{name: "threaded", maxConn: 64, maxCronThreads: 2, replicas: 1, want: 192},
+		{name: "workers", maxConn: 20, workers: 4, maxCronThreads: 1, replicas: 1, want: 120},
+		{name: "replicas", maxConn: 20, workers: 4, maxCronThreads: 1, replicas: 3, want: 360},
+		{
+			name:    "sidecar pooler",
+			maxConn: 20, workers: 4, maxCronThreads: 1, replicas: 3,
+			pooler: &OdooDatabasePooler{Mode: PoolerModeSidecar, DefaultPoolSize: 10},
+			want:   30,
+		},
+		{
+			name:    "pooler deployment",
+			maxConn: 20, workers: 4, maxCronThreads: 1, replicas: 3,
+			pooler: &OdooDatabasePooler{Mode: PoolerModeDeployment, DefaultPoolSize: 25, Replicas: 2},
+			want:   50,
+		},
+		{
+			name:    "pooler larger than the pools of Odoo",
+			maxConn: 2, maxCronThreads: 1, replicas: 1,
+			pooler: &OdooDatabasePooler{Mode: PoolerModeDeployment, DefaultPoolSize: 20, Replicas: 1},
+			want:   4,
+		},
+		{
+			name:    "pooler mode none",
+			maxConn: 20, workers: 4, maxCronThreads: 1, replicas: 1,
+			pooler: &OdooDatabasePooler{Mode: PoolerModeNone, DefaultPoolSize: 10},
+			want:   120,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment(nil, nil)
+			o.Spec.Config.Workers = tc.workers
+			o.Spec.Config.MaxCronThreads = tc.maxCronThreads
+			o.Spec.Replicas = tc.replicas
+			o.Spec.Database.Pooler = tc.pooler
+			if got := o.GetConnectionBudget(tc.maxConn); got != tc.want {
+				t.Errorf("GetConnectionBudget(%d) = %d, want %d", tc.maxConn, got, tc.want)
+			}
+		})
+	}
+}
+
+// newPooledOdooDeployment returns an OdooDeployment behind a pooler of the mode with the defaults of the CRD
+func newPooledOdooDeployment(mode PoolerMode) *OdooDeployment {
+	o := minimalOdooDeployment([]string{"base"}, nil)
+	o.Spec.Database.Pooler = &OdooDatabasePooler{
+		Mode:            mode,
+		PoolMode:        PoolModeSession,
+		Image:           "ghcr.io/cloudnative-pg/pgbouncer:1.24.1",
+		DefaultPoolSize: 20,
+		MaxClientConn:   1000,
+		Replicas:        1,
+	}
+	return o
+}
+
+func TestGetSerializedPgBouncerConfig(t *testing.T) {
+	details := DatabaseConnectionDetails{Host: "cluster-1-rw", Port: 5432}
+
+	o := newPooledOdooDeployment(PoolerModeSidecar)
+	got := o.GetSerializedPgBouncerConfig(details)
+	for _, line := range []string{
+		"* = host=cluster-1-rw port=5432\n",
+		"listen_addr = 127.0.0.1\n",
+		"listen_port = 6432\n",
+		"auth_file = /etc/pgbouncer/userlist.txt\n",
+		"pool_mode = session\n",
+		"default_pool_size = 20\n",
+		"max_client_conn = 1000\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("pgbouncer.ini must contain %q, got:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "server_tls") {
+		t.Errorf("pgbouncer.ini must not configure TLS without an sslmode, got:\n%s", got)
+	}
+
+	o = newPooledOdooDeployment(PoolerModeDeployment)
+	o.Spec.Database.SSLRootCertFromSecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "db-ca"},
+		Key:                  "ca.crt",
+	}
+	details.SSLMode = SSLModeVerifyFull
+	got = o.GetSerializedPgBouncerConfig(details)
+	for _, line := range []string{
+		"listen_addr = 0.0.0.0\n",
+		"server_tls_sslmode = verify-full\n",
+		"server_tls_ca_file = /etc/odoo-db-ssl/root.crt\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("pgbouncer.ini must contain %q, got:\n%s", line, got)
+		}
+	}
+}
+
+func TestGetSerializedPgBouncerUserlist(t *testing.T) {
+	got := GetSerializedPgBouncerUserlist("odoo", `it's "secret"`)
+	want := `"odoo" "it's ""secret"""` + "\n"
+	if got != want {
+		t.Errorf("GetSerializedPgBouncerUserlist() = %q, want %q", got, want)
+	}
+}
+
+func TestGetPodSpec_PoolerSidecar(t *testing.T) {
+	o := newPooledOdooDeployment(PoolerModeSidecar)
+
+	spec := o.GetPodSpec()
+	if len(spec.InitContainers) != 1 || spec.InitContainers[0].Name != "pgbouncer" {
+		t.Fatalf("init containers = %v, want the pgbouncer sidecar", spec.InitContainers)
+	}
+	sidecar := spec.InitContainers[0]
+	if sidecar.RestartPolicy == nil || *sidecar.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+		t.Errorf("pgbouncer must be a native sidecar, restart policy = %v", sidecar.RestartPolicy)
+	}
+	found := false
+	for _, v := range spec.Volumes {
+		if v.Name == "pooler-config" && v.Secret != nil && v.Secret.SecretName == "test-odoo-config" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("pooler-config volume of the config secret missing, volumes = %v", spec.Volumes)
+	}
+
+	// The jobs are built from the pod spec and connect through the sidecar as well
+	job, _ := o.GetDbInitJobTemplate()
+	if len(job.Spec.Template.Spec.InitContainers) != 1 {
+		t.Errorf("init job init containers = %v, want the pgbouncer sidecar", job.Spec.Template.Spec.InitContainers)
+	}
+
+	o = newPooledOdooDeployment(PoolerModeDeployment)
+	if spec := o.GetPodSpec(); len(spec.InitContainers) != 0 {
+		t.Errorf("init containers = %v, want none in deployment mode", spec.InitContainers)
+	}
+}
+
+func TestCreateOdooConfigSecretObj_Pooler(t *testing.T) {
+	passwordSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "odoo-db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	c := fake.NewClientBuilder().WithObjects(passwordSecret).Build()
+
+	tests := []struct {
+		name       string
+		mode       PoolerMode
+		wantHost   string
+		wantPooler bool
+	}{
+		{name: "no pooler", mode: PoolerModeNone, wantHost: "db_host = postgres\ndb_port = 5432\n"},
+		{name: "sidecar", mode: PoolerModeSidecar, wantHost: "db_host = 127.0.0.1\ndb_port = 6432\n", wantPooler: true},
+		{name: "deployment", mode: PoolerModeDeployment, wantHost: "db_host = test-odoo-pooler\ndb_port = 6432\n", wantPooler: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newPooledOdooDeployment(tc.mode)
+			o.Spec.Database.Host = "postgres"
+			o.Spec.Database.Port = 5432
+			o.Spec.Database.User = "odoo"
+			o.Spec.Database.Name = "odoo"
+			o.Spec.Database.SSLMode = SSLModeRequire
+			o.Spec.Database.PasswordFromSecret = corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "odoo-db"},
+				Key:                  "password",
+			}
+
+			secret, err := o.CreateOdooConfigSecretObj(c, context.Background(), "admin")
+			if err != nil {
+				t.Fatalf("CreateOdooConfigSecretObj() error = %v", err)
+			}
+			config := string(secret.Data["odoo.conf"])
+			if !strings.Contains(config, tc.wantHost) {
+				t.Errorf("odoo.conf must contain %q, got:\n%s", tc.wantHost, config)
+			}
+			if _, ok := secret.Data["pgbouncer.ini"]; ok != tc.wantPooler {
+				t.Errorf("pgbouncer.ini in the secret = %v, want %v", ok, tc.wantPooler)
+			}
+			if tc.wantPooler {
+				if !strings.Contains(config, "db_sslmode = disable\n") {
+					t.Errorf("Odoo must connect to the pooler without SSL, got:\n%s", config)
+				}
+				if !strings.Contains(string(secret.Data["pgbouncer.ini"]), "server_tls_sslmode = require\n") {
+					t.Errorf("the pooler must connect with the sslmode of the database, got:\n%s", secret.Data["pgbouncer.ini"])
+				}
+				if string(secret.Data["userlist.txt"]) != "\"odoo\" \"secret\"\n" {
+					t.Errorf("userlist.txt = %q", secret.Data["userlist.txt"])
+				}
+			}
+		})
+	}
+}
+
+func TestGetPoolerDeploymentTemplate(t *testing.T) {
+	o := newPooledOdooDeployment(PoolerModeDeployment)
+	o.Spec.Database.Pooler.Replicas = 2
+
+	deployment := o.GetPoolerDeploymentTemplate()
+	if deployment.Name != "test-odoo-pooler" || *deployment.Spec.Replicas != 2 {
+		t.Errorf("deployment = %s with %d replicas, want test-odoo-pooler with 2", deployment.Name, *deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Template.Labels["app"] != "test-odoo-pooler" {
+		t.Errorf("pod labels = %v, must not select the Odoo pods", deployment.Spec.Template.Labels)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Image != o.Spec.Database.Pooler.Image || container.ReadinessProbe == nil {
+		t.Errorf("container = %+v, want the pooler image with a readiness probe", container)
+	}
+
+	service := o.GetPoolerServiceTemplate()
+	if service.Spec.Ports[0].Port != PoolerPort || service.Spec.Selector["app"] != "test-odoo-pooler" {
+		t.Errorf("service = %+v, want port %d selecting the pooler", service.Spec, PoolerPort)
+	}
+	if !o.UsesDeployment("test-odoo-pooler") || !o.UsesService("test-odoo-pooler") {
+		t.Errorf("the pooler Deployment and Service must map to the OdooDeployment")
+	}
+}
+
+func TestGetAutoTunedConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources corev1.ResourceRequirements
+		want      AutoTunedConfig
+		wantOK    bool
+	}{
+		{
+			name: "bound by the CPU and the memory",
+			resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+			want:   AutoTunedConfig{Workers: 5, MaxCronThreads: 2, LimitMemorySoft: 429496729, LimitMemoryHard: 536870912},
+			wantOK: true,
+		},
+		{
+			name: "bound by the CPU",
+			resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+			want:   AutoTunedConfig{Workers: 2, MaxCronThreads: 1, LimitMemorySoft: 858993459, LimitMemoryHard: 1073741824},
+			wantOK: true,
+		},
+		{
+			name: "limits before requests",
+			resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+			want:   AutoTunedConfig{Workers: 5, MaxCronThreads: 2, LimitMemorySoft: 429496729, LimitMemoryHard: 536870912},
+			wantOK: true,
+		},
+		{
+			name: "memory request only keeps one worker",
+			resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			want:   AutoTunedConfig{Workers: 1, MaxCronThreads: 1, LimitMemorySoft: 286331152, LimitMemoryHard: 357913941},
+			wantOK: true,
+		},
+		{
+			name: "without memory",
+			resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := GetAutoTunedConfig(tc.resources)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("GetAutoTunedConfig() = %+v, %v, want %+v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentTemplate_Probes(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *OdooDeployment)
+		check  func(t *testing.T, container corev1.Container)
+	}{
+		{
+			name:   "health endpoint by default",
+			modify: func(o *OdooDeployment) {},
+			check: func(t *testing.T, container corev1.Container) {
+				for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe, container.StartupProbe} {
+					if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Path != "/web/health" || probe.HTTPGet.Port.String() != "http" {
+						t.Errorf("probe = %+v, want GET /web/health on the http port", probe)
+					}
+				}
+				if container.StartupProbe.FailureThreshold*container.StartupProbe.PeriodSeconds < 600 {
+					t.Errorf("startup probe = %+v, want at least 10 minutes to load the modules", container.StartupProbe)
+				}
+			},
+		},
+		{
+			name:   "tcp check before Odoo 16",
+			modify: func(o *OdooDeployment) { o.Spec.Image = "odoo:15" },
+			check: func(t *testing.T, container corev1.Container) {
+				if probe := container.ReadinessProbe; probe.HTTPGet != nil || probe.TCPSocket == nil {
+					t.Errorf("readiness probe = %+v, want a tcp check", probe)
+				}
+			},
+		},
+		{
+			name: "overridden readiness probe",
+			modify: func(o *OdooDeployment) {
+				o.Spec.Probes = &OdooProbesConfig{
+					Readiness: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{Path: "/web/login", Port: intstr.FromInt32(8069)},
+						},
+						PeriodSeconds: 5,
+					},
+				}
+			},
+			check: func(t *testing.T, container corev1.Container) {
+				probe := container.ReadinessProbe
+				if probe.HTTPGet.Path != "/web/login" || probe.PeriodSeconds != 5 {
+					t.Errorf("readiness probe = %+v, want the override", probe)
+				}
+				// Fields the API server defaults are set, the Deployment would drift otherwise
+				if probe.HTTPGet.Scheme != corev1.URISchemeHTTP || probe.TimeoutSeconds != 1 || probe.SuccessThreshold != 1 || probe.FailureThreshold != 3 {
+					t.Errorf("readiness probe = %+v, want the defaults of the API server", probe)
+				}
+				if container.LivenessProbe.HTTPGet.Path != "/web/health" {
+					t.Errorf("liveness probe = %+v, want the default", container.LivenessProbe)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment(nil, nil)
+			tc.modify(o)
+			tc.check(t, o.GetDeploymentTemplate().Spec.Template.Spec.Containers[0])
+		})
+	}
+}
+
+func TestGetDeploymentTemplate_PreStop(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, nil)
+	o.Spec.Config.LimitTimeReal = 120
+
+	podSpec := o.GetDeploymentTemplate().Spec.Template.Spec
+	lifecycle := podSpec.Containers[0].Lifecycle
+	if lifecycle == nil || lifecycle.PreStop == nil || lifecycle.PreStop.Exec == nil {
+		t.Fatalf("lifecycle = %+v, want a preStop hook", lifecycle)
+	}
+	if got := *podSpec.TerminationGracePeriodSeconds; got != 130 {
+		t.Errorf("terminationGracePeriodSeconds = %d, want the preStop delay and limitTimeReal", got)
+	}
+
+	// The jobs run to completion, they are not probed
+	job, _ := o.GetDbInitJobTemplate()
+	container := job.Spec.Template.Spec.Containers[0]
+	if container.ReadinessProbe != nil || container.StartupProbe != nil || container.Lifecycle != nil {
+		t.Errorf("init job container = %+v, want no probes nor preStop hook", container)
+	}
+}
+
+// newScheduledOdooDeployment returns an OdooDeployment with a ReadWriteOnce filestore whose pods are
+// pinned to a node pool by spec.podTemplate
+func newScheduledOdooDeployment() *OdooDeployment {
+	o := minimalOdooDeployment([]string{"base"}, nil)
+	o.Spec.OdooFilestore.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	o.Spec.PodTemplate = &OdooPodTemplate{
+		Labels:       map[string]string{"app": "other", "team": "erp"},
+		Annotations:  map[string]string{"example.com/owner": "erp"},
+		NodeSelector: map[string]string{"pool": "odoo"},
+		Affinity: &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{
+						Weight: 50,
+						PodAffinityTerm: corev1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test-odoo"}},
+							TopologyKey:   corev1.LabelTopologyZone,
+						},
+					},
+				},
+			},
+		},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "odoo", Effect: corev1.TaintEffectNoSchedule},
+		},
+		PriorityClassName: "business-critical",
+		RuntimeClassName:  func(s string) *string { return &s }("gvisor"),
+	}
+	return o
+}
+
+func TestApplyPodTemplate(t *testing.T) {
+	o := newScheduledOdooDeployment()
+	initJob, _ := o.GetDbInitJobTemplate()
+	backup := minimalOdooBackup().GetBackupJobTemplate(o, DatabaseConnectionDetails{}, "node-1", "uploader:latest")
+
+	templates := map[string]corev1.PodTemplateSpec{
+		"deployment": o.GetDeploymentTemplate().Spec.Template,
+		"init job":   initJob.Spec.Template,
+		"backup job": backup.Spec.Template,
+	}
+	for name, template := range templates {
+		podSpec := template.Spec
+		if podSpec.NodeSelector["pool"] != "odoo" || len(podSpec.Tolerations) != 1 || podSpec.PriorityClassName != "business-critical" ||
+			podSpec.RuntimeClassName == nil || *podSpec.RuntimeClassName != "gvisor" {
+			t.Errorf("%s pod spec = %+v, want the scheduling of spec.podTemplate", name, podSpec)
+		}
+		if podSpec.Affinity == nil || podSpec.Affinity.PodAntiAffinity == nil {
+			t.Errorf("%s affinity = %+v, want the pod anti-affinity of spec.podTemplate", name, podSpec.Affinity)
+		}
+		if template.Labels["team"] != "erp" || template.Annotations["example.com/owner"] != "erp" {
+			t.Errorf("%s metadata = %+v, want the labels and annotations of spec.podTemplate", name, template.ObjectMeta)
+		}
+		if template.Labels[OdooDeploymentLabel] != "test-odoo" {
+			t.Errorf("%s labels = %v, want the OdooDeployment label the pod cache selects", name, template.Labels)
+		}
+	}
+
+	if labels := templates["deployment"].Labels; labels["app"] != "test-odoo" {
+		t.Errorf("deployment labels = %v, the selector labels must take precedence", labels)
+	}
+	// The backup job keeps running on the node of the ReadWriteOnce filestore
+	if affinity := templates["backup job"].Spec.Affinity; affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		t.Errorf("backup job affinity = %+v, want the filestore node affinity", affinity)
+	}
+	// The overlay is copied, changing the pods must not change the spec
+	templates["deployment"].Spec.NodeSelector["pool"] = "other"
+	if o.Spec.PodTemplate.NodeSelector["pool"] != "odoo" {
+		t.Errorf("spec.podTemplate.nodeSelector was modified through the pod spec")
+	}
+}
+
+func TestPodSpecPatchApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   *PodSpecPatch
+		wantErr bool
+		check   func(t *testing.T, podSpec corev1.PodSpec)
+	}{
+		{
+			name: "strategic merge adds an env var and a sidecar",
+			patch: &PodSpecPatch{
+				Type: PodSpecPatchTypeStrategicMerge,
+				Patch: `
+containers:
+  - name: odoo
+    env:
+      - name: TZ
+        value: Europe/Berlin
+  - name: log-shipper
+    image: fluent-bit:3
+`,
+			},
+			check: func(t *testing.T, podSpec corev1.PodSpec) {
+				if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != "log-shipper" {
+					t.Errorf("containers = %+v, want odoo and log-shipper", podSpec.Containers)
+				}
+				odoo := podSpec.Containers[0]
+				if odoo.Image != "odoo:18" || len(odoo.Env) != 1 || odoo.Env[0].Name != "TZ" || len(odoo.VolumeMounts) != 3 {
+					t.Errorf("odoo container = %+v, want TZ merged into it", odoo)
+				}
+			},
+		},
+		{
+			name: "JSON patch",
+			patch: &PodSpecPatch{
+				Type:  PodSpecPatchTypeJSONPatch,
+				Patch: `[{"op": "add", "path": "/hostAliases", "value": [{"ip": "10.0.0.1", "hostnames": ["erp.internal"]}]}]`,
+			},
+			check: func(t *testing.T, podSpec corev1.PodSpec) {
+				if len(podSpec.HostAliases) != 1 || podSpec.HostAliases[0].IP != "10.0.0.1" {
+					t.Errorf("hostAliases = %+v, want the patched alias", podSpec.HostAliases)
+				}
+			},
+		},
+		{
+			name:  "strategic merge by default",
+			patch: &PodSpecPatch{Patch: `{"hostname": "odoo"}`},
+			check: func(t *testing.T, podSpec corev1.PodSpec) {
+				if podSpec.Hostname != "odoo" {
+					t.Errorf("hostname = %q, want odoo", podSpec.Hostname)
+				}
+			},
+		},
+		{
+			name:    "unknown field",
+			patch:   &PodSpecPatch{Patch: `nodeSelecter: {pool: odoo}`},
+			wantErr: true,
+		},
+		{
+			name:    "JSON patch of a missing path",
+			patch:   &PodSpecPatch{Type: PodSpecPatchTypeJSONPatch, Patch: `[{"op": "replace", "path": "/containers/3/image", "value": "odoo:17"}]`},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			podSpec := minimalOdooDeployment(nil, nil).GetPodSpec()
+			err := tc.patch.Apply(&podSpec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Apply() error = %v, want error: %t", err, tc.wantErr)
+			}
+			if tc.check != nil {
+				tc.check(t, podSpec)
+			}
+		})
+	}
+}
+
+func TestPatchPodSpec(t *testing.T) {
+	o := minimalOdooDeployment(nil, nil)
+	o.Spec.PodTemplatePatch = &OdooPodTemplatePatch{
+		Jobs: &PodSpecPatch{Patch: `{"activeDeadlineSeconds": 3600}`},
+	}
+
+	deploymentPodSpec := o.GetDeploymentTemplate().Spec.Template.Spec
+	want := *deploymentPodSpec.DeepCopy()
+	if err := o.PatchDeploymentPodSpec(&deploymentPodSpec); err != nil {
+		t.Fatalf("PatchDeploymentPodSpec() error = %v", err)
+	}
+	if diff := cmp.Diff(want, deploymentPodSpec); diff != "" {
+		t.Errorf("the Deployment must not be patched by the patch of the jobs: %s", diff)
+	}
+
+	jobPodSpec := o.GetPodSpec()
+	if err := o.PatchJobPodSpec(&jobPodSpec); err != nil {
+		t.Fatalf("PatchJobPodSpec() error = %v", err)
+	}
+	if jobPodSpec.ActiveDeadlineSeconds == nil || *jobPodSpec.ActiveDeadlineSeconds != 3600 {
+		t.Errorf("activeDeadlineSeconds = %v, want 3600", jobPodSpec.ActiveDeadlineSeconds)
+	}
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -51,90 +54,513 @@ const (
 	ReasonFailedGetPollService    = "FailedGetPollService"
 	ReasonFailedCreatePollService = "FailedCreatePollService"
 	ReasonFailedUpdatePollService = "FailedUpdatePollService"
+
+	ReasonInvalidBackupSchedule       = "InvalidBackupSchedule"
+	ReasonFailedCreateScheduledBackup = "FailedCreateScheduledBackup"
+	ReasonFailedPruneBackups          = "FailedPruneBackups"
+	ReasonScheduledBackupCreated      = "ScheduledBackupCreated"
+
+	ReasonFailedGetRestore = "FailedGetRestore"
+	ReasonRestoreReleased  = "RestoreReleased"
+
+	ReasonFailedGetMigration = "FailedGetMigration"
+	ReasonMigrationReleased  = "MigrationReleased"
+
+	ReasonFailedGetUpgradeJob      = "FailedGetUpgradeJob"
+	ReasonUpgradeJobCreationFailed = "UpgradeJobCreationFailed"
+	ReasonUpgradeJobCreated        = "UpgradeJobCreated"
+	ReasonUpgradeJobFailed         = "UpgradeJobFailed"
+	ReasonUpgradeJobSucceeded      = "UpgradeJobSucceeded"
+	ReasonFailedDeleteUpgradeJob   = "FailedDeleteUpgradeJob"
+	ReasonFailedGetDatabaseJob     = "FailedGetDatabaseJob"
+	ReasonWaitingForDatabaseJob    = "WaitingForDatabaseJob"
+
+	ReasonPreUpgradeBackupCreated        = "PreUpgradeBackupCreated"
+	ReasonPreUpgradeBackupCreationFailed = "PreUpgradeBackupCreationFailed"
+	ReasonPreUpgradeBackupFailed         = "PreUpgradeBackupFailed"
+	ReasonFailedGetPreUpgradeBackup      = "FailedGetPreUpgradeBackup"
+
+	ReasonUpgradeAwaitingApproval = "UpgradeAwaitingApproval"
+	ReasonFailedGetDeployedImage  = "FailedGetDeployedImage"
+
+	ReasonFailedGetImageDigestPod    = "FailedGetImageDigestPod"
+	ReasonFailedCreateImageDigestPod = "FailedCreateImageDigestPod"
+	ReasonFailedDeleteImageDigestPod = "FailedDeleteImageDigestPod"
+
+	ReasonFailedGetUninstallJob      = "FailedGetUninstallJob"
+	ReasonUninstallJobCreationFailed = "UninstallJobCreationFailed"
+	ReasonUninstallJobCreated        = "UninstallJobCreated"
+	ReasonUninstallJobFailed         = "UninstallJobFailed"
+	ReasonUninstallJobSucceeded      = "UninstallJobSucceeded"
+	ReasonFailedDeleteUninstallJob   = "FailedDeleteUninstallJob"
+
+	ReasonFailedGetIngress    = "FailedGetIngress"
+	ReasonFailedCreateIngress = "FailedCreateIngress"
+	ReasonFailedUpdateIngress = "FailedUpdateIngress"
+	ReasonFailedDeleteIngress = "FailedDeleteIngress"
+
+	ReasonFailedGetHTTPRoute    = "FailedGetHTTPRoute"
+	ReasonFailedCreateHTTPRoute = "FailedCreateHTTPRoute"
+	ReasonFailedUpdateHTTPRoute = "FailedUpdateHTTPRoute"
+	ReasonFailedDeleteHTTPRoute = "FailedDeleteHTTPRoute"
+
+	ReasonFailedGetCertificate    = "FailedGetCertificate"
+	ReasonFailedCreateCertificate = "FailedCreateCertificate"
+	ReasonFailedUpdateCertificate = "FailedUpdateCertificate"
+	ReasonFailedDeleteCertificate = "FailedDeleteCertificate"
+	ReasonCertificatePending      = "CertificatePending"
+	ReasonCertificateNoHostnames  = "CertificateNoHostnames"
+
+	ReasonDatabaseProvisioned          = "DatabaseProvisioned"
+	ReasonDatabaseProvisioningFailed   = "DatabaseProvisioningFailed"
+	ReasonFailedGetSuperuserSecret     = "FailedGetSuperuserSecret"
+	ReasonFailedGetDbConnectionDetails = "FailedGetDbConnectionDetails"
+
+	ReasonDatabaseReady       = "DatabaseReady"
+	ReasonDatabaseUnreachable = "DatabaseUnreachable"
+	ReasonAuthFailed          = "AuthFailed"
+	ReasonDatabaseMissing     = "DatabaseMissing"
+	ReasonVersionUnsupported  = "VersionUnsupported"
+	ReasonPermissionDenied    = "PermissionDenied"
+	ReasonDatabaseCheckFailed = "DatabaseCheckFailed"
+
+	ReasonWithinConnectionBudget   = "WithinConnectionBudget"
+	ReasonConnectionBudgetExceeded = "ConnectionBudgetExceeded"
+	ReasonConnectionBudgetUnknown  = "ConnectionBudgetUnknown"
+
+	ReasonFailedGetPooler    = "FailedGetPooler"
+	ReasonFailedCreatePooler = "FailedCreatePooler"
+	ReasonFailedUpdatePooler = "FailedUpdatePooler"
+	ReasonFailedDeletePooler = "FailedDeletePooler"
+
+	ReasonInvalidPodTemplatePatch = "InvalidPodTemplatePatch"
+)
+
+// ConditionCertificateReady mirrors the Ready condition of the cert-manager Certificate of spec.exposure.certificate
+const ConditionCertificateReady = "CertificateReady"
+
+// ConditionDatabaseProvisioned reports whether the role, the database and the extensions of spec.database.provisioning exist
+const ConditionDatabaseProvisioned = "DatabaseProvisioned"
+
+// ConditionConnectionBudget reports whether the database server accepts all the connections the pods may open
+const ConditionConnectionBudget = "ConnectionBudget"
+
+// ConditionDatabaseReady reports the result of the pre-flight check of the database run before every init job
+const ConditionDatabaseReady = "DatabaseReady"
+
+// CNPGClusterGroupVersionKind is the CloudNativePG Cluster referenced by spec.database.cnpgClusterRef
+var CNPGClusterGroupVersionKind = schema.GroupVersionKind{Group: "postgresql.cnpg.io", Version: "v1", Kind: "Cluster"}
+
+// CertificateGroupVersionKind is the cert-manager Certificate created for spec.exposure.certificate
+var CertificateGroupVersionKind = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// HTTPRouteGroupVersionKind is the Gateway API HTTPRoute created for spec.exposure.gateway
+var HTTPRouteGroupVersionKind = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+// UpgradePolicy defines how the database is migrated when spec.image changes
+// +kubebuilder:validation:Enum=Auto;Manual;Skip
+type UpgradePolicy string
+
+const (
+	// UpgradePolicyAuto upgrades all modules before the new image is rolled out
+	UpgradePolicyAuto UpgradePolicy = "Auto"
+	// UpgradePolicyManual upgrades all modules once the new image is approved with the
+	// UpgradeApprovalAnnotation, the previous image keeps running until then
+	UpgradePolicyManual UpgradePolicy = "Manual"
+	// UpgradePolicySkip rolls out the new image without upgrading any module
+	UpgradePolicySkip UpgradePolicy = "Skip"
+)
+
+// ModuleRemovalPolicy defines what happens to installed modules removed from spec.modules
+// +kubebuilder:validation:Enum=Uninstall;Ignore
+type ModuleRemovalPolicy string
+
+const (
+	// ModuleRemovalPolicyUninstall uninstalls removed modules from the database
+	ModuleRemovalPolicyUninstall ModuleRemovalPolicy = "Uninstall"
+	// ModuleRemovalPolicyIgnore leaves removed modules installed
+	ModuleRemovalPolicyIgnore ModuleRemovalPolicy = "Ignore"
+)
+
+const (
+	// OdooDeploymentLabel is set on objects created on behalf of an OdooDeployment
+	OdooDeploymentLabel = "odoo.abugharbia.com/odoodeployment"
+	// UpgradeApprovalAnnotation approves the upgrade of all modules to the image it is set to
+	// when the upgrade policy is Manual, or to <image>@<digest> when only the digest of the image changed
+	UpgradeApprovalAnnotation = "odoo.abugharbia.com/approve-upgrade"
+	// ImageDigestAnnotation holds the digest the database was last migrated with on the pods of the
+	// Deployment, it rolls out the pods once a tag pushed again is migrated
+	ImageDigestAnnotation = "odoo.abugharbia.com/image-digest"
+	// ScheduledBackupLabel marks OdooBackups created by the backup schedule, only those are pruned
+	ScheduledBackupLabel = "odoo.abugharbia.com/scheduled-backup"
+	// PreUpgradeBackupLabel marks OdooBackups taken before a job changing the database schema,
+	// they are never pruned
+	PreUpgradeBackupLabel = "odoo.abugharbia.com/pre-upgrade-backup"
+	// AutoTuneAnnotation records the settings of spec.config computed by autoTune as JSON
+	AutoTuneAnnotation = "odoo.abugharbia.com/auto-tune"
 )
 
+// OdooProbesConfig overrides the probes of the Odoo container, a probe that is not set checks the
+// /web/health endpoint of the http port, or only opens a connection to it before Odoo 16
+type OdooProbesConfig struct {
+	// Restarts Odoo once it stops answering
+	// +kubebuilder:validation:Optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+	// Sends traffic to Odoo once it answers
+	// +kubebuilder:validation:Optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+	// Holds the other probes back while Odoo loads its modules, up to 10 minutes by default
+	// +kubebuilder:validation:Optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+}
+
+// OdooPodTemplate is laid over the pods of the Deployment and of the init, upgrade, uninstall,
+// migration, backup and restore jobs. The PgBouncer Deployment of the pooler is not affected.
+type OdooPodTemplate struct {
+	// Labels added to the pods, the selector labels of the operator take precedence
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the pods
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// +kubebuilder:validation:Optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Each of nodeAffinity, podAffinity and podAntiAffinity replaces the one of the operator, the backup
+	// and restore jobs otherwise prefer the node of the Odoo pods for a ReadWriteOnce filestore
+	// +kubebuilder:validation:Optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// +kubebuilder:validation:Optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// +kubebuilder:validation:Optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// +kubebuilder:validation:Optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+}
+
+// PodSpecPatchType is the format of a PodSpecPatch
+// +kubebuilder:validation:Enum=StrategicMerge;JSONPatch
+type PodSpecPatchType string
+
+const (
+	// A strategic merge patch, containers, volumes and env vars are merged by name
+	PodSpecPatchTypeStrategicMerge PodSpecPatchType = "StrategicMerge"
+	// A list of RFC 6902 JSON patch operations
+	PodSpecPatchTypeJSONPatch PodSpecPatchType = "JSONPatch"
+)
+
+// PodSpecPatch is a patch of a pod spec rendered by the operator. The Deployment is compared with the
+// patched spec, a patch adding a container must set the fields the API server defaults, e.g. its
+// terminationMessagePath, otherwise the Deployment is updated on every reconciliation.
+type PodSpecPatch struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=StrategicMerge
+	Type PodSpecPatchType `json:"type,omitempty"`
+	// The patch in YAML or JSON, relative to the pod spec, e.g. /containers/0/env/- for JSON patches
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+}
+
+// OdooPodTemplatePatch holds the patches of the pod specs of the Deployment and of the jobs, they are
+// applied after spec.podTemplate
+type OdooPodTemplatePatch struct {
+	// Patch of the pods of the Deployment
+	// +kubebuilder:validation:Optional
+	Deployment *PodSpecPatch `json:"deployment,omitempty"`
+	// Patch of the pods of the init, upgrade, uninstall, migration, backup and restore jobs
+	// +kubebuilder:validation:Optional
+	Jobs *PodSpecPatch `json:"jobs,omitempty"`
+}
+
+// PreStopDelaySeconds is the time a terminating Odoo pod keeps serving, until it is removed from the
+// endpoints of the services
+const PreStopDelaySeconds = 10
+
+// AutoTunedConfig holds the settings of spec.config computed from the resources of the Odoo container
+type AutoTunedConfig struct {
+	Workers         int32 `json:"workers"`
+	MaxCronThreads  int32 `json:"maxCronThreads"`
+	LimitMemorySoft int64 `json:"limitMemorySoft"`
+	LimitMemoryHard int64 `json:"limitMemoryHard"`
+}
+
 type DatabaseConnectionDetails struct {
 	Host     string
 	Port     int32
 	User     string
 	Password string
 	Name     string
-	SSL      bool
+	SSLMode  SSLMode
 	MaxConn  int32
+
+	ReplicaHost string
+	ReplicaPort int32
+
+	// The secret key the password is read from by the job containers
+	PasswordSecret corev1.SecretKeySelector
 }
 
-// type S3Config struct {
-// 	// The S3 endpoint to use for backups
-// 	Endpoint string `json:"endpoint,omitempty"`
-// 	// The S3 bucket to use for backups
-// 	Bucket string `json:"bucket"`
-// 	// Prefix to use for backups
-// 	Prefix string `json:"prefix,omitempty"`
-// 	// The S3 region to use for backups
-// 	Region string `json:"region"`
-// 	// The S3 access key to use for backups
-// 	AccessKeyFromSecret corev1.SecretKeySelector `json:"accessKeyFromSecret"`
-// 	// The S3 secret key to use for backups
-// 	SecretKeyFromSecret corev1.SecretKeySelector `json:"secretKeyFromSecret"`
-// }
-
-// type OdooBackupConfig struct {
-// 	// Whether or not to enable backups
-// 	// +kubebuilder:default=false
-// 	Enabled bool `json:"enabled"`
-// 	// The number of daily backups to keep at all times
-// 	// +kubebuilder:default=7
-// 	KeepDailyBackups int32 `json:"keepDailyBackups,omitempty"`
-// 	// The number of weekly backups to keep at all times
-// 	// +kubebuilder:default=4
-// 	KeepWeeklyBackups int32 `json:"keepWeeklyBackups,omitempty"`
-// 	// The number of monthly backups to keep at all times
-// 	// +kubebuilder:default=12
-// 	KeepMonthlyBackups int32 `json:"keepMonthlyBackups,omitempty"`
-// 	// The S3 configuration for the OdooDployment
-// 	S3 S3Config `json:"s3,omitempty"`
-// }
+// SSLMode is the libpq sslmode of the database connection
+// +kubebuilder:validation:Enum=disable;allow;prefer;require;verify-ca;verify-full
+type SSLMode string
+
+const (
+	SSLModeDisable    SSLMode = "disable"
+	SSLModeAllow      SSLMode = "allow"
+	SSLModePrefer     SSLMode = "prefer"
+	SSLModeRequire    SSLMode = "require"
+	SSLModeVerifyCA   SSLMode = "verify-ca"
+	SSLModeVerifyFull SSLMode = "verify-full"
+)
+
+// The database settings used when neither the field nor its secret is set. They are not defaulted by
+// the CRD, a defaulted field could not be told apart from one set together with its secret
+const (
+	DefaultDatabaseHost    = "postgresql"
+	DefaultDatabasePort    = 5432
+	DefaultDatabaseUser    = "odoo"
+	DefaultDatabaseName    = "odoo"
+	DefaultDatabaseMaxConn = 20
+)
+
+// PoolerMode is where the PgBouncer pooling the database connections of Odoo runs
+// +kubebuilder:validation:Enum=none;sidecar;deployment
+type PoolerMode string
+
+const (
+	// Odoo connects to the database server directly
+	PoolerModeNone PoolerMode = "none"
+	// PgBouncer runs next to Odoo in every pod, including the pods of the database jobs
+	PoolerModeSidecar PoolerMode = "sidecar"
+	// PgBouncer runs in a Deployment of its own behind the <name>-pooler service
+	PoolerModeDeployment PoolerMode = "deployment"
+)
+
+// PoolMode is the pool_mode of PgBouncer, when a server connection is given back to the pool
+// +kubebuilder:validation:Enum=session;transaction;statement
+type PoolMode string
+
+const (
+	PoolModeSession     PoolMode = "session"
+	PoolModeTransaction PoolMode = "transaction"
+	PoolModeStatement   PoolMode = "statement"
+)
+
+// PoolerPort is the port PgBouncer listens on
+const PoolerPort = 6432
+
+// PoolerConfigMountPath is the directory pgbouncer.ini and userlist.txt are mounted in
+const PoolerConfigMountPath = "/etc/pgbouncer"
+
+// DatabaseSSLMountPath is the directory the SSL certificates of the database connection are mounted in
+const DatabaseSSLMountPath = "/etc/odoo-db-ssl"
+
+// S3Config defines an S3 compatible object store backups are uploaded to
+type S3Config struct {
+	// The S3 endpoint to use for backups, e.g. https://minio.minio.svc:9000.
+	// Defaults to AWS S3 when empty, plain http is used only when the scheme is http
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// The S3 bucket to use for backups
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+	// Prefix to use for backups
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+	// The S3 region to use for backups
+	// +kubebuilder:default="us-east-1"
+	Region string `json:"region,omitempty"`
+	// The S3 access key to use for backups
+	AccessKeyFromSecret corev1.SecretKeySelector `json:"accessKeyFromSecret"`
+	// The S3 secret key to use for backups
+	SecretKeyFromSecret corev1.SecretKeySelector `json:"secretKeyFromSecret"`
+}
+
+// OdooBackupConfig defines the scheduled and pre-upgrade backups of an OdooDeployment
+type OdooBackupConfig struct {
+	// Whether or not to enable backups
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled"`
+	// Whether to take a backup before every job changing the database schema, the job waits
+	// for the backup to succeed. Independent of enabled, which only controls the schedule
+	// +kubebuilder:validation:Optional
+	PreUpgrade bool `json:"preUpgrade,omitempty"`
+	// The cron schedule on which backups are taken, in UTC
+	// +kubebuilder:default="0 2 * * *"
+	Schedule string `json:"schedule,omitempty"`
+	// The number of daily backups to keep at all times
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Minimum=0
+	KeepDailyBackups int32 `json:"keepDailyBackups,omitempty"`
+	// The number of weekly backups to keep at all times
+	// +kubebuilder:default=4
+	// +kubebuilder:validation:Minimum=0
+	KeepWeeklyBackups int32 `json:"keepWeeklyBackups,omitempty"`
+	// The number of monthly backups to keep at all times
+	// +kubebuilder:default=12
+	// +kubebuilder:validation:Minimum=0
+	KeepMonthlyBackups int32 `json:"keepMonthlyBackups,omitempty"`
+	// The image used to run the backup jobs
+	// +kubebuilder:default="postgres:17"
+	Image string `json:"image,omitempty"`
+	// The persistent volume claim spec used for the artifacts of every backup
+	// +kubebuilder:validation:Optional
+	Storage PersistentVolumeClaimSpec `json:"storage,omitempty"`
+	// The S3 configuration for the OdooDployment, backups are uploaded to S3 instead of
+	// being kept in a PVC when set
+	// +kubebuilder:validation:Optional
+	S3 *S3Config `json:"s3,omitempty"`
+}
+
+// CNPGClusterReference references a CloudNativePG Cluster in the namespace of the OdooDeployment
+type CNPGClusterReference struct {
+	// The name of the Cluster
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Whether to send the read-only queries of Odoo 18 to the replicas through the -ro service
+	// +kubebuilder:validation:Optional
+	UseReplica bool `json:"useReplica,omitempty"`
+}
 
 // OdooDatabaseConfig defines the database connection configuration for Odoo
 type OdooDatabaseConfig struct {
-	// The database host to use for Odoo
-	// +kubebuilder:default="postgresql"
+	// The CloudNativePG Cluster to connect to. The host, port, user, password and name of the
+	// database are taken from its -rw service and its app secret instead of the fields below
+	// +kubebuilder:validation:Optional
+	CNPGClusterRef *CNPGClusterReference `json:"cnpgClusterRef,omitempty"`
+
+	// The database host to use for Odoo, postgresql when neither host nor hostFromSecret is set
+	// +kubebuilder:validation:Optional
 	Host string `json:"host,omitempty"`
 	// The database host to use for Odoo from a secret
 	HostFromSecret corev1.SecretKeySelector `json:"hostFromSecret,omitempty"`
 
-	// The database port to use for Odoo
-	// +kubebuilder:default=5432
+	// The database port to use for Odoo, 5432 when neither port nor portFromSecret is set
+	// +kubebuilder:validation:Optional
 	Port int32 `json:"port,omitempty"`
 	// The database port to use for Odoo from a secret
 	PortFromSecret corev1.SecretKeySelector `json:"portFromSecret,omitempty"`
 
-	// The database user to use for Odoo
-	// +kubebuilder:default="odoo"
+	// The database user to use for Odoo, odoo when neither user nor userFromSecret is set
+	// +kubebuilder:validation:Optional
 	User string `json:"user,omitempty"`
 	// The database user to use for Odoo from a secret
 	UserFromSecret corev1.SecretKeySelector `json:"userFromSecret,omitempty"`
 
-	// The database password to use for Odoo
-	PasswordFromSecret corev1.SecretKeySelector `json:"passwordFromSecret"`
+	// The database password to use for Odoo, required unless cnpgClusterRef is set
+	// +kubebuilder:validation:Optional
+	PasswordFromSecret corev1.SecretKeySelector `json:"passwordFromSecret,omitempty"`
 
-	// The database name to use for Odoo
-	// +kubebuilder:default="odoo"
+	// The database name to use for Odoo, odoo when neither name nor nameFromSecret is set
+	// +kubebuilder:validation:Optional
 	Name string `json:"name,omitempty"`
 	// The database name to use for Odoo from a secret
 	NameFromSecret corev1.SecretKeySelector `json:"nameFromSecret,omitempty"`
 
-	// Whether or not to enable SSL for the database connection
-	// +kubebuilder:default=false
-	SSL bool `json:"ssl,omitempty"`
-	// Whether or not to enable SSL for the database connection from a secret
-	SSLFromSecret corev1.SecretKeySelector `json:"sslFromSecret,omitempty"`
+	// The SSL mode of the database connection, verify-ca and verify-full need sslRootCertFromSecret.
+	// prefer when neither sslMode nor sslModeFromSecret is set
+	// +kubebuilder:validation:Optional
+	SSLMode SSLMode `json:"sslMode,omitempty"`
+	// The SSL mode of the database connection from a secret
+	SSLModeFromSecret corev1.SecretKeySelector `json:"sslModeFromSecret,omitempty"`
 
-	// The database max connections to use for Odoo
-	// +kubebuilder:default=20
+	// The CA certificate the server certificate is verified with, from a secret
+	SSLRootCertFromSecret corev1.SecretKeySelector `json:"sslRootCertFromSecret,omitempty"`
+	// The client certificate presented to the server, from a secret
+	SSLCertFromSecret corev1.SecretKeySelector `json:"sslCertFromSecret,omitempty"`
+	// The private key of the client certificate, from a secret
+	SSLKeyFromSecret corev1.SecretKeySelector `json:"sslKeyFromSecret,omitempty"`
+
+	// The database max connections to use for Odoo, 20 when neither maxConn nor maxConnFromSecret is set
+	// +kubebuilder:validation:Optional
 	MaxConn int32 `json:"maxConn,omitempty"`
 	// The database max connections to use for Odoo from a secret
 	MaxConnFromSecret corev1.SecretKeySelector `json:"maxConnFromSecret,omitempty"`
+
+	// The connections the database server accepts from Odoo, checked against the connection budget
+	// of the OdooDeployment. Queried from the server as max_connections less the reserved
+	// connections when unset
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+
+	// The host of a read replica of the database, Odoo 18 sends read-only queries to it.
+	// No replica is used when empty
+	// +kubebuilder:validation:Optional
+	ReplicaHost string `json:"replicaHost,omitempty"`
+	// The host of a read replica of the database from a secret
+	ReplicaHostFromSecret corev1.SecretKeySelector `json:"replicaHostFromSecret,omitempty"`
+
+	// The port of the read replica, the port of the database is used when unset
+	// +kubebuilder:validation:Optional
+	ReplicaPort int32 `json:"replicaPort,omitempty"`
+	// The port of the read replica from a secret
+	ReplicaPortFromSecret corev1.SecretKeySelector `json:"replicaPortFromSecret,omitempty"`
+
+	// Create the role and the database Odoo connects with before the database is initialized
+	// +kubebuilder:validation:Optional
+	Provisioning *OdooDatabaseProvisioning `json:"provisioning,omitempty"`
+
+	// Pool the connections of Odoo to the database server with PgBouncer
+	// +kubebuilder:validation:Optional
+	Pooler *OdooDatabasePooler `json:"pooler,omitempty"`
+}
+
+// OdooDatabasePooler configures the PgBouncer Odoo connects to instead of the database server. PgBouncer
+// connects to the database server with the SSL settings of the database, Odoo to PgBouncer in plain text.
+// The read replica is not used behind the pooler.
+type OdooDatabasePooler struct {
+	// Where PgBouncer runs
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=none
+	Mode PoolerMode `json:"mode,omitempty"`
+	// When a server connection is given back to the pool. The bus of Odoo listens for notifications
+	// on a connection of its own, which transaction and statement pooling break
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=session
+	PoolMode PoolMode `json:"poolMode,omitempty"`
+	// The PgBouncer image
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="ghcr.io/cloudnative-pg/pgbouncer:1.24.1"
+	Image string `json:"image,omitempty"`
+	// The server connections PgBouncer opens per database and user
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=20
+	DefaultPoolSize int32 `json:"defaultPoolSize,omitempty"`
+	// The client connections PgBouncer accepts
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1000
+	MaxClientConn int32 `json:"maxClientConn,omitempty"`
+	// The replicas of the PgBouncer Deployment in deployment mode
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// OdooDatabaseProvisioning configures the creation of the role, the database and the extensions
+// Odoo uses by the operator, connecting as a Postgres superuser
+type OdooDatabaseProvisioning struct {
+	// The secret with the username and password keys of a Postgres superuser, like the
+	// <cluster>-superuser secret of a CloudNativePG Cluster with enableSuperuserAccess
+	// +kubebuilder:validation:MinLength=1
+	SuperuserSecretName string `json:"superuserSecretName"`
+	// The database the superuser connects to for creating the role and the database
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=postgres
+	MaintenanceDatabase string `json:"maintenanceDatabase,omitempty"`
+	// The LC_COLLATE of the database, it can not be changed once the database exists
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=C
+	Collation string `json:"collation,omitempty"`
+	// The extensions created in the database
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default={unaccent,pg_trgm}
+	// +listType=set
+	Extensions []string `json:"extensions,omitempty"`
 }
 
 type OdooConfig struct {
@@ -188,6 +614,19 @@ type OdooConfig struct {
 	// +kubebuilder:default=1
 	MaxCronThreads int32 `json:"maxCronThreads,omitempty"`
 
+	// The port of the poll server serving the websocket, or longpolling before Odoo 16, written as
+	// gevent_port or longpolling_port depending on the Odoo version
+	// +kubebuilder:default=8072
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	PollPort int32 `json:"pollPort,omitempty"`
+
+	// Compute workers, maxCronThreads, limitMemorySoft and limitMemoryHard from the CPU and memory of
+	// spec.resources, replacing the values set. The computed values are recorded in the
+	// odoo.abugharbia.com/auto-tune annotation
+	// +kubebuilder:validation:Optional
+	AutoTune bool `json:"autoTune,omitempty"`
+
 	// Extra addons paths for Odoo. Each entry must be an absolute path with no commas, spaces, newlines, or # characters.
 	// +kubebuilder:validation:Optional
 	// +listType=set
@@ -231,7 +670,12 @@ type OdooDeploymentSpec struct {
 	// The image to run for the OdooDployment
 	// +kubebuilder:default="odoo:18"
 	Image string `json:"image,omitempty"`
-	// The backup configuration for the OdooDployment
+
+	// The major Odoo version of the image, e.g. 17. It decides between the longpolling and the
+	// websocket poll server, detected from the image tag when unset
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=8
+	OdooVersion int32 `json:"odooVersion,omitempty"`
 
 	// Image pull policy for the OdooDployment
 	// +kubebuilder:validation:Optional
@@ -243,7 +687,9 @@ type OdooDeploymentSpec struct {
 	// +kubebuilder:validation:Optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
-	// Backup OdooBackupConfig `json:"backup,omitempty"`
+	// The backup configuration for the OdooDployment
+	// +kubebuilder:validation:Optional
+	Backup OdooBackupConfig `json:"backup,omitempty"`
 	// The database configuration for the OdooDployment
 	Database OdooDatabaseConfig `json:"database"`
 	// The configuration for the Odoo
@@ -254,9 +700,229 @@ type OdooDeploymentSpec struct {
 	// +kubebuilder:default={"base"}
 	Modules []string `json:"modules,omitempty"`
 
+	// What happens to installed modules removed from modules, Uninstall uninstalls them
+	// together with the modules depending on them
+	// +kubebuilder:default="Ignore"
+	ModuleRemovalPolicy ModuleRemovalPolicy `json:"moduleRemovalPolicy,omitempty"`
+
+	// The module upgrades run against the database before new pods are rolled out
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default={}
+	Upgrade OdooUpgradeConfig `json:"upgrade,omitempty"`
+
 	// PersistentVolumeClaim defines the replicated volume specs
 	// +kubebuilder:validation:Optional
 	OdooFilestore PersistentVolumeClaimSpec `json:"odooFilestore,omitempty"`
+
+	// The compute resources of the Odoo container
+	// +kubebuilder:validation:Optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Overrides of the probes of the Odoo container of the Deployment
+	// +kubebuilder:validation:Optional
+	Probes *OdooProbesConfig `json:"probes,omitempty"`
+
+	// Scheduling and metadata of the pods of the Deployment and of all the jobs of the operator
+	// +kubebuilder:validation:Optional
+	PodTemplate *OdooPodTemplate `json:"podTemplate,omitempty"`
+
+	// Patches of the rendered pod specs, for what the typed fields do not cover
+	// +kubebuilder:validation:Optional
+	PodTemplatePatch *OdooPodTemplatePatch `json:"podTemplatePatch,omitempty"`
+
+	// The Ingress routing external traffic to the http and poll services, no Ingress is created when unset
+	// +kubebuilder:validation:Optional
+	Ingress *OdooIngressConfig `json:"ingress,omitempty"`
+
+	// Exposure of Odoo through other APIs than Ingress
+	// +kubebuilder:validation:Optional
+	Exposure *OdooExposureConfig `json:"exposure,omitempty"`
+}
+
+// OdooExposureConfig defines how Odoo is exposed outside of the cluster
+type OdooExposureConfig struct {
+	// The Gateway API HTTPRoute routing external traffic to the http and poll services,
+	// no HTTPRoute is created when unset
+	// +kubebuilder:validation:Optional
+	Gateway *OdooGatewayConfig `json:"gateway,omitempty"`
+
+	// The cert-manager Certificate issued for the hosts of the Ingress and the hostnames of the
+	// HTTPRoute, no Certificate is created when unset
+	// +kubebuilder:validation:Optional
+	Certificate *OdooCertificateConfig `json:"certificate,omitempty"`
+}
+
+// OdooCertificateConfig defines the cert-manager Certificate of an OdooDeployment. The Ingress
+// terminates TLS with it unless spec.ingress.tlsSecretName is set, Gateways have to reference
+// the secret in their listeners
+type OdooCertificateConfig struct {
+	// The cert-manager issuer of the Certificate
+	IssuerRef CertificateIssuerReference `json:"issuerRef"`
+	// The name of the secret the certificate is stored in, defaults to <name>-tls
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// CertificateIssuerReference references a cert-manager Issuer or ClusterIssuer
+type CertificateIssuerReference struct {
+	// The name of the issuer
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The kind of the issuer
+	// +kubebuilder:default="Issuer"
+	Kind string `json:"kind,omitempty"`
+	// The API group of the issuer
+	// +kubebuilder:default="cert-manager.io"
+	Group string `json:"group,omitempty"`
+}
+
+// OdooGatewayConfig defines the HTTPRoute of an OdooDeployment. The websocket path, or the
+// longpolling path before Odoo 16, is routed to the poll service, everything else to the http service
+type OdooGatewayConfig struct {
+	// The Gateways, or listeners of them, the HTTPRoute attaches to
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []GatewayParentReference `json:"parentRefs"`
+	// The hostnames the HTTPRoute matches, the hostnames of the listeners are used when empty
+	// +kubebuilder:validation:Optional
+	// +listType=set
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+// GatewayParentReference references a Gateway, or a listener of it, an HTTPRoute attaches to
+type GatewayParentReference struct {
+	// The name of the Gateway
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The namespace of the Gateway, defaults to the namespace of the OdooDeployment
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the listener of the Gateway to attach to, all listeners when empty
+	// +kubebuilder:validation:Optional
+	SectionName string `json:"sectionName,omitempty"`
+	// The port of the listeners of the Gateway to attach to, all ports when unset
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
+// HTTPRouteParentStatus is the Accepted and ResolvedRefs conditions reported by the
+// controller of a Gateway the HTTPRoute attaches to
+type HTTPRouteParentStatus struct {
+	// The name of the Gateway
+	Name string `json:"name"`
+	// The namespace of the Gateway
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the listener of the Gateway
+	// +kubebuilder:validation:Optional
+	SectionName string `json:"sectionName,omitempty"`
+	// The Accepted and ResolvedRefs conditions of the HTTPRoute for the Gateway
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ConnectionBudgetStatus compares the connections the pods of an OdooDeployment may open with the
+// connections the database server accepts
+type ConnectionBudgetStatus struct {
+	// The connections all pods may open, maxConn × (workers + maxCronThreads + 1) × replicas
+	Required int32 `json:"required"`
+	// The connections the database server accepts, unset when they could not be queried
+	// +kubebuilder:validation:Optional
+	Available int32 `json:"available,omitempty"`
+	// The generation of the OdooDeployment the available connections were queried for
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// HTTPRouteStatus is the observed state of the HTTPRoute of an OdooDeployment
+type HTTPRouteStatus struct {
+	// The name of the HTTPRoute
+	Name string `json:"name"`
+	// The status of the HTTPRoute for every Gateway it attaches to
+	// +kubebuilder:validation:Optional
+	Parents []HTTPRouteParentStatus `json:"parents,omitempty"`
+}
+
+// OdooIngressConfig defines the Ingress of an OdooDeployment. The websocket path, or the
+// longpolling path before Odoo 16, is routed to the poll service, everything else to the http service
+type OdooIngressConfig struct {
+	// The hosts the Ingress routes to Odoo
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Hosts []string `json:"hosts"`
+	// The name of the secret holding the TLS certificate of the hosts, TLS is not terminated when empty
+	// +kubebuilder:validation:Optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// The IngressClass of the Ingress, the default IngressClass of the cluster is used when unset
+	// +kubebuilder:validation:Optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations set on the Ingress, e.g. to configure the ingress controller
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// OdooUpgradeConfig defines the module upgrades run with `-u` when the image, the token or the modules change
+type OdooUpgradeConfig struct {
+	// How the database is migrated when the image changes, Auto and Manual upgrade all modules
+	// +kubebuilder:default="Auto"
+	Policy UpgradePolicy `json:"policy,omitempty"`
+	// A list of modules to upgrade when the token changes or modules are added to it,
+	// "all" upgrades every installed module
+	// +kubebuilder:validation:Optional
+	Modules []string `json:"modules,omitempty"`
+	// An arbitrary value, changing it upgrades the modules again without changing the image
+	// +kubebuilder:validation:Optional
+	Token string `json:"token,omitempty"`
+}
+
+// OdooUpgrade is the image, modules and token of a module upgrade
+type OdooUpgrade struct {
+	// The image the modules were upgraded with
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// The digest of the image the modules were upgraded with, as reported by the kubelet
+	// +kubebuilder:validation:Optional
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// The list of modules that were upgraded
+	// +kubebuilder:validation:Optional
+	Modules []string `json:"modules,omitempty"`
+	// The token of spec.upgrade the modules were upgraded for
+	// +kubebuilder:validation:Optional
+	Token string `json:"token,omitempty"`
+	// The time the upgrade finished
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// ImageDigestCheckInterval is how often the digest of spec.image is resolved again
+const ImageDigestCheckInterval = 10 * time.Minute
+
+// ResolvedImage is the digest an image pulled by the image digest pod resolved to
+type ResolvedImage struct {
+	// The image that was pulled
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// The digest of the pulled image, as reported by the kubelet
+	// +kubebuilder:validation:Optional
+	Digest string `json:"digest,omitempty"`
+	// The time the image was pulled
+	// +kubebuilder:validation:Optional
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+}
+
+type DBUpgradeJob struct {
+	// The name of the UpgradeJob
+	Name string `json:"name"`
+	// The namespace of the UpgradeJob
+	Namespace string `json:"jobNamespace"`
+
+	// The upgrade run by the job
+	Upgrade OdooUpgrade `json:"upgrade,omitempty"`
+
+	// The OdooBackup taken before the job ran, restore it to roll back a failed upgrade
+	// +kubebuilder:validation:Optional
+	Backup string `json:"backup,omitempty"`
 }
 
 type DBInitjob struct {
@@ -267,6 +933,10 @@ type DBInitjob struct {
 
 	// The list of modules that are being installed
 	Modules []string `json:"modules,omitempty"`
+
+	// The OdooBackup taken before the job ran, restore it to roll back a failed job
+	// +kubebuilder:validation:Optional
+	Backup string `json:"backup,omitempty"`
 }
 
 // OdooDeploymentStatus defines the observed state of OdooDeployment
@@ -292,10 +962,57 @@ type OdooDeploymentStatus struct { // INSERT ADDITIONAL SPEC FIELDS - desired st
 	// +kubebuilder:validation:Optional
 	CurrentInitJob DBInitjob `json:"currentInitJob,omitempty"`
 
+	// The current running UninstallJob
+	// +kubebuilder:validation:Optional
+	CurrentUninstallJob DBInitjob `json:"currentUninstallJob,omitempty"`
+
+	// The current running UpgradeJob, the Deployment is not updated while it is set
+	// +kubebuilder:validation:Optional
+	CurrentUpgradeJob DBUpgradeJob `json:"currentUpgradeJob,omitempty"`
+
+	// The image and modules the database was last initialised or upgraded with
+	// +kubebuilder:validation:Optional
+	LastUpgrade OdooUpgrade `json:"lastUpgrade,omitempty"`
+
+	// The digest spec.image currently resolves to, only resolved when imagePullPolicy is Always
+	// +kubebuilder:validation:Optional
+	ResolvedImage ResolvedImage `json:"resolvedImage,omitempty"`
+
 	// The secret name for the Odoo admin password
 	// +kubebuilder:validation:Optional
 	OdooAdminSecretName string `json:"odooAdminSecretName,omitempty"`
 
+	// The name of the OdooRestore currently replacing the database and filestore,
+	// the Deployment is scaled to zero and no init job runs while it is set
+	// +kubebuilder:validation:Optional
+	CurrentRestore string `json:"currentRestore,omitempty"`
+
+	// The name of the OdooMigration currently migrating the database and filestore,
+	// the Deployment is scaled to zero and no init or upgrade job runs while it is set
+	// +kubebuilder:validation:Optional
+	CurrentMigration string `json:"currentMigration,omitempty"`
+
+	// The time the last scheduled backup was due
+	// +kubebuilder:validation:Optional
+	LastScheduledBackupTime *metav1.Time `json:"lastScheduledBackupTime,omitempty"`
+
+	// The name of the last OdooBackup created by the backup schedule
+	// +kubebuilder:validation:Optional
+	LastScheduledBackupName string `json:"lastScheduledBackupName,omitempty"`
+
+	// The OdooBackup taken for the next job changing the database schema, it is moved
+	// to the status entry of that job once the job is created
+	// +kubebuilder:validation:Optional
+	PreUpgradeBackup string `json:"preUpgradeBackup,omitempty"`
+
+	// The HTTPRoute of spec.exposure.gateway
+	// +kubebuilder:validation:Optional
+	HTTPRoute *HTTPRouteStatus `json:"httpRoute,omitempty"`
+
+	// The connections the pods may open and the connections the database server accepts
+	// +kubebuilder:validation:Optional
+	ConnectionBudget *ConnectionBudgetStatus `json:"connectionBudget,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	Conditions []metav1.Condition `json:"conditions"`
 }
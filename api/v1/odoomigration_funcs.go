@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"fmt"
+	"slices"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetMigrationJobName returns the name of the job running the step at index
+func (m *OdooMigration) GetMigrationJobName(index int) string {
+	return fmt.Sprintf("%s-step-%d", m.Name, index+1)
+}
+
+// GetMigrationJobTemplate returns the job running the step at index against the database and the
+// filestore of the given OdooDeployment. The job is not retried, a failed step may have left the
+// database half migrated.
+func (m *OdooMigration) GetMigrationJobTemplate(odooDeployment *OdooDeployment, index int) batchv1.Job {
+	step := m.Spec.Steps[index]
+
+	command := slices.Clone(step.Command)
+	if len(command) == 0 {
+		command = slices.Clone(odooDeployment.Spec.OdooCommand)
+	}
+	args := slices.Clone(step.Args)
+	if len(args) == 0 {
+		args = []string{"-c", "/opt/odoo/odoo.conf", "--stop-after-init", "--no-http", "--update", "all"}
+	}
+
+	spec := odooDeployment.GetPodSpec()
+	spec.Containers[0].Image = step.Image
+	spec.Containers[0].ImagePullPolicy = step.ImagePullPolicy
+	spec.Containers[0].Command = command
+	spec.Containers[0].Args = args
+	spec.Containers[0].Env = append(spec.Containers[0].Env, step.Env...)
+	spec.Containers[0].Ports = []corev1.ContainerPort{}
+	spec.RestartPolicy = corev1.RestartPolicyNever
+
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.GetMigrationJobName(index),
+			Namespace: m.Namespace,
+			Labels: map[string]string{
+				OdooDeploymentLabel: odooDeployment.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: odooDeployment.getPodTemplateMetadata(nil),
+				Spec:       spec,
+			},
+			Parallelism:  func(i int32) *int32 { return &i }(1),
+			BackoffLimit: func(i int32) *int32 { return &i }(0),
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func minimalOdooMigration(steps ...MigrationStep) *OdooMigration {
+	return &OdooMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-migration",
+			Namespace: "default",
+		},
+		Spec: OdooMigrationSpec{
+			OdooDeploymentRef: corev1.LocalObjectReference{Name: "test-odoo"},
+			Steps:             steps,
+			TargetImage:       "odoo:18.0",
+		},
+	}
+}
+
+func TestGetMigrationJobTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		step        MigrationStep
+		wantCommand string
+		wantArgs    string
+	}{
+		{
+			name:        "defaults",
+			step:        MigrationStep{Image: "openupgrade:17.0"},
+			wantCommand: "odoo",
+			wantArgs:    "-c /opt/odoo/odoo.conf --stop-after-init --no-http --update all",
+		},
+		{
+			name: "custom command and arguments",
+			step: MigrationStep{
+				Image:   "openupgrade:17.0",
+				Command: []string{"/usr/bin/env", "odoo"},
+				Args:    []string{"-c", "/opt/odoo/odoo.conf", "--stop-after-init", "-u", "all", "--load", "base,web,openupgrade_framework"},
+			},
+			wantCommand: "/usr/bin/env odoo",
+			wantArgs:    "-c /opt/odoo/odoo.conf --stop-after-init -u all --load base,web,openupgrade_framework",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+			m := minimalOdooMigration(MigrationStep{Image: "openupgrade:16.0"}, tc.step)
+
+			job := m.GetMigrationJobTemplate(o, 1)
+
+			if job.Name != "test-migration-step-2" {
+				t.Errorf("job name = %q, want %q", job.Name, "test-migration-step-2")
+			}
+			if job.Spec.BackoffLimit == nil || *job.Spec.BackoffLimit != 0 {
+				t.Errorf("backoff limit = %v, want 0", job.Spec.BackoffLimit)
+			}
+			container := job.Spec.Template.Spec.Containers[0]
+			if container.Image != tc.step.Image {
+				t.Errorf("image = %q, want %q", container.Image, tc.step.Image)
+			}
+			if got := strings.Join(container.Command, " "); got != tc.wantCommand {
+				t.Errorf("command = %q, want %q", got, tc.wantCommand)
+			}
+			if got := strings.Join(container.Args, " "); got != tc.wantArgs {
+				t.Errorf("args = %q, want %q", got, tc.wantArgs)
+			}
+			if len(container.Ports) != 0 {
+				t.Errorf("ports = %v, want none", container.Ports)
+			}
+			if strings.Join(o.Spec.OdooCommand, " ") != "odoo" {
+				t.Errorf("OdooCommand of the OdooDeployment was modified: %v", o.Spec.OdooCommand)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentReplicasDuringMigration(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+	o.Spec.Replicas = 2
+	o.Status.CurrentMigration = "test-migration"
+
+	if got := *o.GetDeploymentReplicas(); got != 0 {
+		t.Errorf("GetDeploymentReplicas() = %d, want 0 while migrating", got)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonOdooDeploymentLocked       = "OdooDeploymentLocked"
+	ReasonMigrationBackupFailed      = "MigrationBackupFailed"
+	ReasonWaitingForMigrationBackup  = "WaitingForMigrationBackup"
+	ReasonMigrationJobCreationFailed = "MigrationJobCreationFailed"
+	ReasonMigrationJobCreated        = "MigrationJobCreated"
+	ReasonMigrationJobFailed         = "MigrationJobFailed"
+	ReasonMigrationStepSucceeded     = "MigrationStepSucceeded"
+	ReasonFailedSwitchImage          = "FailedSwitchImage"
+	ReasonMigrationSucceeded         = "MigrationSucceeded"
+)
+
+// MigrationPhase is the lifecycle phase of a migration
+// +kubebuilder:validation:Enum=Pending;ScalingDown;BackingUp;Running;Succeeded;Failed
+type MigrationPhase string
+
+const (
+	MigrationPhasePending     MigrationPhase = "Pending"
+	MigrationPhaseScalingDown MigrationPhase = "ScalingDown"
+	MigrationPhaseBackingUp   MigrationPhase = "BackingUp"
+	MigrationPhaseRunning     MigrationPhase = "Running"
+	MigrationPhaseSucceeded   MigrationPhase = "Succeeded"
+	MigrationPhaseFailed      MigrationPhase = "Failed"
+)
+
+// MigrationStep is a single hop of a migration, migrating the database and the filestore
+// from one major Odoo version to the next
+type MigrationStep struct {
+	// A name for the step, e.g. the Odoo version it migrates to
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// The migration image of the step, e.g. an OpenUpgrade image of the Odoo version it migrates to
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Image pull policy for the migration job
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="IfNotPresent"
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// The command of the migration container, defaults to the odooCommand of the OdooDeployment
+	// +kubebuilder:validation:Optional
+	Command []string `json:"command,omitempty"`
+
+	// The arguments of the migration container,
+	// defaults to "-c /opt/odoo/odoo.conf --stop-after-init --no-http --update all"
+	// +kubebuilder:validation:Optional
+	Args []string `json:"args,omitempty"`
+
+	// Additional environment variables of the migration container
+	// +kubebuilder:validation:Optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// OdooMigrationSpec defines the desired state of OdooMigration
+type OdooMigrationSpec struct {
+	// The OdooDeployment to migrate, in the same namespace as the OdooMigration.
+	// Its Deployment is scaled to zero while the migration runs
+	OdooDeploymentRef corev1.LocalObjectReference `json:"odooDeploymentRef"`
+
+	// The steps of the migration, run in order, one job each
+	// +kubebuilder:validation:MinItems=1
+	Steps []MigrationStep `json:"steps"`
+
+	// The Odoo image the OdooDeployment is switched to once every step succeeded
+	// +kubebuilder:validation:MinLength=1
+	TargetImage string `json:"targetImage"`
+}
+
+// MigrationStepStatus is the observed state of a step of a migration
+type MigrationStepStatus struct {
+	// The name of the step
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// The migration image of the step
+	Image string `json:"image"`
+
+	// The name of the job running the step
+	JobName string `json:"jobName"`
+
+	// The time the job of the step was created
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// The time the job of the step succeeded
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// OdooMigrationStatus defines the observed state of OdooMigration
+type OdooMigrationStatus struct {
+	// The current phase of the migration
+	// +kubebuilder:validation:Optional
+	Phase MigrationPhase `json:"phase,omitempty"`
+
+	// The number of steps that succeeded, a resumed migration continues with the next one
+	// +kubebuilder:validation:Optional
+	CompletedSteps int32 `json:"completedSteps,omitempty"`
+
+	// The steps that were started, in order
+	// +kubebuilder:validation:Optional
+	Steps []MigrationStepStatus `json:"steps,omitempty"`
+
+	// The OdooBackup taken before the first step when spec.backup.preUpgrade of the
+	// OdooDeployment is set, restore it to roll back a failed migration
+	// +kubebuilder:validation:Optional
+	Backup string `json:"backup,omitempty"`
+
+	// The image the OdooDeployment ran before the migration
+	// +kubebuilder:validation:Optional
+	SourceImage string `json:"sourceImage,omitempty"`
+
+	// The time the migration started
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// The time the migration finished
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="OdooDeployment",type=string,JSONPath=`.spec.odooDeploymentRef.name`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetImage`
+// +kubebuilder:printcolumn:name="Completed",type=integer,JSONPath=`.status.completedSteps`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OdooMigration is the Schema for the odoomigrations API
+type OdooMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OdooMigrationSpec   `json:"spec,omitempty"`
+	Status OdooMigrationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OdooMigrationList contains a list of OdooMigration
+type OdooMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OdooMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OdooMigration{}, &OdooMigrationList{})
+}
@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// restoreScript replaces the database and the filestore of the OdooDeployment with the content
+// of the backup in /backup. The database is dropped while connected to the postgres maintenance
+// database, which requires PostgreSQL 13 or newer and a user allowed to create databases.
+const restoreScript = `set -eu
+cd /backup
+verify() {
+  if [ -n "$2" ]; then
+    echo "$2  $1" | sha256sum -c -
+  fi
+}
+verify "${DB_DUMP_FILE}" "${DB_DUMP_SHA256:-}"
+verify "${FILESTORE_ARCHIVE_FILE}" "${FILESTORE_ARCHIVE_SHA256:-}"
+psql --dbname=postgres -v ON_ERROR_STOP=1 \
+  -c "DROP DATABASE IF EXISTS \"${PGDATABASE}\" WITH (FORCE)" \
+  -c "CREATE DATABASE \"${PGDATABASE}\""
+pg_restore --no-owner --no-acl --exit-on-error --dbname="${PGDATABASE}" "${DB_DUMP_FILE}"
+rm -rf "/filestore/${PGDATABASE}"
+mkdir -p "/filestore/${PGDATABASE}"
+tar --zstd -xf "${FILESTORE_ARCHIVE_FILE}" -C "/filestore/${PGDATABASE}"
+`
+
+// RestoreSourceLocation is where the artifacts of the backup to restore are read from,
+// either a backup PVC or a key prefix in an object store
+type RestoreSourceLocation struct {
+	PvcName   string
+	S3        *S3Config
+	KeyPrefix string
+	// The expected checksums of the artifacts, they are not verified when empty
+	DatabaseDumpSHA256     string
+	FilestoreArchiveSHA256 string
+	// The Odoo image and the modules installed when the backup was taken
+	OdooImage string
+	Modules   []string
+}
+
+func (r *OdooRestore) GetRestoreJobName() string {
+	return fmt.Sprintf("%s-restore", r.Name)
+}
+
+// GetRestoreJobTemplate returns the job restoring the backup at source into the database and
+// the filestore of the given OdooDeployment, on filestoreNode when it is set. Backups in an object store are downloaded into a
+// scratch volume by an init container running uploaderImage first.
+func (r *OdooRestore) GetRestoreJobTemplate(
+	odooDeployment *OdooDeployment,
+	dbConnectionDetails DatabaseConnectionDetails,
+	source RestoreSourceLocation,
+	filestoreNode string,
+	uploaderImage string,
+) batchv1.Job {
+	env := odooDeployment.Spec.Database.GetDbEnvVars(dbConnectionDetails)
+	env = append(env,
+		corev1.EnvVar{Name: "DB_DUMP_FILE", Value: BackupDatabaseDumpFileName},
+		corev1.EnvVar{Name: "FILESTORE_ARCHIVE_FILE", Value: BackupFilestoreArchiveFileName},
+		corev1.EnvVar{Name: "DB_DUMP_SHA256", Value: source.DatabaseDumpSHA256},
+		corev1.EnvVar{Name: "FILESTORE_ARCHIVE_SHA256", Value: source.FilestoreArchiveSHA256},
+	)
+
+	spec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:            "restore",
+				Image:           r.Spec.Image,
+				ImagePullPolicy: r.Spec.ImagePullPolicy,
+				Command:         []string{"/bin/sh", "-c", restoreScript},
+				Env:             env,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      "odoo-data",
+						MountPath: "/filestore",
+						SubPath:   "filestore",
+					},
+					{
+						Name:      "backup",
+						MountPath: "/backup",
+						ReadOnly:  true,
+					},
+				},
+			},
+		},
+		Volumes: []corev1.Volume{
+			{
+				Name: "odoo-data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: odooDeployment.Status.OdooDataPvcName,
+					},
+				},
+			},
+		},
+		ImagePullSecrets: odooDeployment.Spec.ImagePullSecrets,
+		RestartPolicy:    corev1.RestartPolicyNever,
+	}
+	odooDeployment.Spec.Database.MountSSLFiles(&spec, &spec.Containers[0])
+	odooDeployment.applyPodTemplate(&spec)
+	odooDeployment.applyFilestoreAffinity(&spec, filestoreNode)
+
+	if source.S3 != nil {
+		downloadEnv := source.S3.GetS3EnvVars()
+		downloadEnv = append(downloadEnv,
+			corev1.EnvVar{Name: "S3_KEY_PREFIX", Value: source.KeyPrefix},
+			corev1.EnvVar{Name: "BACKUP_DIR", Value: "/backup"},
+		)
+		spec.InitContainers = []corev1.Container{
+			{
+				Name:            "download",
+				Image:           uploaderImage,
+				ImagePullPolicy: r.Spec.ImagePullPolicy,
+				Command:         []string{"/odoo-operator", "download-backup"},
+				Env:             downloadEnv,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      "backup",
+						MountPath: "/backup",
+					},
+				},
+			},
+		}
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: "backup",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	} else {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: "backup",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: source.PvcName,
+					ReadOnly:  true,
+				},
+			},
+		})
+	}
+
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.GetRestoreJobName(),
+			Namespace: r.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: odooDeployment.getPodTemplateMetadata(nil),
+				Spec:       spec,
+			},
+			Parallelism:  func(i int32) *int32 { return &i }(1),
+			BackoffLimit: func(i int32) *int32 { return &i }(2),
+		},
+	}
+}
+
+// GetRestoreSourceLocation returns where the artifacts of a succeeded OdooBackup are stored
+func (b *OdooBackup) GetRestoreSourceLocation() RestoreSourceLocation {
+	source := RestoreSourceLocation{
+		DatabaseDumpSHA256:     b.Status.DatabaseDump.SHA256,
+		FilestoreArchiveSHA256: b.Status.FilestoreArchive.SHA256,
+		OdooImage:              b.Status.OdooImage,
+		Modules:                b.Status.InstalledModules,
+	}
+	if b.Spec.S3 != nil {
+		source.S3 = b.Spec.S3
+		source.KeyPrefix = b.GetBackupObjectKeyPrefix()
+	} else {
+		source.PvcName = b.Status.PvcName
+	}
+	return source
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func minimalOdooRestore() *OdooRestore {
+	return &OdooRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-restore",
+			Namespace: "default",
+		},
+		Spec: OdooRestoreSpec{
+			OdooDeploymentRef: corev1.LocalObjectReference{Name: "test-odoo"},
+			Image:             "postgres:17",
+		},
+	}
+}
+
+func TestGetRestoreJobTemplate(t *testing.T) {
+	s3 := &S3Config{
+		Endpoint: "http://minio:9000",
+		Bucket:   "backups",
+		Region:   "us-east-1",
+	}
+
+	tests := []struct {
+		name               string
+		source             RestoreSourceLocation
+		wantInitContainers int
+		wantBackupClaim    string
+	}{
+		{
+			name:            "backup PVC is mounted read-only",
+			source:          RestoreSourceLocation{PvcName: "test-backup", DatabaseDumpSHA256: "abc"},
+			wantBackupClaim: "test-backup",
+		},
+		{
+			name:               "object store backups are downloaded first",
+			source:             RestoreSourceLocation{S3: s3, KeyPrefix: "default/test-odoo/20250316T020000Z"},
+			wantInitContainers: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+			r := minimalOdooRestore()
+
+			job := r.GetRestoreJobTemplate(o, DatabaseConnectionDetails{Host: "db-host", Port: 5432, Name: "odoo"}, tc.source, "", "uploader:latest")
+			spec := job.Spec.Template.Spec
+
+			if job.Name != "test-restore-restore" {
+				t.Errorf("job name = %q, want %q", job.Name, "test-restore-restore")
+			}
+			if len(spec.InitContainers) != tc.wantInitContainers {
+				t.Fatalf("init containers = %d, want %d", len(spec.InitContainers), tc.wantInitContainers)
+			}
+			env := map[string]string{}
+			for _, e := range spec.Containers[0].Env {
+				env[e.Name] = e.Value
+			}
+			if env["PGDATABASE"] != "odoo" || env["DB_DUMP_SHA256"] != tc.source.DatabaseDumpSHA256 {
+				t.Errorf("unexpected restore env: %v", spec.Containers[0].Env)
+			}
+
+			for _, v := range spec.Volumes {
+				switch v.Name {
+				case "odoo-data":
+					if v.PersistentVolumeClaim == nil || v.PersistentVolumeClaim.ReadOnly {
+						t.Errorf("filestore PVC must be mounted read-write, got %+v", v.VolumeSource)
+					}
+				case "backup":
+					if tc.wantBackupClaim == "" && v.EmptyDir == nil {
+						t.Errorf("backup volume must be scratch space, got %+v", v.VolumeSource)
+					}
+					if tc.wantBackupClaim != "" && (v.PersistentVolumeClaim == nil || v.PersistentVolumeClaim.ClaimName != tc.wantBackupClaim) {
+						t.Errorf("backup volume must be the PVC %q, got %+v", tc.wantBackupClaim, v.VolumeSource)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGetRestoreSourceLocation(t *testing.T) {
+	b := minimalOdooBackup()
+	b.Status.PvcName = "test-backup"
+	b.Status.DatabaseDump.SHA256 = "abc"
+	b.Status.OdooImage = "odoo:18"
+	b.Status.InstalledModules = []string{"base", "sale"}
+
+	source := b.GetRestoreSourceLocation()
+	if source.PvcName != "test-backup" || source.S3 != nil || source.DatabaseDumpSHA256 != "abc" {
+		t.Errorf("unexpected PVC source: %+v", source)
+	}
+	if source.OdooImage != "odoo:18" || strings.Join(source.Modules, ",") != "base,sale" {
+		t.Errorf("source image and modules = %q %v, want those of the backup status", source.OdooImage, source.Modules)
+	}
+
+	b.Spec.S3 = &S3Config{Bucket: "backups"}
+	source = b.GetRestoreSourceLocation()
+	if source.S3 == nil || source.PvcName != "" || source.KeyPrefix != b.GetBackupObjectKeyPrefix() {
+		t.Errorf("unexpected object store source: %+v", source)
+	}
+}
+
+func TestGetDeploymentReplicas(t *testing.T) {
+	o := minimalOdooDeployment([]string{"base"}, []string{"base"})
+	o.Spec.Replicas = 3
+
+	if got := *o.GetDeploymentTemplate().Spec.Replicas; got != 3 {
+		t.Errorf("replicas = %d, want 3", got)
+	}
+
+	o.Status.CurrentRestore = "test-restore"
+	if got := *o.GetDeploymentTemplate().Spec.Replicas; got != 0 {
+		t.Errorf("replicas during a restore = %d, want 0", got)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonRestoreSourceInvalid    = "RestoreSourceInvalid"
+	ReasonFailedGetBackupManifest = "FailedGetBackupManifest"
+	ReasonOdooBackupNotFound      = "OdooBackupNotFound"
+	ReasonOdooBackupNotSucceeded  = "OdooBackupNotSucceeded"
+	ReasonRestoreInProgress       = "RestoreInProgress"
+	ReasonWaitingForScaleDown     = "WaitingForScaleDown"
+	ReasonWaitingForDatabaseJobs  = "WaitingForDatabaseJobs"
+
+	ReasonFailedDeleteDatabaseJobs = "FailedDeleteDatabaseJobs"
+
+	ReasonRestoreJobCreationFailed = "RestoreJobCreationFailed"
+	ReasonRestoreJobCreated        = "RestoreJobCreated"
+	ReasonRestoreJobFailed         = "RestoreJobFailed"
+	ReasonRestoreSucceeded         = "RestoreSucceeded"
+)
+
+// RestorePhase is the lifecycle phase of a single restore run
+// +kubebuilder:validation:Enum=Pending;ScalingDown;Running;Succeeded;Failed
+type RestorePhase string
+
+const (
+	RestorePhasePending     RestorePhase = "Pending"
+	RestorePhaseScalingDown RestorePhase = "ScalingDown"
+	RestorePhaseRunning     RestorePhase = "Running"
+	RestorePhaseSucceeded   RestorePhase = "Succeeded"
+	RestorePhaseFailed      RestorePhase = "Failed"
+)
+
+// RestoreSource is the backup to restore, either an OdooBackup or a backup in an object store
+// +kubebuilder:validation:XValidation:rule="has(self.odooBackupName) != has(self.path)",message="exactly one of odooBackupName and path must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.path) || has(self.s3)",message="s3 is required when restoring from a path"
+type RestoreSource struct {
+	// The name of a succeeded OdooBackup in the same namespace as the OdooRestore
+	// +kubebuilder:validation:Optional
+	OdooBackupName string `json:"odooBackupName,omitempty"`
+
+	// The key prefix of a backup in the bucket of S3, the directory holding its manifest.json
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+
+	// The object store the backup is read from when restoring from a path
+	// +kubebuilder:validation:Optional
+	S3 *S3Config `json:"s3,omitempty"`
+}
+
+// OdooRestoreSpec defines the desired state of OdooRestore
+type OdooRestoreSpec struct {
+	// The backup to restore
+	Source RestoreSource `json:"source"`
+
+	// The OdooDeployment to restore into, in the same namespace as the OdooRestore.
+	// Its database and filestore are replaced by the content of the backup
+	OdooDeploymentRef corev1.LocalObjectReference `json:"odooDeploymentRef"`
+
+	// The image used to run the restore job, it must provide psql, pg_restore, tar, zstd and sha256sum
+	// +kubebuilder:default="postgres:17"
+	Image string `json:"image,omitempty"`
+
+	// Image pull policy for the restore job
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="IfNotPresent"
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// OdooRestoreStatus defines the observed state of OdooRestore
+type OdooRestoreStatus struct {
+	// The current phase of the restore
+	// +kubebuilder:validation:Optional
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// The name of the job running the restore
+	// +kubebuilder:validation:Optional
+	JobName string `json:"jobName,omitempty"`
+
+	// The time the restore job was created
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// The time the restore job finished
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// The modules installed in the restored database, recorded from the backup when the restore job is created
+	// +kubebuilder:validation:Optional
+	RestoredModules []string `json:"restoredModules,omitempty"`
+
+	// The Odoo image the backup was taken with
+	// +kubebuilder:validation:Optional
+	OdooImage string `json:"odooImage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="OdooDeployment",type=string,JSONPath=`.spec.odooDeploymentRef.name`
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.source.odooBackupName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OdooRestore is the Schema for the odoorestores API
+type OdooRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OdooRestoreSpec   `json:"spec,omitempty"`
+	Status OdooRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OdooRestoreList contains a list of OdooRestore
+type OdooRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OdooRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OdooRestore{}, &OdooRestoreList{})
+}
@@ -23,9 +23,106 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoTunedConfig) DeepCopyInto(out *AutoTunedConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoTunedConfig.
+func (in *AutoTunedConfig) DeepCopy() *AutoTunedConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoTunedConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupArtifact) DeepCopyInto(out *BackupArtifact) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupArtifact.
+func (in *BackupArtifact) DeepCopy() *BackupArtifact {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupArtifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupManifest) DeepCopyInto(out *BackupManifest) {
+	*out = *in
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.DatabaseDump = in.DatabaseDump
+	out.FilestoreArchive = in.FilestoreArchive
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupManifest.
+func (in *BackupManifest) DeepCopy() *BackupManifest {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupManifest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNPGClusterReference) DeepCopyInto(out *CNPGClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNPGClusterReference.
+func (in *CNPGClusterReference) DeepCopy() *CNPGClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CNPGClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateIssuerReference) DeepCopyInto(out *CertificateIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateIssuerReference.
+func (in *CertificateIssuerReference) DeepCopy() *CertificateIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionBudgetStatus) DeepCopyInto(out *ConnectionBudgetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionBudgetStatus.
+func (in *ConnectionBudgetStatus) DeepCopy() *ConnectionBudgetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionBudgetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DBInitjob) DeepCopyInto(out *DBInitjob) {
 	*out = *in
@@ -46,9 +143,26 @@ func (in *DBInitjob) DeepCopy() *DBInitjob {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DBUpgradeJob) DeepCopyInto(out *DBUpgradeJob) {
+	*out = *in
+	in.Upgrade.DeepCopyInto(&out.Upgrade)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DBUpgradeJob.
+func (in *DBUpgradeJob) DeepCopy() *DBUpgradeJob {
+	if in == nil {
+		return nil
+	}
+	out := new(DBUpgradeJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseConnectionDetails) DeepCopyInto(out *DatabaseConnectionDetails) {
 	*out = *in
+	in.PasswordSecret.DeepCopyInto(&out.PasswordSecret)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseConnectionDetails.
@@ -62,49 +176,126 @@ func (in *DatabaseConnectionDetails) DeepCopy() *DatabaseConnectionDetails {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OdooConfig) DeepCopyInto(out *OdooConfig) {
+func (in *GatewayParentReference) DeepCopyInto(out *GatewayParentReference) {
 	*out = *in
-	if in.ExtraAddonsPaths != nil {
-		in, out := &in.ExtraAddonsPaths, &out.ExtraAddonsPaths
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParentReference.
+func (in *GatewayParentReference) DeepCopy() *GatewayParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRouteParentStatus) DeepCopyInto(out *HTTPRouteParentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRouteParentStatus.
+func (in *HTTPRouteParentStatus) DeepCopy() *HTTPRouteParentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRouteParentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRouteStatus) DeepCopyInto(out *HTTPRouteStatus) {
+	*out = *in
+	if in.Parents != nil {
+		in, out := &in.Parents, &out.Parents
+		*out = make([]HTTPRouteParentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRouteStatus.
+func (in *HTTPRouteStatus) DeepCopy() *HTTPRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationStep) DeepCopyInto(out *MigrationStep) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooConfig.
-func (in *OdooConfig) DeepCopy() *OdooConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStep.
+func (in *MigrationStep) DeepCopy() *MigrationStep {
 	if in == nil {
 		return nil
 	}
-	out := new(OdooConfig)
+	out := new(MigrationStep)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OdooDatabaseConfig) DeepCopyInto(out *OdooDatabaseConfig) {
+func (in *MigrationStepStatus) DeepCopyInto(out *MigrationStepStatus) {
 	*out = *in
-	in.HostFromSecret.DeepCopyInto(&out.HostFromSecret)
-	in.PortFromSecret.DeepCopyInto(&out.PortFromSecret)
-	in.UserFromSecret.DeepCopyInto(&out.UserFromSecret)
-	in.PasswordFromSecret.DeepCopyInto(&out.PasswordFromSecret)
-	in.NameFromSecret.DeepCopyInto(&out.NameFromSecret)
-	in.SSLFromSecret.DeepCopyInto(&out.SSLFromSecret)
-	in.MaxConnFromSecret.DeepCopyInto(&out.MaxConnFromSecret)
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDatabaseConfig.
-func (in *OdooDatabaseConfig) DeepCopy() *OdooDatabaseConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStepStatus.
+func (in *MigrationStepStatus) DeepCopy() *MigrationStepStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OdooDatabaseConfig)
+	out := new(MigrationStepStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OdooDeployment) DeepCopyInto(out *OdooDeployment) {
+func (in *OdooBackup) DeepCopyInto(out *OdooBackup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -112,18 +303,18 @@ func (in *OdooDeployment) DeepCopyInto(out *OdooDeployment) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeployment.
-func (in *OdooDeployment) DeepCopy() *OdooDeployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooBackup.
+func (in *OdooBackup) DeepCopy() *OdooBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(OdooDeployment)
+	out := new(OdooBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OdooDeployment) DeepCopyObject() runtime.Object {
+func (in *OdooBackup) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -131,31 +322,52 @@ func (in *OdooDeployment) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OdooDeploymentList) DeepCopyInto(out *OdooDeploymentList) {
+func (in *OdooBackupConfig) DeepCopyInto(out *OdooBackupConfig) {
+	*out = *in
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Config)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooBackupConfig.
+func (in *OdooBackupConfig) DeepCopy() *OdooBackupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooBackupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooBackupList) DeepCopyInto(out *OdooBackupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]OdooDeployment, len(*in))
+		*out = make([]OdooBackup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeploymentList.
-func (in *OdooDeploymentList) DeepCopy() *OdooDeploymentList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooBackupList.
+func (in *OdooBackupList) DeepCopy() *OdooBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(OdooDeploymentList)
+	out := new(OdooBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OdooDeploymentList) DeepCopyObject() runtime.Object {
+func (in *OdooBackupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -163,47 +375,45 @@ func (in *OdooDeploymentList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OdooDeploymentSpec) DeepCopyInto(out *OdooDeploymentSpec) {
+func (in *OdooBackupSpec) DeepCopyInto(out *OdooBackupSpec) {
 	*out = *in
-	if in.OdooCommand != nil {
-		in, out := &in.OdooCommand, &out.OdooCommand
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
-	in.Database.DeepCopyInto(&out.Database)
-	in.Config.DeepCopyInto(&out.Config)
-	if in.Modules != nil {
-		in, out := &in.Modules, &out.Modules
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.OdooDeploymentRef = in.OdooDeploymentRef
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Config)
+		(*in).DeepCopyInto(*out)
 	}
-	in.OdooFilestore.DeepCopyInto(&out.OdooFilestore)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeploymentSpec.
-func (in *OdooDeploymentSpec) DeepCopy() *OdooDeploymentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooBackupSpec.
+func (in *OdooBackupSpec) DeepCopy() *OdooBackupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(OdooDeploymentSpec)
+	out := new(OdooBackupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OdooDeploymentStatus) DeepCopyInto(out *OdooDeploymentStatus) {
+func (in *OdooBackupStatus) DeepCopyInto(out *OdooBackupStatus) {
 	*out = *in
-	if in.InitModulesInstalled != nil {
-		in, out := &in.InitModulesInstalled, &out.InitModulesInstalled
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.InstalledModules != nil {
+		in, out := &in.InstalledModules, &out.InstalledModules
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.CurrentInitJob.DeepCopyInto(&out.CurrentInitJob)
+	out.DatabaseDump = in.DatabaseDump
+	out.FilestoreArchive = in.FilestoreArchive
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -213,33 +423,881 @@ func (in *OdooDeploymentStatus) DeepCopyInto(out *OdooDeploymentStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeploymentStatus.
-func (in *OdooDeploymentStatus) DeepCopy() *OdooDeploymentStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooBackupStatus.
+func (in *OdooBackupStatus) DeepCopy() *OdooBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OdooDeploymentStatus)
+	out := new(OdooBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PersistentVolumeClaimSpec) DeepCopyInto(out *PersistentVolumeClaimSpec) {
+func (in *OdooCertificateConfig) DeepCopyInto(out *OdooCertificateConfig) {
 	*out = *in
-	out.Size = in.Size.DeepCopy()
-	if in.AccessModes != nil {
-		in, out := &in.AccessModes, &out.AccessModes
-		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooCertificateConfig.
+func (in *OdooCertificateConfig) DeepCopy() *OdooCertificateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooCertificateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooConfig) DeepCopyInto(out *OdooConfig) {
+	*out = *in
+	if in.ExtraAddonsPaths != nil {
+		in, out := &in.ExtraAddonsPaths, &out.ExtraAddonsPaths
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimSpec.
-func (in *PersistentVolumeClaimSpec) DeepCopy() *PersistentVolumeClaimSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooConfig.
+func (in *OdooConfig) DeepCopy() *OdooConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(PersistentVolumeClaimSpec)
+	out := new(OdooConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDatabaseConfig) DeepCopyInto(out *OdooDatabaseConfig) {
+	*out = *in
+	if in.CNPGClusterRef != nil {
+		in, out := &in.CNPGClusterRef, &out.CNPGClusterRef
+		*out = new(CNPGClusterReference)
+		**out = **in
+	}
+	in.HostFromSecret.DeepCopyInto(&out.HostFromSecret)
+	in.PortFromSecret.DeepCopyInto(&out.PortFromSecret)
+	in.UserFromSecret.DeepCopyInto(&out.UserFromSecret)
+	in.PasswordFromSecret.DeepCopyInto(&out.PasswordFromSecret)
+	in.NameFromSecret.DeepCopyInto(&out.NameFromSecret)
+	in.SSLModeFromSecret.DeepCopyInto(&out.SSLModeFromSecret)
+	in.SSLRootCertFromSecret.DeepCopyInto(&out.SSLRootCertFromSecret)
+	in.SSLCertFromSecret.DeepCopyInto(&out.SSLCertFromSecret)
+	in.SSLKeyFromSecret.DeepCopyInto(&out.SSLKeyFromSecret)
+	in.MaxConnFromSecret.DeepCopyInto(&out.MaxConnFromSecret)
+	in.ReplicaHostFromSecret.DeepCopyInto(&out.ReplicaHostFromSecret)
+	in.ReplicaPortFromSecret.DeepCopyInto(&out.ReplicaPortFromSecret)
+	if in.Provisioning != nil {
+		in, out := &in.Provisioning, &out.Provisioning
+		*out = new(OdooDatabaseProvisioning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pooler != nil {
+		in, out := &in.Pooler, &out.Pooler
+		*out = new(OdooDatabasePooler)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDatabaseConfig.
+func (in *OdooDatabaseConfig) DeepCopy() *OdooDatabaseConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDatabaseConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDatabasePooler) DeepCopyInto(out *OdooDatabasePooler) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDatabasePooler.
+func (in *OdooDatabasePooler) DeepCopy() *OdooDatabasePooler {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDatabasePooler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDatabaseProvisioning) DeepCopyInto(out *OdooDatabaseProvisioning) {
+	*out = *in
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDatabaseProvisioning.
+func (in *OdooDatabaseProvisioning) DeepCopy() *OdooDatabaseProvisioning {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDatabaseProvisioning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDeployment) DeepCopyInto(out *OdooDeployment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeployment.
+func (in *OdooDeployment) DeepCopy() *OdooDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OdooDeployment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDeploymentList) DeepCopyInto(out *OdooDeploymentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OdooDeployment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeploymentList.
+func (in *OdooDeploymentList) DeepCopy() *OdooDeploymentList {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDeploymentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OdooDeploymentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDeploymentSpec) DeepCopyInto(out *OdooDeploymentSpec) {
+	*out = *in
+	if in.OdooCommand != nil {
+		in, out := &in.OdooCommand, &out.OdooCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	in.Backup.DeepCopyInto(&out.Backup)
+	in.Database.DeepCopyInto(&out.Database)
+	in.Config.DeepCopyInto(&out.Config)
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Upgrade.DeepCopyInto(&out.Upgrade)
+	in.OdooFilestore.DeepCopyInto(&out.OdooFilestore)
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(OdooProbesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(OdooPodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplatePatch != nil {
+		in, out := &in.PodTemplatePatch, &out.PodTemplatePatch
+		*out = new(OdooPodTemplatePatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(OdooIngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(OdooExposureConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeploymentSpec.
+func (in *OdooDeploymentSpec) DeepCopy() *OdooDeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooDeploymentStatus) DeepCopyInto(out *OdooDeploymentStatus) {
+	*out = *in
+	if in.InitModulesInstalled != nil {
+		in, out := &in.InitModulesInstalled, &out.InitModulesInstalled
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.CurrentInitJob.DeepCopyInto(&out.CurrentInitJob)
+	in.CurrentUninstallJob.DeepCopyInto(&out.CurrentUninstallJob)
+	in.CurrentUpgradeJob.DeepCopyInto(&out.CurrentUpgradeJob)
+	in.LastUpgrade.DeepCopyInto(&out.LastUpgrade)
+	in.ResolvedImage.DeepCopyInto(&out.ResolvedImage)
+	if in.LastScheduledBackupTime != nil {
+		in, out := &in.LastScheduledBackupTime, &out.LastScheduledBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(HTTPRouteStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionBudget != nil {
+		in, out := &in.ConnectionBudget, &out.ConnectionBudget
+		*out = new(ConnectionBudgetStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooDeploymentStatus.
+func (in *OdooDeploymentStatus) DeepCopy() *OdooDeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooDeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooExposureConfig) DeepCopyInto(out *OdooExposureConfig) {
+	*out = *in
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(OdooGatewayConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(OdooCertificateConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooExposureConfig.
+func (in *OdooExposureConfig) DeepCopy() *OdooExposureConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooExposureConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooGatewayConfig) DeepCopyInto(out *OdooGatewayConfig) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]GatewayParentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooGatewayConfig.
+func (in *OdooGatewayConfig) DeepCopy() *OdooGatewayConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooGatewayConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooIngressConfig) DeepCopyInto(out *OdooIngressConfig) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooIngressConfig.
+func (in *OdooIngressConfig) DeepCopy() *OdooIngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooIngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooMigration) DeepCopyInto(out *OdooMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooMigration.
+func (in *OdooMigration) DeepCopy() *OdooMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OdooMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooMigrationList) DeepCopyInto(out *OdooMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OdooMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooMigrationList.
+func (in *OdooMigrationList) DeepCopy() *OdooMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OdooMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooMigrationSpec) DeepCopyInto(out *OdooMigrationSpec) {
+	*out = *in
+	out.OdooDeploymentRef = in.OdooDeploymentRef
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]MigrationStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooMigrationSpec.
+func (in *OdooMigrationSpec) DeepCopy() *OdooMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooMigrationStatus) DeepCopyInto(out *OdooMigrationStatus) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]MigrationStepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooMigrationStatus.
+func (in *OdooMigrationStatus) DeepCopy() *OdooMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooPodTemplate) DeepCopyInto(out *OdooPodTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooPodTemplate.
+func (in *OdooPodTemplate) DeepCopy() *OdooPodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooPodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooPodTemplatePatch) DeepCopyInto(out *OdooPodTemplatePatch) {
+	*out = *in
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(PodSpecPatch)
+		**out = **in
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(PodSpecPatch)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooPodTemplatePatch.
+func (in *OdooPodTemplatePatch) DeepCopy() *OdooPodTemplatePatch {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooPodTemplatePatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooProbesConfig) DeepCopyInto(out *OdooProbesConfig) {
+	*out = *in
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooProbesConfig.
+func (in *OdooProbesConfig) DeepCopy() *OdooProbesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooProbesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooRestore) DeepCopyInto(out *OdooRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooRestore.
+func (in *OdooRestore) DeepCopy() *OdooRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OdooRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooRestoreList) DeepCopyInto(out *OdooRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OdooRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooRestoreList.
+func (in *OdooRestoreList) DeepCopy() *OdooRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OdooRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooRestoreSpec) DeepCopyInto(out *OdooRestoreSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	out.OdooDeploymentRef = in.OdooDeploymentRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooRestoreSpec.
+func (in *OdooRestoreSpec) DeepCopy() *OdooRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooRestoreStatus) DeepCopyInto(out *OdooRestoreStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.RestoredModules != nil {
+		in, out := &in.RestoredModules, &out.RestoredModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooRestoreStatus.
+func (in *OdooRestoreStatus) DeepCopy() *OdooRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooUpgrade) DeepCopyInto(out *OdooUpgrade) {
+	*out = *in
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooUpgrade.
+func (in *OdooUpgrade) DeepCopy() *OdooUpgrade {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooUpgrade)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OdooUpgradeConfig) DeepCopyInto(out *OdooUpgradeConfig) {
+	*out = *in
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OdooUpgradeConfig.
+func (in *OdooUpgradeConfig) DeepCopy() *OdooUpgradeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OdooUpgradeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimSpec) DeepCopyInto(out *PersistentVolumeClaimSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimSpec.
+func (in *PersistentVolumeClaimSpec) DeepCopy() *PersistentVolumeClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSpecPatch) DeepCopyInto(out *PodSpecPatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSpecPatch.
+func (in *PodSpecPatch) DeepCopy() *PodSpecPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSpecPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedImage) DeepCopyInto(out *ResolvedImage) {
+	*out = *in
+	if in.ResolvedAt != nil {
+		in, out := &in.ResolvedAt, &out.ResolvedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedImage.
+func (in *ResolvedImage) DeepCopy() *ResolvedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSource) DeepCopyInto(out *RestoreSource) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Config)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSource.
+func (in *RestoreSource) DeepCopy() *RestoreSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSourceLocation) DeepCopyInto(out *RestoreSourceLocation) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Config)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSourceLocation.
+func (in *RestoreSourceLocation) DeepCopy() *RestoreSourceLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSourceLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Config) DeepCopyInto(out *S3Config) {
+	*out = *in
+	in.AccessKeyFromSecret.DeepCopyInto(&out.AccessKeyFromSecret)
+	in.SecretKeyFromSecret.DeepCopyInto(&out.SecretKeyFromSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Config.
+func (in *S3Config) DeepCopy() *S3Config {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Config)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/objectstore"
+)
+
+// downloadBackupCommand is the first argument that makes the binary download a backup
+// instead of running the manager. It is used by the restore jobs of OdooRestores reading
+// from an object store, the configuration is read from the environment set by the job template.
+const downloadBackupCommand = "download-backup"
+
+// runDownloadBackup downloads the backup at S3_KEY_PREFIX into BACKUP_DIR. The manifest is
+// downloaded first and the artifacts are verified against the checksums it records.
+func runDownloadBackup() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := objectstore.NewClient(objectstore.Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		Region:    os.Getenv("S3_REGION"),
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	})
+	if err != nil {
+		return err
+	}
+
+	backupDir := os.Getenv("BACKUP_DIR")
+	keyPrefix := os.Getenv("S3_KEY_PREFIX")
+
+	manifestPath := filepath.Join(backupDir, odoov1.BackupManifestFileName)
+	fmt.Printf("Downloading %s\n", client.URL(objectstore.Key(keyPrefix, odoov1.BackupManifestFileName)))
+	if err := client.DownloadFile(ctx, objectstore.Key(keyPrefix, odoov1.BackupManifestFileName), manifestPath); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	manifest, err := odoov1.ParseBackupManifest(string(data))
+	if err != nil {
+		return err
+	}
+
+	for _, artifact := range []odoov1.BackupArtifact{manifest.DatabaseDump, manifest.FilestoreArchive} {
+		key := objectstore.Key(keyPrefix, artifact.Name)
+		artifactPath := filepath.Join(backupDir, filepath.Base(artifact.Name))
+		fmt.Printf("Downloading %s\n", client.URL(key))
+		if err := client.DownloadFile(ctx, key, artifactPath); err != nil {
+			return err
+		}
+		if err := verifyArtifact(artifactPath, artifact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyArtifact checks the size and the checksum of a downloaded artifact against the manifest
+func verifyArtifact(filePath string, artifact odoov1.BackupArtifact) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+	if size != artifact.Size {
+		return fmt.Errorf("%s has %d bytes, the manifest expects %d", artifact.Name, size, artifact.Size)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); artifact.SHA256 != "" && sum != artifact.SHA256 {
+		return fmt.Errorf("%s has checksum %s, the manifest expects %s", artifact.Name, sum, artifact.SHA256)
+	}
+	return nil
+}
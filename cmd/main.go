@@ -19,6 +19,7 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -26,10 +27,15 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -38,9 +44,14 @@ import (
 
 	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
 	"github.com/MohanadAbugharbia/odoo-operator/internal/controller"
+	webhookv1 "github.com/MohanadAbugharbia/odoo-operator/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
+// defaultBackupUploaderImage is the image of the operator itself, it is set at build time with
+// -ldflags "-X main.defaultBackupUploaderImage=<image>" so the uploader matches the operator version
+var defaultBackupUploaderImage = "ghcr.io/mohanadabugharbia/odoo-operator:latest"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -54,6 +65,21 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == uploadBackupCommand {
+		if err := runUploadBackup(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to upload backup: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == downloadBackupCommand {
+		if err := runDownloadBackup(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to download backup: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
@@ -61,6 +87,7 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	var logLevel string
+	var backupUploaderImage string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -72,6 +99,8 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	flag.StringVar(&backupUploaderImage, "backup-uploader-image", getEnvOrDefault("BACKUP_UPLOADER_IMAGE", defaultBackupUploaderImage),
+		"The image used to upload backups to and download them from S3, it must contain this binary.")
 	flag.Parse()
 
 	var zapLevel zapcore.Level
@@ -126,8 +155,20 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
+	// Only the pods created for an OdooDeployment are cached, not every pod of the cluster
+	odooPods, err := labels.NewRequirement(odoov1.OdooDeploymentLabel, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "unable to create the pod cache selector")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
+		Scheme: scheme,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: {Label: labels.NewSelector().Add(*odooPods)},
+			},
+		},
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -157,6 +198,36 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "OdooDeployment")
 		os.Exit(1)
 	}
+	if err = (&controller.OdooBackupReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		UploaderImage: backupUploaderImage,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OdooBackup")
+		os.Exit(1)
+	}
+	if err = (&controller.OdooRestoreReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		UploaderImage: backupUploaderImage,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OdooRestore")
+		os.Exit(1)
+	}
+	if err = (&controller.OdooMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OdooMigration")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookv1.SetupOdooDeploymentWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OdooDeployment")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -174,3 +245,10 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func getEnvOrDefault(key string, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/objectstore"
+)
+
+// uploadBackupCommand is the first argument that makes the binary upload a backup
+// instead of running the manager. It is used by the backup jobs of OdooBackups with an
+// S3 target, the configuration is read from the environment set by the job template.
+const uploadBackupCommand = "upload-backup"
+
+// runUploadBackup uploads the artifacts of the backup in BACKUP_DIR to S3_KEY_PREFIX.
+// The manifest is uploaded last, so a backup without manifest in the object store is
+// known to be incomplete. The manifest without its modules is then written to the termination log.
+func runUploadBackup() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := objectstore.NewClient(objectstore.Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		Region:    os.Getenv("S3_REGION"),
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	})
+	if err != nil {
+		return err
+	}
+
+	backupDir := os.Getenv("BACKUP_DIR")
+	keyPrefix := os.Getenv("S3_KEY_PREFIX")
+	for _, name := range []string{
+		odoov1.BackupDatabaseDumpFileName,
+		odoov1.BackupFilestoreArchiveFileName,
+		odoov1.BackupManifestFileName,
+	} {
+		key := objectstore.Key(keyPrefix, name)
+		fmt.Printf("Uploading %s to %s\n", name, client.URL(key))
+		if err := client.UploadFile(ctx, key, filepath.Join(backupDir, name)); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(backupDir, odoov1.BackupManifestFileName))
+	if err != nil {
+		return err
+	}
+	manifest, err := odoov1.ParseBackupManifest(string(data))
+	if err != nil {
+		return err
+	}
+	message, err := manifest.GetTerminationMessage()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile("/dev/termination-log", message, 0o644)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/objectstore"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooBackupReconciler reconciles a OdooBackup object
+type OdooBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// UploaderImage is the image of the container uploading backups to S3
+	UploaderImage string
+}
+
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoobackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoobackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoobackups/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=get;list;watch
+
+// Reconcile runs a single backup of the referenced OdooDeployment. A backup is run exactly once,
+// once it has succeeded or failed the OdooBackup is only reconciled again when it is deleted.
+// Backups uploaded to S3 hold a finalizer, their objects are deleted together with the OdooBackup.
+func (r *OdooBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	odooBackup := &odoov1.OdooBackup{}
+	err := r.Get(ctx, req.NamespacedName, odooBackup)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("OdooBackup resource object not found.")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		logger.Error(err, "Failed to get OdooBackup")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	if !odooBackup.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, odooBackup)
+	}
+	if odooBackup.Spec.S3 != nil && controllerutil.AddFinalizer(odooBackup, odoov1.BackupObjectsFinalizer) {
+		if err := r.Update(ctx, odooBackup); err != nil {
+			logger.Error(err, "Failed to add the finalizer to OdooBackup")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+	}
+
+	if odooBackup.Status.Phase == odoov1.BackupPhaseSucceeded || odooBackup.Status.Phase == odoov1.BackupPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	if odooBackup.Status.JobName != "" {
+		return r.reconcileBackupJob(ctx, odooBackup)
+	}
+
+	odooDeployment := &odoov1.OdooDeployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: odooBackup.Spec.OdooDeploymentRef.Name, Namespace: odooBackup.Namespace}, odooDeployment)
+	if err != nil {
+		logger.Error(err, "Failed to get OdooDeployment", "odooDeployment", odooBackup.Spec.OdooDeploymentRef.Name)
+		odooBackup.Status.Phase = odoov1.BackupPhasePending
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentNotFound, fmt.Sprintf("Failed to get OdooDeployment %s: %v", odooBackup.Spec.OdooDeploymentRef.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooBackup)
+	}
+
+	if odooDeployment.Status.OdooDataPvcName == "" || len(odooDeployment.Status.InitModulesInstalled) == 0 {
+		logger.Info("OdooDeployment has not been initialised yet, waiting", "odooDeployment", odooDeployment.Name)
+		odooBackup.Status.Phase = odoov1.BackupPhasePending
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentNotReady, fmt.Sprintf("OdooDeployment %s has not been initialised yet", odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooBackup)
+	}
+
+	if odooBackup.Spec.S3 != nil {
+		if _, _, err := objectstore.ParseEndpoint(odooBackup.Spec.S3.Endpoint); err != nil {
+			logger.Error(err, "Invalid S3 endpoint", "endpoint", odooBackup.Spec.S3.Endpoint)
+			odooBackup.Status.Phase = odoov1.BackupPhaseFailed
+			utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonS3ConfigInvalid, fmt.Sprintf("Invalid S3 endpoint %q: %v", odooBackup.Spec.S3.Endpoint, err), metav1.ConditionFalse)
+			return ctrl.Result{}, r.Status().Update(ctx, odooBackup)
+		}
+		odooBackup.Status.Location = odooBackup.GetBackupLocation()
+	} else {
+		pvc, err := r.reconcileBackupPvc(ctx, odooBackup)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile backup PVC")
+			utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonBackupPvcCreationFailed, fmt.Sprintf("error creating %s pvc: %v", odooBackup.GetBackupPvcName(), err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooBackup)})
+		}
+		odooBackup.Status.PvcName = pvc.Name
+	}
+
+	dbConnectionDetails, err := odooDeployment.Spec.Database.GetDbConnectionDetails(r.Client, ctx, odooDeployment.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get database connection details")
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonDbConnectionDetailsFailed, fmt.Sprintf("Failed to get database connection details: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooBackup)})
+	}
+
+	filestoreNode, err := utils.GetPvcNodeName(r.Client, ctx, odooDeployment.Namespace, odooDeployment.Status.OdooDataPvcName)
+	if err != nil {
+		logger.Error(err, "Failed to get the node of the filestore", "pvc", odooDeployment.Status.OdooDataPvcName)
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetFilestoreNode, fmt.Sprintf("Failed to get the node PVC %s is attached to: %v", odooDeployment.Status.OdooDataPvcName, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooBackup)})
+	}
+
+	job := odooBackup.GetBackupJobTemplate(odooDeployment, dbConnectionDetails, filestoreNode, r.UploaderImage)
+	if err := odooDeployment.PatchJobPodSpec(&job.Spec.Template.Spec); err != nil {
+		logger.Error(err, fmt.Sprintf("Failed to patch the pod spec of the backup job %s", job.Name))
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.jobs of OdooDeployment %s: %v", odooDeployment.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooBackup)})
+	}
+	ctrl.SetControllerReference(odooBackup, &job, r.Scheme)
+	logger.Info(fmt.Sprintf("Creating backup job %s", job.Name))
+	err = r.Create(ctx, &job)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, fmt.Sprintf("error creating %s backup job.", job.Name))
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonBackupJobCreationFailed, fmt.Sprintf("error creating %s backup job: %v", job.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooBackup)})
+	}
+
+	now := metav1.Now()
+	odooBackup.Status.Phase = odoov1.BackupPhaseRunning
+	odooBackup.Status.JobName = job.Name
+	odooBackup.Status.StartedAt = &now
+	odooBackup.Status.Database = dbConnectionDetails.Name
+	odooBackup.Status.OdooImage = odooDeployment.Spec.Image
+	odooBackup.Status.InstalledModules = odooDeployment.Status.InitModulesInstalled
+	utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorSucceeded", odoov1.ReasonBackupJobCreated, fmt.Sprintf("Backup job %s created", job.Name), metav1.ConditionTrue)
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooBackup)
+}
+
+func (r *OdooBackupReconciler) reconcileBackupPvc(ctx context.Context, odooBackup *odoov1.OdooBackup) (corev1.PersistentVolumeClaim, error) {
+	pvc := corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Name: odooBackup.GetBackupPvcName(), Namespace: odooBackup.Namespace}, &pvc)
+	if err == nil {
+		return pvc, nil
+	} else if !errors.IsNotFound(err) {
+		return pvc, err
+	}
+
+	pvc = odooBackup.GetBackupPvcTemplate()
+	ctrl.SetControllerReference(odooBackup, &pvc, r.Scheme)
+	log.FromContext(ctx).Info(fmt.Sprintf("Creating a new PVC for %s", odooBackup.Name))
+	return pvc, r.Create(ctx, &pvc)
+}
+
+// reconcileBackupJob follows the backup job and records its outcome in the OdooBackup status
+func (r *OdooBackupReconciler) reconcileBackupJob(ctx context.Context, odooBackup *odoov1.OdooBackup) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: odooBackup.Status.JobName, Namespace: odooBackup.Namespace}, job)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Backup job not found", "job", odooBackup.Status.JobName)
+		odooBackup.Status.Phase = odoov1.BackupPhaseFailed
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonBackupJobFailed, fmt.Sprintf("Backup job %s not found", odooBackup.Status.JobName), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, odooBackup)
+	} else if err != nil {
+		logger.Error(err, "Failed to get backup job")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	if job.Status.Succeeded > 0 {
+		message, err := utils.GetJobTerminationMessage(r.Client, ctx, job)
+		if err != nil {
+			logger.Error(err, "Failed to read backup manifest from job", "job", job.Name)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+		manifest, err := odoov1.ParseBackupManifest(message)
+		if err != nil {
+			logger.Error(err, "Failed to parse backup manifest", "job", job.Name)
+			odooBackup.Status.Phase = odoov1.BackupPhaseFailed
+			utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonBackupManifestInvalid, fmt.Sprintf("Failed to parse backup manifest of job %s: %v", job.Name, err), metav1.ConditionFalse)
+			return ctrl.Result{}, r.Status().Update(ctx, odooBackup)
+		}
+		now := metav1.Now()
+		odooBackup.Status.Phase = odoov1.BackupPhaseSucceeded
+		odooBackup.Status.CompletedAt = &now
+		odooBackup.Status.DatabaseDump = manifest.DatabaseDump
+		odooBackup.Status.FilestoreArchive = manifest.FilestoreArchive
+		logger.Info("Backup succeeded", "job", job.Name)
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorSucceeded", odoov1.ReasonBackupSucceeded, fmt.Sprintf("Backup job %s succeeded", job.Name), metav1.ConditionTrue)
+		return ctrl.Result{}, r.Status().Update(ctx, odooBackup)
+	} else if job.Status.Failed > 0 && job.Status.Active == 0 && isJobFinished(job) {
+		now := metav1.Now()
+		odooBackup.Status.Phase = odoov1.BackupPhaseFailed
+		odooBackup.Status.CompletedAt = &now
+		utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonBackupJobFailed, fmt.Sprintf("Backup job %s failed", job.Name), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, odooBackup)
+	}
+
+	logger.Info("Backup job still running, requeueing", "job", job.Name)
+	return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+}
+
+// reconcileDelete deletes the objects of a backup uploaded to S3 before its finalizer is removed.
+// A running backup job is deleted first so it does not upload anything afterwards.
+func (r *OdooBackupReconciler) reconcileDelete(ctx context.Context, odooBackup *odoov1.OdooBackup) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(odooBackup, odoov1.BackupObjectsFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if odooBackup.Status.JobName != "" {
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: odooBackup.Status.JobName, Namespace: odooBackup.Namespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to get backup job")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		} else if err == nil {
+			// The job is kept until its pods are gone, they could still be uploading
+			logger.Info(fmt.Sprintf("Deleting backup job %s before its objects", job.Name))
+			err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground))
+			if err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to delete backup job")
+				return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+			}
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+
+	// Nothing was uploaded when the backup failed before its location was known
+	if odooBackup.Spec.S3 != nil && odooBackup.Status.Location != "" {
+		err := r.deleteBackupObjects(ctx, odooBackup)
+		if err != nil && goerrors.Is(err, utils.ErrSecretNotFound) {
+			// Without credentials the objects can not be deleted, e.g. while the namespace is deleted
+			logger.Info(fmt.Sprintf("Leaving the objects of OdooBackup %s in %s: %v", odooBackup.Name, odooBackup.GetBackupLocation(), err))
+		} else if err != nil {
+			logger.Error(err, "Failed to delete the objects of OdooBackup", "location", odooBackup.GetBackupLocation())
+			utils.UpdateStatus(&odooBackup.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteBackupObjects, fmt.Sprintf("Failed to delete the objects in %s: %v", odooBackup.GetBackupLocation(), err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooBackup)})
+		} else {
+			logger.Info(fmt.Sprintf("Deleted the objects of OdooBackup %s in %s", odooBackup.Name, odooBackup.GetBackupLocation()))
+		}
+	}
+
+	controllerutil.RemoveFinalizer(odooBackup, odoov1.BackupObjectsFinalizer)
+	return ctrl.Result{}, r.Update(ctx, odooBackup)
+}
+
+// deleteBackupObjects deletes everything below the object store prefix of the backup
+func (r *OdooBackupReconciler) deleteBackupObjects(ctx context.Context, odooBackup *odoov1.OdooBackup) error {
+	config, err := odooBackup.Spec.S3.GetObjectStoreConfig(r.Client, ctx, odooBackup.Namespace)
+	if err != nil {
+		return err
+	}
+	store, err := objectstore.NewClient(config)
+	if err != nil {
+		return err
+	}
+	return store.DeletePrefix(ctx, odooBackup.GetBackupObjectKeyPrefix())
+}
+
+// isJobFinished checks whether a job has a Complete or Failed condition
+func isJobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OdooBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&odoov1.OdooBackup{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Complete(r)
+}
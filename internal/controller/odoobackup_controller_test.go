@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+)
+
+var _ = Describe("OdooBackup Controller", func() {
+	Context("When the referenced OdooDeployment does not exist", func() {
+		const resourceName = "test-backup-missing-deployment"
+		const resourceNamespace = "default"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: resourceNamespace,
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind OdooBackup")
+			err := k8sClient.Get(ctx, typeNamespacedName, &odoov1.OdooBackup{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &odoov1.OdooBackup{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: resourceNamespace,
+					},
+					Spec: odoov1.OdooBackupSpec{
+						OdooDeploymentRef: corev1.LocalObjectReference{Name: "does-not-exist"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &odoov1.OdooBackup{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance OdooBackup")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should keep the backup pending", func() {
+			controllerReconciler := &OdooBackupReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			backup := &odoov1.OdooBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(backup.Status.Phase).To(Equal(odoov1.BackupPhasePending))
+			Expect(backup.Status.JobName).To(BeEmpty())
+		})
+	})
+
+	Context("When a backup to S3 is deleted", func() {
+		const resourceName = "test-backup-s3-finalizer"
+		const resourceNamespace = "default"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: resourceNamespace,
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind OdooBackup")
+			resource := &odoov1.OdooBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: resourceNamespace,
+				},
+				Spec: odoov1.OdooBackupSpec{
+					OdooDeploymentRef: corev1.LocalObjectReference{Name: "does-not-exist"},
+					S3: &odoov1.S3Config{
+						Bucket: "backups",
+						AccessKeyFromSecret: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "s3-credentials"},
+							Key:                  "access-key",
+						},
+						SecretKeyFromSecret: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "s3-credentials"},
+							Key:                  "secret-key",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		It("should hold a finalizer until the backup is deleted", func() {
+			controllerReconciler := &OdooBackupReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			backup := &odoov1.OdooBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(backup.Finalizers).To(ContainElement(odoov1.BackupObjectsFinalizer))
+
+			By("deleting the backup that never uploaded anything")
+			Expect(k8sClient.Delete(ctx, backup)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, &odoov1.OdooBackup{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -24,10 +24,13 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -74,11 +77,18 @@ func IsOwnedByOdooDeployment(obj client.Object) (string, bool) {
 // +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoodeployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoodeployments/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=postgresql.cnpg.io,resources=clusters,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoobackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoorestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoomigrations,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -162,18 +172,136 @@ func (r *OdooDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	odooDeployment.Status.OdooDataPvcName = pvc.Name
 	r.Status().Update(ctx, odooDeployment)
 
-	odooDatabaseInitJobReconciler := reconcileloops.OdooDatabaseInitJobReconciler{
+	odooPoolerReconciler := reconcileloops.OdooPoolerReconciler{
 		Client:         r.Client,
 		Scheme:         r.Scheme,
 		OdooDeployment: odooDeployment,
 	}
 
-	result, err, requeue := odooDatabaseInitJobReconciler.Reconcile(ctx, req)
+	err = odooPoolerReconciler.Reconcile(ctx, req)
 	if err != nil {
-		logger.Error(err, "Failed to reconcile Odoo database init job")
+		logger.Error(err, "Failed to reconcile the database pooler")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	odooRestoreLockReconciler := reconcileloops.OdooDatabaseLockReconciler{
+		Client:          r.Client,
+		Scheme:          r.Scheme,
+		OdooDeployment:  odooDeployment,
+		Lock:            &odooDeployment.Status.CurrentRestore,
+		Holder:          &odoov1.OdooRestore{},
+		ReasonReleased:  odoov1.ReasonRestoreReleased,
+		ReasonFailedGet: odoov1.ReasonFailedGetRestore,
+	}
+
+	result, err, restoring := odooRestoreLockReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile Odoo restore lock")
+		return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+	}
+
+	odooMigrationLockReconciler := reconcileloops.OdooDatabaseLockReconciler{
+		Client:          r.Client,
+		Scheme:          r.Scheme,
+		OdooDeployment:  odooDeployment,
+		Lock:            &odooDeployment.Status.CurrentMigration,
+		Holder:          &odoov1.OdooMigration{},
+		ReasonReleased:  odoov1.ReasonMigrationReleased,
+		ReasonFailedGet: odoov1.ReasonFailedGetMigration,
+	}
+
+	result, err, migrating := odooMigrationLockReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile Odoo migration lock")
 		return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
-	} else if requeue {
-		return result, r.Status().Update(ctx, odooDeployment)
+	}
+
+	// A tag pushed again is an image change, its digest is resolved before the upgrade job is reconciled
+	odooImageDigestReconciler := reconcileloops.OdooImageDigestReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		OdooDeployment: odooDeployment,
+	}
+
+	imageDigestResult, err := odooImageDigestReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile the image digest")
+		return imageDigestResult, err
+	}
+
+	// The database is being replaced by a restore or a migration, the database jobs would change it
+	if !restoring && !migrating {
+		// The role and the database have to exist before the init job connects to them
+		odooDatabaseProvisioningReconciler := reconcileloops.OdooDatabaseProvisioningReconciler{
+			Client:         r.Client,
+			Scheme:         r.Scheme,
+			OdooDeployment: odooDeployment,
+		}
+
+		result, err, requeue := odooDatabaseProvisioningReconciler.Reconcile(ctx, req)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile Odoo database provisioning")
+			return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+		} else if requeue {
+			return result, r.Status().Update(ctx, odooDeployment)
+		}
+
+		// New pods are only rolled out once the modules are upgraded. The upgrade runs before the init
+		// and uninstall jobs, which use spec.image and must not run against a database not migrated to it.
+		odooDatabaseUpgradeJobReconciler := reconcileloops.OdooDatabaseUpgradeJobReconciler{
+			Client:         r.Client,
+			Scheme:         r.Scheme,
+			OdooDeployment: odooDeployment,
+		}
+
+		result, err, requeue = odooDatabaseUpgradeJobReconciler.Reconcile(ctx, req)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile Odoo database upgrade job")
+			return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+		} else if requeue {
+			return result, r.Status().Update(ctx, odooDeployment)
+		}
+
+		odooDatabaseInitJobReconciler := reconcileloops.OdooDatabaseInitJobReconciler{
+			Client:         r.Client,
+			Scheme:         r.Scheme,
+			OdooDeployment: odooDeployment,
+		}
+
+		result, err, requeue = odooDatabaseInitJobReconciler.Reconcile(ctx, req)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile Odoo database init job")
+			return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+		} else if requeue {
+			return result, r.Status().Update(ctx, odooDeployment)
+		}
+
+		odooDatabaseUninstallJobReconciler := reconcileloops.OdooDatabaseUninstallJobReconciler{
+			Client:         r.Client,
+			Scheme:         r.Scheme,
+			OdooDeployment: odooDeployment,
+		}
+
+		result, err, requeue = odooDatabaseUninstallJobReconciler.Reconcile(ctx, req)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile Odoo database uninstall job")
+			return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+		} else if requeue {
+			return result, r.Status().Update(ctx, odooDeployment)
+		}
+	}
+
+	// Scaling the replicas, workers or cron threads can exhaust the connections of the database server
+	odooConnectionBudgetReconciler := reconcileloops.OdooConnectionBudgetReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		OdooDeployment: odooDeployment,
+	}
+
+	err = odooConnectionBudgetReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile Odoo connection budget")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
 	}
 
 	deploymentReconciler := reconcileloops.DeploymentReconciler{
@@ -214,21 +342,77 @@ func (r *OdooDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
 	}
 
+	odooIngressReconciler := reconcileloops.OdooIngressReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		OdooDeployment: odooDeployment,
+	}
+
+	_, err = odooIngressReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile Odoo ingress")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+	}
+
+	odooHTTPRouteReconciler := reconcileloops.OdooHTTPRouteReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		OdooDeployment: odooDeployment,
+	}
+
+	_, err = odooHTTPRouteReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile Odoo httproute")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+	}
+
+	odooCertificateReconciler := reconcileloops.OdooCertificateReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		OdooDeployment: odooDeployment,
+	}
+
+	_, err = odooCertificateReconciler.Reconcile(ctx, req)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile Odoo certificate")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+	}
+
+	odooScheduledBackupReconciler := reconcileloops.OdooScheduledBackupReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		OdooDeployment: odooDeployment,
+	}
+
+	if !restoring && !migrating {
+		result, err = odooScheduledBackupReconciler.Reconcile(ctx, req)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile scheduled backups")
+			return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooDeployment)})
+		}
+	}
+
 	logger.Info("Finished reconciling OdooDeployment")
 
+	if imageDigestResult.RequeueAfter > 0 && (result.RequeueAfter == 0 || imageDigestResult.RequeueAfter < result.RequeueAfter) {
+		result.RequeueAfter = imageDigestResult.RequeueAfter
+	}
+
 	utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorSucceeded", "ReconcileSucceeded", "Reconcile succeeded", metav1.ConditionTrue)
-	return ctrl.Result{}, utilerrors.NewAggregate([]error{nil, r.Status().Update(ctx, odooDeployment)})
+	return result, utilerrors.NewAggregate([]error{nil, r.Status().Update(ctx, odooDeployment)})
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *OdooDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&odoov1.OdooDeployment{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
+		Owns(&networkingv1.Ingress{}).
 		Owns(&batchv1.Job{}).
+		Owns(&corev1.Pod{}).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.mapSecretsToOdooDeployments()),
@@ -249,7 +433,55 @@ func (r *OdooDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(r.mapServicesToOdooDeployments()),
 			builder.WithPredicates(servicePredicate),
 		).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 2}).
+		Watches(
+			&networkingv1.Ingress{},
+			handler.EnqueueRequestsFromMapFunc(r.mapIngressesToOdooDeployments()),
+			builder.WithPredicates(ingressPredicate),
+		).
+		Watches(
+			&odoov1.OdooBackup{},
+			handler.EnqueueRequestsFromMapFunc(mapBackupsToOdooDeployments),
+			builder.WithPredicates(odooDeploymentBackupPredicate),
+		).
+		Watches(
+			&odoov1.OdooRestore{},
+			handler.EnqueueRequestsFromMapFunc(mapRestoresToOdooDeployments),
+			builder.WithPredicates(restorePredicate),
+		).
+		Watches(
+			&odoov1.OdooMigration{},
+			handler.EnqueueRequestsFromMapFunc(mapMigrationsToOdooDeployments),
+			builder.WithPredicates(migrationPredicate),
+		)
+
+	// HTTPRoutes and Certificates are only watched when the Gateway API and cert-manager are
+	// installed, the operator runs without them
+	for _, gvk := range []schema.GroupVersionKind{odoov1.HTTPRouteGroupVersionKind, odoov1.CertificateGroupVersionKind} {
+		if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			mgr.GetLogger().Info(fmt.Sprintf("%s not installed, it is not watched", gvk.GroupKind()), "error", err.Error())
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		b = b.Owns(obj)
+	}
+
+	// CloudNativePG Clusters are not owned by the OdooDeployments connecting to them, changes to
+	// their app secret are picked up through the secret watch, changes to the Cluster through this one
+	gvk := odoov1.CNPGClusterGroupVersionKind
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		mgr.GetLogger().Info(fmt.Sprintf("%s not installed, it is not watched", gvk.GroupKind()), "error", err.Error())
+	} else {
+		cluster := &unstructured.Unstructured{}
+		cluster.SetGroupVersionKind(gvk)
+		b = b.Watches(
+			cluster,
+			handler.EnqueueRequestsFromMapFunc(r.mapCNPGClustersToOdooDeployments()),
+			builder.WithPredicates(cnpgClusterPredicate),
+		)
+	}
+
+	return b.WithOptions(controller.Options{MaxConcurrentReconciles: 2}).
 		Complete(r)
 }
 
@@ -315,6 +547,81 @@ func (r *OdooDeploymentReconciler) mapServicesToOdooDeployments() handler.MapFun
 		return filterOdooDeploymentsUsingService(odooDeployments, service)
 	}
 }
+func (r *OdooDeploymentReconciler) mapIngressesToOdooDeployments() handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		ingress, ok := obj.(*networkingv1.Ingress)
+		if !ok {
+			return nil
+		}
+		odooDeployments, err := r.getOdooDeploymentsForIngressesToOdooDeploymentsMapper(ctx, ingress)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "while getting OdooDeployment list", "namespace", ingress.Namespace)
+			return nil
+		}
+		// build requests for OdooDeployment referring the Ingress
+		return filterOdooDeploymentsUsingIngress(odooDeployments, ingress)
+	}
+}
+
+func (r *OdooDeploymentReconciler) mapCNPGClustersToOdooDeployments() handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var odooDeployments odoov1.OdooDeploymentList
+		if err := r.List(ctx, &odooDeployments, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.FromContext(ctx).Error(err, "while getting OdooDeployment list", "namespace", obj.GetNamespace())
+			return nil
+		}
+		// build requests for OdooDeployment referring the Cluster
+		return filterOdooDeploymentsUsingCNPGCluster(odooDeployments, obj)
+	}
+}
+
+// mapBackupsToOdooDeployments maps a scheduled or pre-upgrade OdooBackup to the OdooDeployment it was taken from
+func mapBackupsToOdooDeployments(ctx context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[odoov1.OdooDeploymentLabel]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: obj.GetNamespace(),
+			},
+		},
+	}
+}
+
+// mapRestoresToOdooDeployments maps an OdooRestore to the OdooDeployment it restores into
+func mapRestoresToOdooDeployments(ctx context.Context, obj client.Object) []reconcile.Request {
+	restore, ok := obj.(*odoov1.OdooRestore)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      restore.Spec.OdooDeploymentRef.Name,
+				Namespace: restore.Namespace,
+			},
+		},
+	}
+}
+
+// mapMigrationsToOdooDeployments maps an OdooMigration to the OdooDeployment it migrates
+func mapMigrationsToOdooDeployments(ctx context.Context, obj client.Object) []reconcile.Request {
+	migration, ok := obj.(*odoov1.OdooMigration)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      migration.Spec.OdooDeploymentRef.Name,
+				Namespace: migration.Namespace,
+			},
+		},
+	}
+}
 
 func (r *OdooDeploymentReconciler) getOdooDeploymentsForSecretsOrConfigMapsToOdooDeploymentsMapper(
 	ctx context.Context,
@@ -393,6 +700,25 @@ func (r *OdooDeploymentReconciler) getOdooDeploymentsForServicesToOdooDeployment
 	return odooDeployments, err
 }
 
+func (r *OdooDeploymentReconciler) getOdooDeploymentsForIngressesToOdooDeploymentsMapper(
+	ctx context.Context,
+	object metav1.Object,
+) (odooDeployments odoov1.OdooDeploymentList, err error) {
+	_, isIngress := object.(*networkingv1.Ingress)
+
+	if !isIngress {
+		return odooDeployments, fmt.Errorf("unsupported object: %+v", object)
+	}
+
+	// Get all the Odoo Deployments handled by the operator in the Ingress namespaces
+	err = r.List(
+		ctx,
+		&odooDeployments,
+		client.InNamespace(object.GetNamespace()),
+	)
+	return odooDeployments, err
+}
+
 // filterOdooDeploymentsUsingSecret returns a list of reconcile.Request for the Odoo Deployments
 // that reference the secret
 func filterOdooDeploymentsUsingSecret(
@@ -480,3 +806,44 @@ func filterOdooDeploymentsUsingService(
 	}
 	return requests
 }
+
+// filterOdooDeploymentsUsingIngress returns a list of reconcile.Request for the Odoo Deployments
+// that reference the Ingress
+func filterOdooDeploymentsUsingCNPGCluster(
+	odooDeployments odoov1.OdooDeploymentList,
+	cluster client.Object,
+) (requests []reconcile.Request) {
+	for _, odooDeployment := range odooDeployments.Items {
+		if odooDeployment.UsesCNPGCluster(cluster.GetName()) {
+			requests = append(requests,
+				reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      odooDeployment.Name,
+						Namespace: odooDeployment.Namespace,
+					},
+				},
+			)
+		}
+	}
+	return requests
+}
+
+func filterOdooDeploymentsUsingIngress(
+	odooDeployments odoov1.OdooDeploymentList,
+	ingress *networkingv1.Ingress,
+) (requests []reconcile.Request) {
+	for _, odooDeployment := range odooDeployments.Items {
+		if odooDeployment.UsesIngress(ingress.Name) {
+			requests = append(requests,
+				reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      odooDeployment.Name,
+						Namespace: odooDeployment.Namespace,
+					},
+				},
+			)
+			continue
+		}
+	}
+	return requests
+}
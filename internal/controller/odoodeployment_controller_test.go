@@ -265,11 +265,11 @@ var _ = Describe("Filtering OdooDeployments by Secret", func() {
 					},
 					Key: "name",
 				},
-				SSLFromSecret: corev1.SecretKeySelector{
+				SSLModeFromSecret: corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
 						Name: "my-db-ssl-secret",
 					},
-					Key: "ssl",
+					Key: "sslmode",
 				},
 				MaxConnFromSecret: corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
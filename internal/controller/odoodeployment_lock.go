@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// isScaledDown returns true once the OdooDeployment controller scaled the Deployment of a locked
+// OdooDeployment to zero. While it is not, the WaitingForScaleDown condition is set in conditions.
+func isScaledDown(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment, conditions *[]metav1.Condition) (bool, error) {
+	deployment := &appsv1.Deployment{}
+	err := c.Get(ctx, types.NamespacedName{Name: odooDeployment.Name, Namespace: odooDeployment.Namespace}, deployment)
+	if err != nil && errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0 && deployment.Status.Replicas == 0 {
+		return true, nil
+	}
+	log.FromContext(ctx).Info("Waiting for the Deployment to scale down", "deployment", deployment.Name)
+	utils.UpdateStatus(conditions, "OperatorSucceeded", odoov1.ReasonWaitingForScaleDown, fmt.Sprintf("Waiting for Deployment %s to scale down", deployment.Name), metav1.ConditionTrue)
+	return false, nil
+}
@@ -3,10 +3,14 @@ package controller
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
 )
 
 var (
@@ -31,6 +35,18 @@ var (
 		})
 	}
 
+	isUsefulOdooDeploymentIngress = func(object client.Object) bool {
+		return isOwnedByOdooDeploymentOrSatisfiesPredicate(object, func(object client.Object) bool {
+			_, ok := object.(*networkingv1.Ingress)
+			return ok
+		})
+	}
+
+	isOdooDeploymentBackup = func(object client.Object) bool {
+		_, ok := object.(*odoov1.OdooBackup)
+		return ok && (object.GetLabels()[odoov1.ScheduledBackupLabel] == "true" || object.GetLabels()[odoov1.PreUpgradeBackupLabel] == "true")
+	}
+
 	isUsefulOdooDeploymentPVC = func(object client.Object) bool {
 		return isOwnedByOdooDeploymentOrSatisfiesPredicate(object, func(object client.Object) bool {
 			_, ok := object.(*corev1.PersistentVolumeClaim)
@@ -161,6 +177,146 @@ var (
 		},
 	}
 
+	// ingressPredicate filters ingress events
+	ingressPredicate = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			result := isUsefulOdooDeploymentIngress(e.Object)
+			if result {
+				ctrllog.Log.V(1).Info("Ingress created, triggering reconcile",
+					"ingress", e.Object.GetName(),
+					"namespace", e.Object.GetNamespace())
+			}
+			return result
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			result := isUsefulOdooDeploymentIngress(e.ObjectNew)
+			if result {
+				ctrllog.Log.V(1).Info("Ingress updated, triggering reconcile",
+					"ingress", e.ObjectNew.GetName(),
+					"namespace", e.ObjectNew.GetNamespace())
+			}
+			return result
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			result := isUsefulOdooDeploymentIngress(e.Object)
+			if result {
+				ctrllog.Log.V(1).Info("Ingress deleted, triggering reconcile",
+					"ingress", e.Object.GetName(),
+					"namespace", e.Object.GetNamespace())
+			}
+			return result
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isUsefulOdooDeploymentIngress(e.Object)
+		},
+	}
+
+	// odooDeploymentBackupPredicate filters scheduled and pre-upgrade backup events, the retention
+	// policy and the jobs waiting for a backup only need to know when a backup finishes or is removed
+	odooDeploymentBackupPredicate = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !isOdooDeploymentBackup(e.ObjectNew) {
+				return false
+			}
+			oldBackup, oldOk := e.ObjectOld.(*odoov1.OdooBackup)
+			newBackup, newOk := e.ObjectNew.(*odoov1.OdooBackup)
+			if oldOk && newOk && oldBackup.Status.Phase != newBackup.Status.Phase {
+				ctrllog.Log.V(1).Info("Backup phase changed, triggering reconcile",
+					"backup", newBackup.Name,
+					"namespace", newBackup.Namespace,
+					"phase", newBackup.Status.Phase)
+				return true
+			}
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isOdooDeploymentBackup(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isOdooDeploymentBackup(e.Object)
+		},
+	}
+
+	// migrationPredicate filters migration events, the migration lock of an OdooDeployment is only
+	// released by the OdooDeployment controller once its OdooMigration is deleted
+	migrationPredicate = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, ok := e.Object.(*odoov1.OdooMigration)
+			if ok {
+				ctrllog.Log.V(1).Info("OdooMigration deleted, triggering reconcile",
+					"migration", e.Object.GetName(),
+					"namespace", e.Object.GetNamespace())
+			}
+			return ok
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	// restorePredicate filters restore events, the restore lock of an OdooDeployment is only
+	// released by the OdooDeployment controller once its OdooRestore is deleted
+	restorePredicate = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, ok := e.Object.(*odoov1.OdooRestore)
+			if ok {
+				ctrllog.Log.V(1).Info("OdooRestore deleted, triggering reconcile",
+					"restore", e.Object.GetName(),
+					"namespace", e.Object.GetNamespace())
+			}
+			return ok
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	// cnpgClusterPredicate filters CloudNativePG Cluster events, the connection details only change
+	// with the spec of a Cluster, its phase tells when a newly created Cluster becomes usable
+	cnpgClusterPredicate = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, oldOk := e.ObjectOld.(*unstructured.Unstructured)
+			newCluster, newOk := e.ObjectNew.(*unstructured.Unstructured)
+			if !oldOk || !newOk {
+				return false
+			}
+			oldPhase, _, _ := unstructured.NestedString(oldCluster.Object, "status", "phase")
+			newPhase, _, _ := unstructured.NestedString(newCluster.Object, "status", "phase")
+			if oldCluster.GetGeneration() != newCluster.GetGeneration() || oldPhase != newPhase {
+				ctrllog.Log.V(1).Info("CloudNativePG Cluster changed, triggering reconcile",
+					"cluster", newCluster.GetName(),
+					"namespace", newCluster.GetNamespace(),
+					"phase", newPhase)
+				return true
+			}
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+
 	// pvcPredicate filters PVC events
 	pvcPredicate = predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
@@ -0,0 +1,312 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooMigrationReconciler reconciles a OdooMigration object
+type OdooMigrationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoomigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoomigrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoomigrations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoobackups,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoodeployments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoodeployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+
+// Reconcile migrates the referenced OdooDeployment through the steps of the OdooMigration.
+// The OdooDeployment is locked through its status so its Deployment is scaled to zero and no
+// init or upgrade job runs. A backup is taken first when spec.backup.preUpgrade of the
+// OdooDeployment is set, then a job runs each step in order. The number of completed steps is
+// checkpointed in the status so an interrupted migration resumes with the next step. Once every
+// step succeeded the OdooDeployment is switched to the target image and the lock is released.
+// A failed step keeps the lock until the OdooMigration is deleted.
+func (r *OdooMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	odooMigration := &odoov1.OdooMigration{}
+	err := r.Get(ctx, req.NamespacedName, odooMigration)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("OdooMigration resource object not found.")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		logger.Error(err, "Failed to get OdooMigration")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	if odooMigration.Status.Phase == odoov1.MigrationPhaseSucceeded || odooMigration.Status.Phase == odoov1.MigrationPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	odooDeployment := &odoov1.OdooDeployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: odooMigration.Spec.OdooDeploymentRef.Name, Namespace: odooMigration.Namespace}, odooDeployment)
+	if err != nil {
+		logger.Error(err, "Failed to get OdooDeployment", "odooDeployment", odooMigration.Spec.OdooDeploymentRef.Name)
+		odooMigration.Status.Phase = odoov1.MigrationPhasePending
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentNotFound, fmt.Sprintf("Failed to get OdooDeployment %s: %v", odooMigration.Spec.OdooDeploymentRef.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooMigration)
+	}
+
+	if odooDeployment.Status.CurrentMigration != odooMigration.Name {
+		return r.lockOdooDeployment(ctx, odooMigration, odooDeployment)
+	}
+
+	// Wait for the OdooDeployment controller to scale the Deployment to zero
+	scaledDown, err := isScaledDown(ctx, r.Client, odooDeployment, &odooMigration.Status.Conditions)
+	if err != nil {
+		logger.Error(err, "Failed to get Deployment")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	} else if !scaledDown {
+		odooMigration.Status.Phase = odoov1.MigrationPhaseScalingDown
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, r.Status().Update(ctx, odooMigration)
+	}
+
+	// The backup is only taken before the first step, a resumed migration already has one
+	if odooDeployment.Spec.Backup.PreUpgrade && len(odooMigration.Status.Steps) == 0 {
+		result, ready, err := r.waitForBackup(ctx, odooMigration, odooDeployment)
+		if err != nil || !ready {
+			return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooMigration)})
+		}
+	}
+
+	step := int(odooMigration.Status.CompletedSteps)
+	if step >= len(odooMigration.Spec.Steps) {
+		return r.switchImage(ctx, odooMigration, odooDeployment)
+	}
+	if step < len(odooMigration.Status.Steps) {
+		return r.reconcileMigrationJob(ctx, odooMigration, odooDeployment, step)
+	}
+
+	job := odooMigration.GetMigrationJobTemplate(odooDeployment, step)
+	if err := odooDeployment.PatchJobPodSpec(&job.Spec.Template.Spec); err != nil {
+		logger.Error(err, fmt.Sprintf("Failed to patch the pod spec of the migration job %s", job.Name))
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.jobs of OdooDeployment %s: %v", odooDeployment.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooMigration)})
+	}
+	ctrl.SetControllerReference(odooMigration, &job, r.Scheme)
+	logger.Info(fmt.Sprintf("Creating migration job %s", job.Name), "image", odooMigration.Spec.Steps[step].Image)
+	err = r.Create(ctx, &job)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, fmt.Sprintf("error creating %s migration job.", job.Name))
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonMigrationJobCreationFailed, fmt.Sprintf("error creating %s migration job: %v", job.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooMigration)})
+	}
+
+	now := metav1.Now()
+	odooMigration.Status.Phase = odoov1.MigrationPhaseRunning
+	odooMigration.Status.Steps = append(odooMigration.Status.Steps, odoov1.MigrationStepStatus{
+		Name:      odooMigration.Spec.Steps[step].Name,
+		Image:     odooMigration.Spec.Steps[step].Image,
+		JobName:   job.Name,
+		StartedAt: &now,
+	})
+	utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorSucceeded", odoov1.ReasonMigrationJobCreated, fmt.Sprintf("Migration job %s created for step %d of %d", job.Name, step+1, len(odooMigration.Spec.Steps)), metav1.ConditionTrue)
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooMigration)
+}
+
+// lockOdooDeployment takes the migration lock of the OdooDeployment, the migration waits while
+// a restore or another migration holds the OdooDeployment
+func (r *OdooMigrationReconciler) lockOdooDeployment(ctx context.Context, odooMigration *odoov1.OdooMigration, odooDeployment *odoov1.OdooDeployment) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if len(odooDeployment.Status.InitModulesInstalled) == 0 || odooDeployment.Status.OdooConfigSecretName == "" || odooDeployment.Status.OdooDataPvcName == "" {
+		logger.Info("OdooDeployment has no initialised database yet, waiting", "odooDeployment", odooDeployment.Name)
+		odooMigration.Status.Phase = odoov1.MigrationPhasePending
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentNotReady, fmt.Sprintf("OdooDeployment %s has no initialised database yet", odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooMigration)
+	}
+
+	holder := odooDeployment.Status.CurrentMigration
+	if holder == "" && odooDeployment.Status.CurrentRestore != "" {
+		holder = odooDeployment.Status.CurrentRestore
+	}
+	if holder != "" {
+		logger.Info("OdooDeployment is locked", "holder", holder)
+		odooMigration.Status.Phase = odoov1.MigrationPhasePending
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentLocked, fmt.Sprintf("%s is already replacing the database of %s", holder, odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooMigration)
+	}
+
+	logger.Info(fmt.Sprintf("Locking OdooDeployment %s for migration", odooDeployment.Name))
+	odooDeployment.Status.CurrentMigration = odooMigration.Name
+	if err := r.Status().Update(ctx, odooDeployment); err != nil {
+		logger.Error(err, "Failed to lock OdooDeployment for migration")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	now := metav1.Now()
+	odooMigration.Status.Phase = odoov1.MigrationPhaseScalingDown
+	odooMigration.Status.SourceImage = odooDeployment.Spec.Image
+	odooMigration.Status.StartedAt = &now
+	return ctrl.Result{RequeueAfter: 10 * time.Second}, r.Status().Update(ctx, odooMigration)
+}
+
+// waitForBackup takes a backup of the OdooDeployment before the first step and returns true
+// once it succeeded. A failed backup blocks the migration until it is deleted.
+// The OdooMigration status is updated in memory only.
+func (r *OdooMigrationReconciler) waitForBackup(ctx context.Context, odooMigration *odoov1.OdooMigration, odooDeployment *odoov1.OdooDeployment) (ctrl.Result, bool, error) {
+	logger := log.FromContext(ctx)
+	odooMigration.Status.Phase = odoov1.MigrationPhaseBackingUp
+
+	if odooMigration.Status.Backup == "" {
+		backup := odooDeployment.GetPreUpgradeBackupTemplate(time.Now())
+		logger.Info(fmt.Sprintf("Creating pre-migration backup %s", backup.Name))
+		if err := r.Create(ctx, &backup); err != nil {
+			logger.Error(err, "Failed to create pre-migration backup")
+			utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonPreUpgradeBackupCreationFailed, fmt.Sprintf("Failed to create pre-migration backup %s: %v", backup.Name, err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, false, err
+		}
+		odooMigration.Status.Backup = backup.Name
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorSucceeded", odoov1.ReasonWaitingForMigrationBackup, fmt.Sprintf("Waiting for pre-migration backup %s", backup.Name), metav1.ConditionTrue)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, false, nil
+	}
+
+	backup := &odoov1.OdooBackup{}
+	err := r.Get(ctx, types.NamespacedName{Name: odooMigration.Status.Backup, Namespace: odooMigration.Namespace}, backup)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info(fmt.Sprintf("Pre-migration backup %s not found, taking a new one", odooMigration.Status.Backup))
+		odooMigration.Status.Backup = ""
+		return ctrl.Result{RequeueAfter: time.Second}, false, nil
+	} else if err != nil {
+		logger.Error(err, "Failed to get pre-migration backup")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, false, err
+	}
+
+	switch backup.Status.Phase {
+	case odoov1.BackupPhaseSucceeded:
+		return ctrl.Result{}, true, nil
+	case odoov1.BackupPhaseFailed:
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonMigrationBackupFailed, fmt.Sprintf("Pre-migration backup %s failed, delete it to take a new one", backup.Name), metav1.ConditionFalse)
+		return ctrl.Result{}, false, nil
+	}
+	logger.Info(fmt.Sprintf("Waiting for pre-migration backup %s", backup.Name))
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, false, nil
+}
+
+// reconcileMigrationJob follows the job of the step at index, on success the step is checkpointed
+func (r *OdooMigrationReconciler) reconcileMigrationJob(ctx context.Context, odooMigration *odoov1.OdooMigration, odooDeployment *odoov1.OdooDeployment, index int) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	stepStatus := &odooMigration.Status.Steps[index]
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: stepStatus.JobName, Namespace: odooMigration.Namespace}, job)
+	if err != nil && errors.IsNotFound(err) {
+		// The step may have partially run, it is not safe to run it again
+		logger.Info("Migration job not found", "job", stepStatus.JobName)
+		now := metav1.Now()
+		odooMigration.Status.Phase = odoov1.MigrationPhaseFailed
+		odooMigration.Status.CompletedAt = &now
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonMigrationJobFailed, fmt.Sprintf("Migration job %s of step %d not found%s", stepStatus.JobName, index+1, migrationRollbackHint(odooMigration)), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, odooMigration)
+	} else if err != nil {
+		logger.Error(err, "Failed to get migration job", "job", stepStatus.JobName)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	if job.Status.Succeeded > 0 {
+		logger.Info(fmt.Sprintf("Migration step %d succeeded", index+1), "job", job.Name)
+		now := metav1.Now()
+		stepStatus.CompletedAt = &now
+		odooMigration.Status.CompletedSteps = int32(index + 1)
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorSucceeded", odoov1.ReasonMigrationStepSucceeded, fmt.Sprintf("Step %d of %d succeeded", index+1, len(odooMigration.Spec.Steps)), metav1.ConditionTrue)
+		return ctrl.Result{RequeueAfter: time.Second}, r.Status().Update(ctx, odooMigration)
+	} else if utils.IsJobFailed(job) {
+		now := metav1.Now()
+		odooMigration.Status.Phase = odoov1.MigrationPhaseFailed
+		odooMigration.Status.CompletedAt = &now
+		utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonMigrationJobFailed, fmt.Sprintf("Migration job %s of step %d failed, OdooDeployment %s stays scaled down until the OdooMigration is deleted%s", job.Name, index+1, odooDeployment.Name, migrationRollbackHint(odooMigration)), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, odooMigration)
+	}
+
+	logger.Info("Migration job still running, requeueing", "job", job.Name)
+	return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+}
+
+// switchImage switches the OdooDeployment to the target image once every step succeeded and
+// releases the migration lock. The database is recorded as migrated with the target image so
+// the OdooDeployment does not upgrade it again.
+func (r *OdooMigrationReconciler) switchImage(ctx context.Context, odooMigration *odoov1.OdooMigration, odooDeployment *odoov1.OdooDeployment) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if odooDeployment.Spec.Image != odooMigration.Spec.TargetImage {
+		logger.Info(fmt.Sprintf("Switching OdooDeployment %s to image %s", odooDeployment.Name, odooMigration.Spec.TargetImage))
+		odooDeployment.Spec.Image = odooMigration.Spec.TargetImage
+		if err := r.Update(ctx, odooDeployment); err != nil {
+			logger.Error(err, "Failed to switch the image of the OdooDeployment")
+			utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedSwitchImage, fmt.Sprintf("Failed to switch OdooDeployment %s to image %s: %v", odooDeployment.Name, odooMigration.Spec.TargetImage, err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooMigration)})
+		}
+	}
+
+	now := metav1.Now()
+	odooDeployment.Status.LastUpgrade = odoov1.OdooUpgrade{
+		Image:       odooMigration.Spec.TargetImage,
+		Modules:     []string{"all"},
+		Token:       odooDeployment.Spec.Upgrade.Token,
+		CompletedAt: &now,
+	}
+	odooDeployment.Status.CurrentMigration = ""
+	utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonMigrationSucceeded, fmt.Sprintf("OdooMigration %s succeeded", odooMigration.Name), metav1.ConditionTrue)
+	if err := r.Status().Update(ctx, odooDeployment); err != nil {
+		logger.Error(err, "Failed to unlock OdooDeployment")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	odooMigration.Status.Phase = odoov1.MigrationPhaseSucceeded
+	odooMigration.Status.CompletedAt = &now
+	utils.UpdateStatus(&odooMigration.Status.Conditions, "OperatorSucceeded", odoov1.ReasonMigrationSucceeded, fmt.Sprintf("Migrated OdooDeployment %s to image %s", odooDeployment.Name, odooMigration.Spec.TargetImage), metav1.ConditionTrue)
+	return ctrl.Result{}, r.Status().Update(ctx, odooMigration)
+}
+
+// migrationRollbackHint returns the part of a failed migration message pointing to its backup
+func migrationRollbackHint(odooMigration *odoov1.OdooMigration) string {
+	if odooMigration.Status.Backup == "" {
+		return ""
+	}
+	return fmt.Sprintf(", restore OdooBackup %s to roll back", odooMigration.Status.Backup)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OdooMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&odoov1.OdooMigration{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
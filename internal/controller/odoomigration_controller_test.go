@@ -0,0 +1,290 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+)
+
+var _ = Describe("OdooMigration Controller", func() {
+	Context("When the referenced OdooDeployment does not exist", func() {
+		const resourceName = "test-migration-missing-deployment"
+		const resourceNamespace = "default"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: resourceNamespace,
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind OdooMigration")
+			err := k8sClient.Get(ctx, typeNamespacedName, &odoov1.OdooMigration{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &odoov1.OdooMigration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: resourceNamespace,
+					},
+					Spec: odoov1.OdooMigrationSpec{
+						OdooDeploymentRef: corev1.LocalObjectReference{Name: "does-not-exist"},
+						Steps:             []odoov1.MigrationStep{{Name: "18.0", Image: "openupgrade:18.0"}},
+						TargetImage:       "odoo:18.0",
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &odoov1.OdooMigration{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance OdooMigration")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should keep the migration pending", func() {
+			controllerReconciler := &OdooMigrationReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			migration := &odoov1.OdooMigration{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, migration)).To(Succeed())
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhasePending))
+			Expect(migration.Status.Steps).To(BeEmpty())
+		})
+	})
+
+	Context("When migrating an OdooDeployment through two steps", func() {
+		const resourceNamespace = "default"
+
+		var (
+			ctx                  = context.Background()
+			controllerReconciler *OdooMigrationReconciler
+			odooDeploymentName   types.NamespacedName
+			migrationName        types.NamespacedName
+		)
+
+		reconcileMigration := func() *odoov1.OdooMigration {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: migrationName})
+			Expect(err).NotTo(HaveOccurred())
+			migration := &odoov1.OdooMigration{}
+			Expect(k8sClient.Get(ctx, migrationName, migration)).To(Succeed())
+			return migration
+		}
+
+		getOdooDeployment := func() *odoov1.OdooDeployment {
+			od := &odoov1.OdooDeployment{}
+			Expect(k8sClient.Get(ctx, odooDeploymentName, od)).To(Succeed())
+			return od
+		}
+
+		getStepJob := func(migration *odoov1.OdooMigration, index int) (*batchv1.Job, error) {
+			job := &batchv1.Job{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: migration.GetMigrationJobName(index), Namespace: resourceNamespace}, job)
+			return job, err
+		}
+
+		// runStep reconciles the migration until the step at index succeeded
+		runStep := func(index int) {
+			migration := reconcileMigration()
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhaseRunning))
+			Expect(migration.Status.Steps).To(HaveLen(index + 1))
+			Expect(migration.Status.Steps[index].Image).To(Equal(migration.Spec.Steps[index].Image))
+			job, err := getStepJob(migration, index)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(migration.Spec.Steps[index].Image))
+
+			By("waiting for the job of the step")
+			migration = reconcileMigration()
+			Expect(migration.Status.CompletedSteps).To(Equal(int32(index)))
+
+			setJobSucceeded(ctx, job)
+			migration = reconcileMigration()
+			Expect(migration.Status.CompletedSteps).To(Equal(int32(index + 1)))
+			Expect(migration.Status.Steps[index].CompletedAt).NotTo(BeNil())
+			Expect(meta.FindStatusCondition(migration.Status.Conditions, "OperatorSucceeded").Reason).To(Equal(odoov1.ReasonMigrationStepSucceeded))
+		}
+
+		// expectImageSwitched reconciles the migration once every step succeeded
+		expectImageSwitched := func() {
+			migration := reconcileMigration()
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhaseSucceeded))
+			Expect(migration.Status.CompletedAt).NotTo(BeNil())
+
+			od := getOdooDeployment()
+			Expect(od.Spec.Image).To(Equal("odoo:18.0"))
+			Expect(od.Status.CurrentMigration).To(BeEmpty())
+			Expect(od.Status.LastUpgrade.Image).To(Equal("odoo:18.0"))
+			Expect(od.Status.LastUpgrade.Modules).To(Equal([]string{"all"}))
+		}
+
+		BeforeEach(func() {
+			n := atomic.AddInt64(&lockSpecCounter, 1)
+			name := fmt.Sprintf("test-migration-%d", n)
+			odooDeploymentName = types.NamespacedName{Name: name, Namespace: resourceNamespace}
+			migrationName = types.NamespacedName{Name: name + "-migration", Namespace: resourceNamespace}
+			controllerReconciler = &OdooMigrationReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			By("creating the OdooDeployment taking a backup before upgrades")
+			odooDeployment := newLockedOdooDeployment(name, resourceNamespace, "odoo:16.0")
+			odooDeployment.Spec.Backup.PreUpgrade = true
+			createLockedOdooDeployment(ctx, odooDeployment)
+
+			Expect(k8sClient.Create(ctx, &odoov1.OdooMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: migrationName.Name, Namespace: resourceNamespace},
+				Spec: odoov1.OdooMigrationSpec{
+					OdooDeploymentRef: corev1.LocalObjectReference{Name: name},
+					Steps: []odoov1.MigrationStep{
+						{Name: "17.0", Image: "openupgrade:17.0"},
+						{Name: "18.0", Image: "openupgrade:18.0"},
+					},
+					TargetImage: "odoo:18.0",
+				},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			name := odooDeploymentName.Name
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace(resourceNamespace))).To(Succeed())
+			for i := range jobs.Items {
+				tryDelete(ctx, &jobs.Items[i])
+			}
+			backups := &odoov1.OdooBackupList{}
+			Expect(k8sClient.List(ctx, backups, client.InNamespace(resourceNamespace), client.MatchingLabels{odoov1.OdooDeploymentLabel: name})).To(Succeed())
+			for i := range backups.Items {
+				tryDelete(ctx, &backups.Items[i])
+			}
+			od := getOdooDeployment()
+			tryDelete(ctx, &odoov1.OdooMigration{ObjectMeta: metav1.ObjectMeta{Name: migrationName.Name, Namespace: resourceNamespace}})
+			tryDelete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: resourceNamespace}})
+			tryDelete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: od.Status.OdooDataPvcName, Namespace: resourceNamespace}})
+			tryDelete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name + "-db", Namespace: resourceNamespace}})
+			tryDelete(ctx, od)
+		})
+
+		It("should take a backup, run the steps in order and switch the image", func() {
+			By("taking the lock")
+			migration := reconcileMigration()
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhaseScalingDown))
+			Expect(migration.Status.SourceImage).To(Equal("odoo:16.0"))
+			Expect(getOdooDeployment().Status.CurrentMigration).To(Equal(migrationName.Name))
+
+			By("waiting for the Deployment to scale down")
+			migration = reconcileMigration()
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhaseScalingDown))
+			Expect(meta.FindStatusCondition(migration.Status.Conditions, "OperatorSucceeded").Reason).To(Equal(odoov1.ReasonWaitingForScaleDown))
+			scaleDownDeployment(ctx, odooDeploymentName)
+
+			By("waiting for the pre-migration backup")
+			migration = reconcileMigration()
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhaseBackingUp))
+			Expect(migration.Status.Backup).NotTo(BeEmpty())
+			migration = reconcileMigration()
+			Expect(migration.Status.Phase).To(Equal(odoov1.MigrationPhaseBackingUp))
+			Expect(migration.Status.Steps).To(BeEmpty())
+			_, err := getStepJob(migration, 0)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			backup := &odoov1.OdooBackup{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: migration.Status.Backup, Namespace: resourceNamespace}, backup)).To(Succeed())
+			backup.Status.Phase = odoov1.BackupPhaseSucceeded
+			Expect(k8sClient.Status().Update(ctx, backup)).To(Succeed())
+
+			By("running the first step")
+			runStep(0)
+			Expect(getOdooDeployment().Spec.Image).To(Equal("odoo:16.0"))
+
+			By("running the second step")
+			runStep(1)
+
+			By("switching the OdooDeployment to the target image")
+			expectImageSwitched()
+		})
+
+		It("should resume after the completed steps", func() {
+			By("recording the first step as completed, as before a restart of the operator")
+			od := getOdooDeployment()
+			od.Status.CurrentMigration = migrationName.Name
+			Expect(k8sClient.Status().Update(ctx, od)).To(Succeed())
+			scaleDownDeployment(ctx, odooDeploymentName)
+
+			migration := &odoov1.OdooMigration{}
+			Expect(k8sClient.Get(ctx, migrationName, migration)).To(Succeed())
+			now := metav1.Now()
+			migration.Status.Phase = odoov1.MigrationPhaseRunning
+			migration.Status.SourceImage = "odoo:16.0"
+			migration.Status.StartedAt = &now
+			migration.Status.CompletedSteps = 1
+			migration.Status.Steps = []odoov1.MigrationStepStatus{{
+				Name:        "17.0",
+				Image:       "openupgrade:17.0",
+				JobName:     migration.GetMigrationJobName(0),
+				StartedAt:   &now,
+				CompletedAt: &now,
+			}}
+			Expect(k8sClient.Status().Update(ctx, migration)).To(Succeed())
+
+			By("running the second step only")
+			migration = reconcileMigration()
+			Expect(migration.Status.Backup).To(BeEmpty())
+			Expect(migration.Status.Steps).To(HaveLen(2))
+			_, err := getStepJob(migration, 0)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			job, err := getStepJob(migration, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			setJobSucceeded(ctx, job)
+			migration = reconcileMigration()
+			Expect(migration.Status.CompletedSteps).To(Equal(int32(2)))
+
+			By("switching the OdooDeployment to the target image")
+			expectImageSwitched()
+		})
+	})
+})
@@ -0,0 +1,421 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/objectstore"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooRestoreReconciler reconciles a OdooRestore object
+type OdooRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// UploaderImage is the image of the container downloading backups from S3
+	UploaderImage string
+}
+
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoorestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoorestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoorestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoobackups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoomigrations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoodeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=odoo.abugharbia.com,resources=odoodeployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=get;list;watch
+
+// Reconcile restores a backup into the referenced OdooDeployment. The OdooDeployment is locked
+// through its status so its Deployment is scaled to zero and no init job runs, the database jobs
+// started before the lock are deleted, then a job replaces the database and the filestore.
+// Once the job succeeded the modules of the backup are recorded as installed and the lock
+// is released. A failed restore keeps the lock until the OdooRestore is deleted.
+func (r *OdooRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	odooRestore := &odoov1.OdooRestore{}
+	err := r.Get(ctx, req.NamespacedName, odooRestore)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("OdooRestore resource object not found.")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		logger.Error(err, "Failed to get OdooRestore")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	if odooRestore.Status.Phase == odoov1.RestorePhaseSucceeded || odooRestore.Status.Phase == odoov1.RestorePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	odooDeployment := &odoov1.OdooDeployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: odooRestore.Spec.OdooDeploymentRef.Name, Namespace: odooRestore.Namespace}, odooDeployment)
+	if err != nil {
+		logger.Error(err, "Failed to get OdooDeployment", "odooDeployment", odooRestore.Spec.OdooDeploymentRef.Name)
+		odooRestore.Status.Phase = odoov1.RestorePhasePending
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentNotFound, fmt.Sprintf("Failed to get OdooDeployment %s: %v", odooRestore.Spec.OdooDeploymentRef.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooRestore)
+	}
+
+	if odooRestore.Status.JobName != "" {
+		return r.reconcileRestoreJob(ctx, odooRestore, odooDeployment)
+	}
+
+	source, result, err := r.getRestoreSourceLocation(ctx, odooRestore)
+	if err != nil || !result.IsZero() || odooRestore.Status.Phase == odoov1.RestorePhaseFailed {
+		return result, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+	}
+
+	if odooDeployment.Status.OdooDataPvcName == "" {
+		logger.Info("OdooDeployment has no filestore PVC yet, waiting", "odooDeployment", odooDeployment.Name)
+		odooRestore.Status.Phase = odoov1.RestorePhasePending
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentNotReady, fmt.Sprintf("OdooDeployment %s has no filestore PVC yet", odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooRestore)
+	}
+
+	// Take the restore lock of the OdooDeployment, a failed migration is rolled back by the restore
+	migrationFailed, err := r.isMigrationFailed(ctx, odooDeployment)
+	if err != nil {
+		logger.Error(err, "Failed to get OdooMigration", "odooMigration", odooDeployment.Status.CurrentMigration)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+	if odooDeployment.Status.CurrentMigration != "" && !migrationFailed {
+		logger.Info("A migration is in progress", "odooMigration", odooDeployment.Status.CurrentMigration)
+		odooRestore.Status.Phase = odoov1.RestorePhasePending
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooDeploymentLocked, fmt.Sprintf("OdooMigration %s is migrating %s", odooDeployment.Status.CurrentMigration, odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooRestore)
+	} else if odooDeployment.Status.CurrentRestore != "" && odooDeployment.Status.CurrentRestore != odooRestore.Name {
+		logger.Info("Another restore is in progress", "odooRestore", odooDeployment.Status.CurrentRestore)
+		odooRestore.Status.Phase = odoov1.RestorePhasePending
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonRestoreInProgress, fmt.Sprintf("OdooRestore %s is already restoring into %s", odooDeployment.Status.CurrentRestore, odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooRestore)
+	} else if odooDeployment.Status.CurrentRestore == "" {
+		logger.Info(fmt.Sprintf("Locking OdooDeployment %s for restore", odooDeployment.Name))
+		odooDeployment.Status.CurrentRestore = odooRestore.Name
+		if err := r.Status().Update(ctx, odooDeployment); err != nil {
+			logger.Error(err, "Failed to lock OdooDeployment for restore")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+	}
+
+	// Wait for the OdooDeployment controller to scale the Deployment to zero
+	scaledDown, err := isScaledDown(ctx, r.Client, odooDeployment, &odooRestore.Status.Conditions)
+	if err != nil {
+		logger.Error(err, "Failed to get Deployment")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	} else if !scaledDown {
+		odooRestore.Status.Phase = odoov1.RestorePhaseScalingDown
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, r.Status().Update(ctx, odooRestore)
+	}
+
+	// Wait for the database jobs started before the lock, they would change the database being restored
+	jobsStopped, err := r.stopDatabaseJobs(ctx, odooDeployment)
+	if err != nil {
+		logger.Error(err, "Failed to delete the database jobs of OdooDeployment", "odooDeployment", odooDeployment.Name)
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteDatabaseJobs, fmt.Sprintf("Failed to delete the database jobs of OdooDeployment %s: %v", odooDeployment.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+	} else if !jobsStopped {
+		logger.Info("Waiting for the database jobs to stop", "odooDeployment", odooDeployment.Name)
+		odooRestore.Status.Phase = odoov1.RestorePhaseScalingDown
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorSucceeded", odoov1.ReasonWaitingForDatabaseJobs, fmt.Sprintf("Waiting for the database jobs of OdooDeployment %s to stop", odooDeployment.Name), metav1.ConditionTrue)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, r.Status().Update(ctx, odooRestore)
+	} else if hasDatabaseJobs(odooDeployment) {
+		odooDeployment.Status.CurrentInitJob = odoov1.DBInitjob{}
+		odooDeployment.Status.CurrentUninstallJob = odoov1.DBInitjob{}
+		odooDeployment.Status.CurrentUpgradeJob = odoov1.DBUpgradeJob{}
+		if err := r.Status().Update(ctx, odooDeployment); err != nil {
+			logger.Error(err, "Failed to clear the database jobs of OdooDeployment")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+	}
+
+	dbConnectionDetails, err := odooDeployment.Spec.Database.GetDbConnectionDetails(r.Client, ctx, odooDeployment.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get database connection details")
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonDbConnectionDetailsFailed, fmt.Sprintf("Failed to get database connection details: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+	}
+
+	filestoreNode, err := utils.GetPvcNodeName(r.Client, ctx, odooDeployment.Namespace, odooDeployment.Status.OdooDataPvcName)
+	if err != nil {
+		logger.Error(err, "Failed to get the node of the filestore", "pvc", odooDeployment.Status.OdooDataPvcName)
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetFilestoreNode, fmt.Sprintf("Failed to get the node PVC %s is attached to: %v", odooDeployment.Status.OdooDataPvcName, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+	}
+
+	job := odooRestore.GetRestoreJobTemplate(odooDeployment, dbConnectionDetails, source, filestoreNode, r.UploaderImage)
+	if err := odooDeployment.PatchJobPodSpec(&job.Spec.Template.Spec); err != nil {
+		logger.Error(err, fmt.Sprintf("Failed to patch the pod spec of the restore job %s", job.Name))
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.jobs of OdooDeployment %s: %v", odooDeployment.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+	}
+	ctrl.SetControllerReference(odooRestore, &job, r.Scheme)
+	logger.Info(fmt.Sprintf("Creating restore job %s", job.Name))
+	err = r.Create(ctx, &job)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, fmt.Sprintf("error creating %s restore job.", job.Name))
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonRestoreJobCreationFailed, fmt.Sprintf("error creating %s restore job: %v", job.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+	}
+
+	now := metav1.Now()
+	odooRestore.Status.Phase = odoov1.RestorePhaseRunning
+	odooRestore.Status.JobName = job.Name
+	odooRestore.Status.StartedAt = &now
+	odooRestore.Status.RestoredModules = source.Modules
+	odooRestore.Status.OdooImage = source.OdooImage
+	utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorSucceeded", odoov1.ReasonRestoreJobCreated, fmt.Sprintf("Restore job %s created", job.Name), metav1.ConditionTrue)
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, odooRestore)
+}
+
+// getRestoreSourceLocation resolves the source of the restore. A non zero ctrl.Result is
+// returned while the source is not ready yet, an invalid source fails the restore.
+// The image and the modules of a backup in an object store are read from its manifest.json.
+// The OdooRestore status is updated in memory only.
+func (r *OdooRestoreReconciler) getRestoreSourceLocation(ctx context.Context, odooRestore *odoov1.OdooRestore) (odoov1.RestoreSourceLocation, ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	source := odooRestore.Spec.Source
+
+	if source.OdooBackupName == "" {
+		if source.Path == "" || source.S3 == nil {
+			odooRestore.Status.Phase = odoov1.RestorePhaseFailed
+			utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonRestoreSourceInvalid, "Either odooBackupName or path and s3 must be set", metav1.ConditionFalse)
+			return odoov1.RestoreSourceLocation{}, ctrl.Result{}, nil
+		}
+		if _, _, err := objectstore.ParseEndpoint(source.S3.Endpoint); err != nil {
+			odooRestore.Status.Phase = odoov1.RestorePhaseFailed
+			utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonS3ConfigInvalid, fmt.Sprintf("Invalid S3 endpoint %q: %v", source.S3.Endpoint, err), metav1.ConditionFalse)
+			return odoov1.RestoreSourceLocation{}, ctrl.Result{}, nil
+		}
+		location := odoov1.RestoreSourceLocation{
+			S3:        source.S3,
+			KeyPrefix: objectstore.Key(source.Path),
+		}
+
+		manifestKey := objectstore.Key(location.KeyPrefix, odoov1.BackupManifestFileName)
+		data, err := r.getObject(ctx, odooRestore.Namespace, source.S3, manifestKey)
+		if err != nil {
+			logger.Error(err, "Failed to get backup manifest", "key", manifestKey)
+			odooRestore.Status.Phase = odoov1.RestorePhasePending
+			utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetBackupManifest, fmt.Sprintf("Failed to get backup manifest %s: %v", manifestKey, err), metav1.ConditionFalse)
+			return odoov1.RestoreSourceLocation{}, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		manifest, err := odoov1.ParseBackupManifest(string(data))
+		if err != nil {
+			odooRestore.Status.Phase = odoov1.RestorePhaseFailed
+			utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonBackupManifestInvalid, fmt.Sprintf("Failed to parse backup manifest %s: %v", manifestKey, err), metav1.ConditionFalse)
+			return odoov1.RestoreSourceLocation{}, ctrl.Result{}, nil
+		}
+		location.OdooImage = manifest.OdooImage
+		location.Modules = manifest.Modules
+		return location, ctrl.Result{}, nil
+	}
+
+	odooBackup := &odoov1.OdooBackup{}
+	err := r.Get(ctx, types.NamespacedName{Name: source.OdooBackupName, Namespace: odooRestore.Namespace}, odooBackup)
+	if err != nil {
+		logger.Error(err, "Failed to get OdooBackup", "odooBackup", source.OdooBackupName)
+		odooRestore.Status.Phase = odoov1.RestorePhasePending
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooBackupNotFound, fmt.Sprintf("Failed to get OdooBackup %s: %v", source.OdooBackupName, err), metav1.ConditionFalse)
+		return odoov1.RestoreSourceLocation{}, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	switch odooBackup.Status.Phase {
+	case odoov1.BackupPhaseSucceeded:
+		return odooBackup.GetRestoreSourceLocation(), ctrl.Result{}, nil
+	case odoov1.BackupPhaseFailed:
+		odooRestore.Status.Phase = odoov1.RestorePhaseFailed
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooBackupNotSucceeded, fmt.Sprintf("OdooBackup %s failed", odooBackup.Name), metav1.ConditionFalse)
+		return odoov1.RestoreSourceLocation{}, ctrl.Result{}, nil
+	default:
+		logger.Info("OdooBackup has not succeeded yet, waiting", "odooBackup", odooBackup.Name)
+		odooRestore.Status.Phase = odoov1.RestorePhasePending
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonOdooBackupNotSucceeded, fmt.Sprintf("OdooBackup %s has not succeeded yet", odooBackup.Name), metav1.ConditionFalse)
+		return odoov1.RestoreSourceLocation{}, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+}
+
+// reconcileRestoreJob follows the restore job, on success the OdooDeployment is unlocked with
+// the modules of the backup recorded as installed
+func (r *OdooRestoreReconciler) reconcileRestoreJob(ctx context.Context, odooRestore *odoov1.OdooRestore, odooDeployment *odoov1.OdooDeployment) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: odooRestore.Status.JobName, Namespace: odooRestore.Namespace}, job)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Restore job not found", "job", odooRestore.Status.JobName)
+		odooRestore.Status.Phase = odoov1.RestorePhaseFailed
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonRestoreJobFailed, fmt.Sprintf("Restore job %s not found", odooRestore.Status.JobName), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, odooRestore)
+	} else if err != nil {
+		logger.Error(err, "Failed to get restore job")
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+	}
+
+	if job.Status.Succeeded > 0 {
+		// The database jobs must not apply their result to the restored database
+		jobsStopped, err := r.stopDatabaseJobs(ctx, odooDeployment)
+		if err != nil {
+			logger.Error(err, "Failed to delete the database jobs of OdooDeployment", "odooDeployment", odooDeployment.Name)
+			utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteDatabaseJobs, fmt.Sprintf("Failed to delete the database jobs of OdooDeployment %s: %v", odooDeployment.Name, err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, odooRestore)})
+		} else if !jobsStopped {
+			logger.Info("Waiting for the database jobs to stop", "odooDeployment", odooDeployment.Name)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+
+		logger.Info(fmt.Sprintf("Restore succeeded, unlocking OdooDeployment %s", odooDeployment.Name), "modules", odooRestore.Status.RestoredModules)
+		odooDeployment.Status.InitModulesInstalled = odooRestore.Status.RestoredModules
+		if odooDeployment.Status.InitModulesInstalled == nil {
+			odooDeployment.Status.InitModulesInstalled = []string{}
+		}
+		odooDeployment.Status.CurrentInitJob = odoov1.DBInitjob{}
+		odooDeployment.Status.CurrentUninstallJob = odoov1.DBInitjob{}
+		odooDeployment.Status.PreUpgradeBackup = ""
+		odooDeployment.Status.CurrentMigration = ""
+		// The restored database is upgraded again when the backup was taken with another image
+		odooDeployment.Status.CurrentUpgradeJob = odoov1.DBUpgradeJob{}
+		odooDeployment.Status.LastUpgrade = odoov1.OdooUpgrade{
+			Image:   odooRestore.Status.OdooImage,
+			Modules: odooDeployment.Spec.Upgrade.Modules,
+			Token:   odooDeployment.Spec.Upgrade.Token,
+		}
+		if odooDeployment.Status.CurrentRestore == odooRestore.Name {
+			odooDeployment.Status.CurrentRestore = ""
+		}
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonRestoreSucceeded, fmt.Sprintf("OdooRestore %s succeeded", odooRestore.Name), metav1.ConditionTrue)
+		if err := r.Status().Update(ctx, odooDeployment); err != nil {
+			logger.Error(err, "Failed to unlock OdooDeployment")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+
+		now := metav1.Now()
+		odooRestore.Status.Phase = odoov1.RestorePhaseSucceeded
+		odooRestore.Status.CompletedAt = &now
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorSucceeded", odoov1.ReasonRestoreSucceeded, fmt.Sprintf("Restore job %s succeeded", job.Name), metav1.ConditionTrue)
+		return ctrl.Result{}, r.Status().Update(ctx, odooRestore)
+	} else if job.Status.Failed > 0 && job.Status.Active == 0 && isJobFinished(job) {
+		now := metav1.Now()
+		odooRestore.Status.Phase = odoov1.RestorePhaseFailed
+		odooRestore.Status.CompletedAt = &now
+		utils.UpdateStatus(&odooRestore.Status.Conditions, "OperatorDegraded", odoov1.ReasonRestoreJobFailed, fmt.Sprintf("Restore job %s failed, OdooDeployment %s stays scaled down until the OdooRestore is deleted", job.Name, odooDeployment.Name), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, odooRestore)
+	}
+
+	logger.Info("Restore job still running, requeueing", "job", job.Name)
+	return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+}
+
+// stopDatabaseJobs deletes the init, uninstall and upgrade jobs recorded in the OdooDeployment status.
+// It returns true once the jobs are gone and none of their pods is running anymore.
+func (r *OdooRestoreReconciler) stopDatabaseJobs(ctx context.Context, odooDeployment *odoov1.OdooDeployment) (bool, error) {
+	stopped := true
+	for _, name := range []string{
+		odooDeployment.Status.CurrentInitJob.Name,
+		odooDeployment.Status.CurrentUninstallJob.Name,
+		odooDeployment.Status.CurrentUpgradeJob.Name,
+	} {
+		if name == "" {
+			continue
+		}
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: odooDeployment.Namespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		} else if err == nil {
+			log.FromContext(ctx).Info(fmt.Sprintf("Deleting database job %s before the restore", job.Name))
+			if err := utils.DeleteJob(r.Client, ctx, job); err != nil {
+				return false, err
+			}
+			stopped = false
+			continue
+		}
+
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(odooDeployment.Namespace), client.MatchingLabels{"job-name": name}); err != nil {
+			return false, err
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+				stopped = false
+			}
+		}
+	}
+	return stopped, nil
+}
+
+// hasDatabaseJobs returns true when the OdooDeployment status records an init, uninstall or upgrade job
+func hasDatabaseJobs(odooDeployment *odoov1.OdooDeployment) bool {
+	return odooDeployment.Status.CurrentInitJob.Name != "" ||
+		odooDeployment.Status.CurrentUninstallJob.Name != "" ||
+		odooDeployment.Status.CurrentUpgradeJob.Name != ""
+}
+
+// getObject reads an object from the object store with the credentials of the given namespace
+func (r *OdooRestoreReconciler) getObject(ctx context.Context, namespace string, s3 *odoov1.S3Config, key string) ([]byte, error) {
+	config, err := s3.GetObjectStoreConfig(r.Client, ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	store, err := objectstore.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetObject(ctx, key)
+}
+
+// isMigrationFailed returns true when the OdooMigration holding the OdooDeployment failed
+func (r *OdooRestoreReconciler) isMigrationFailed(ctx context.Context, odooDeployment *odoov1.OdooDeployment) (bool, error) {
+	if odooDeployment.Status.CurrentMigration == "" {
+		return false, nil
+	}
+	migration := &odoov1.OdooMigration{}
+	err := r.Get(ctx, types.NamespacedName{Name: odooDeployment.Status.CurrentMigration, Namespace: odooDeployment.Namespace}, migration)
+	if err != nil && errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return migration.Status.Phase == odoov1.MigrationPhaseFailed, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OdooRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&odoov1.OdooRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
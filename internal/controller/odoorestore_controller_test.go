@@ -0,0 +1,382 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+)
+
+// lockSpecCounter generates unique resource names per test of the restore and migration
+// controllers, envtest has no garbage collector to remove the objects a test leaves behind
+var lockSpecCounter int64
+
+// newLockedOdooDeployment returns an OdooDeployment with an initialised database, as the
+// restore and migration controllers expect it
+func newLockedOdooDeployment(name string, namespace string, image string) *odoov1.OdooDeployment {
+	return &odoov1.OdooDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: odoov1.OdooDeploymentSpec{
+			Image:    image,
+			Replicas: 1,
+			Database: odoov1.OdooDatabaseConfig{
+				Host: "my-database-host",
+				Port: 5432,
+				User: "my-db-user",
+				Name: "my-database-name",
+				PasswordFromSecret: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name + "-db"},
+					Key:                  "password",
+				},
+			},
+			OdooFilestore: odoov1.PersistentVolumeClaimSpec{
+				Size:        resource.MustParse("1Gi"),
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			},
+			Modules: []string{"base", "web"},
+		},
+	}
+}
+
+// createLockedOdooDeployment creates the OdooDeployment together with its database secret,
+// its filestore PVC and a Deployment running one replica
+func createLockedOdooDeployment(ctx context.Context, odooDeployment *odoov1.OdooDeployment) {
+	Expect(k8sClient.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: odooDeployment.Name + "-db", Namespace: odooDeployment.Namespace},
+		Data:       map[string][]byte{"password": []byte("my-secret-password")},
+	})).To(Succeed())
+	Expect(k8sClient.Create(ctx, odooDeployment)).To(Succeed())
+
+	pvc := odooDeployment.GetPvcTemplate()
+	Expect(k8sClient.Create(ctx, &pvc)).To(Succeed())
+
+	replicas := int32(1)
+	labels := map[string]string{"app": odooDeployment.Name}
+	Expect(k8sClient.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: odooDeployment.Name, Namespace: odooDeployment.Namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "odoo", Image: odooDeployment.Spec.Image}}},
+			},
+		},
+	})).To(Succeed())
+
+	odooDeployment.Status.OdooDataPvcName = pvc.Name
+	odooDeployment.Status.OdooConfigSecretName = odooDeployment.Name + "-config"
+	odooDeployment.Status.InitModulesInstalled = []string{"base", "web"}
+	odooDeployment.Status.LastUpgrade = odoov1.OdooUpgrade{Image: odooDeployment.Spec.Image, Modules: []string{"all"}}
+	Expect(k8sClient.Status().Update(ctx, odooDeployment)).To(Succeed())
+}
+
+// scaleDownDeployment does what the OdooDeployment controller does for a locked OdooDeployment
+func scaleDownDeployment(ctx context.Context, name types.NamespacedName) {
+	deployment := &appsv1.Deployment{}
+	Expect(k8sClient.Get(ctx, name, deployment)).To(Succeed())
+	replicas := int32(0)
+	deployment.Spec.Replicas = &replicas
+	Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+}
+
+// newDatabaseJob returns a job running in place of the jobs of the OdooDeployment controller
+func newDatabaseJob(name types.NamespacedName) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers:    []corev1.Container{{Name: "odoo", Image: "odoo:17"}},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
+
+// setJobSucceeded marks the job as succeeded, as the job controller does
+func setJobSucceeded(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+// setJobFailed marks the job as failed, as the job controller does once its backoff limit is reached
+func setJobFailed(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.Failed = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: batchv1.JobReasonBackoffLimitExceeded, LastTransitionTime: now},
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: batchv1.JobReasonBackoffLimitExceeded, LastTransitionTime: now},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+// tryDelete deletes the object, it may already be gone
+func tryDelete(ctx context.Context, obj client.Object) {
+	err := k8sClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
+
+var _ = Describe("OdooRestore Controller", func() {
+	Context("When the referenced OdooDeployment does not exist", func() {
+		const resourceName = "test-restore-missing-deployment"
+		const resourceNamespace = "default"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: resourceNamespace,
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind OdooRestore")
+			err := k8sClient.Get(ctx, typeNamespacedName, &odoov1.OdooRestore{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &odoov1.OdooRestore{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: resourceNamespace,
+					},
+					Spec: odoov1.OdooRestoreSpec{
+						OdooDeploymentRef: corev1.LocalObjectReference{Name: "does-not-exist"},
+						Source:            odoov1.RestoreSource{OdooBackupName: "does-not-exist"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &odoov1.OdooRestore{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance OdooRestore")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should keep the restore pending", func() {
+			controllerReconciler := &OdooRestoreReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			restore := &odoov1.OdooRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhasePending))
+			Expect(restore.Status.JobName).To(BeEmpty())
+		})
+	})
+
+	Context("When restoring an OdooBackup into an OdooDeployment", func() {
+		const resourceNamespace = "default"
+
+		var (
+			ctx                  = context.Background()
+			controllerReconciler *OdooRestoreReconciler
+			odooDeployment       *odoov1.OdooDeployment
+			odooDeploymentName   types.NamespacedName
+			restoreName          types.NamespacedName
+			initJobName          types.NamespacedName
+		)
+
+		reconcileRestore := func() *odoov1.OdooRestore {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: restoreName})
+			Expect(err).NotTo(HaveOccurred())
+			restore := &odoov1.OdooRestore{}
+			Expect(k8sClient.Get(ctx, restoreName, restore)).To(Succeed())
+			return restore
+		}
+
+		getOdooDeployment := func() *odoov1.OdooDeployment {
+			od := &odoov1.OdooDeployment{}
+			Expect(k8sClient.Get(ctx, odooDeploymentName, od)).To(Succeed())
+			return od
+		}
+
+		// startRestoreJob reconciles the restore until its job is created
+		startRestoreJob := func() *batchv1.Job {
+			restore := reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseScalingDown))
+			scaleDownDeployment(ctx, odooDeploymentName)
+			Expect(reconcileRestore().Status.JobName).To(BeEmpty())
+			restore = reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseRunning))
+
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: restore.Status.JobName, Namespace: resourceNamespace}, job)).To(Succeed())
+			return job
+		}
+
+		BeforeEach(func() {
+			n := atomic.AddInt64(&lockSpecCounter, 1)
+			name := fmt.Sprintf("test-restore-%d", n)
+			odooDeploymentName = types.NamespacedName{Name: name, Namespace: resourceNamespace}
+			restoreName = types.NamespacedName{Name: name + "-restore", Namespace: resourceNamespace}
+			initJobName = types.NamespacedName{Name: name + "-init", Namespace: resourceNamespace}
+			controllerReconciler = &OdooRestoreReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				UploaderImage: "odoo-operator:test",
+			}
+
+			By("creating the OdooDeployment with an init job in flight")
+			odooDeployment = newLockedOdooDeployment(name, resourceNamespace, "odoo:17")
+			createLockedOdooDeployment(ctx, odooDeployment)
+			Expect(k8sClient.Create(ctx, newDatabaseJob(initJobName))).To(Succeed())
+			odooDeployment.Status.CurrentInitJob = odoov1.DBInitjob{Name: initJobName.Name, Namespace: resourceNamespace, Modules: []string{"web"}}
+			Expect(k8sClient.Status().Update(ctx, odooDeployment)).To(Succeed())
+
+			By("creating a succeeded OdooBackup")
+			backup := &odoov1.OdooBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: name + "-backup", Namespace: resourceNamespace},
+				Spec:       odoov1.OdooBackupSpec{OdooDeploymentRef: corev1.LocalObjectReference{Name: name}},
+			}
+			Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+			backup.Status.Phase = odoov1.BackupPhaseSucceeded
+			backup.Status.PvcName = name + "-backup"
+			backup.Status.OdooImage = "odoo:16"
+			backup.Status.InstalledModules = []string{"base", "web", "sale"}
+			Expect(k8sClient.Status().Update(ctx, backup)).To(Succeed())
+
+			Expect(k8sClient.Create(ctx, &odoov1.OdooRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: restoreName.Name, Namespace: resourceNamespace},
+				Spec: odoov1.OdooRestoreSpec{
+					OdooDeploymentRef: corev1.LocalObjectReference{Name: name},
+					Source:            odoov1.RestoreSource{OdooBackupName: backup.Name},
+				},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace(resourceNamespace))).To(Succeed())
+			for i := range jobs.Items {
+				tryDelete(ctx, &jobs.Items[i])
+			}
+			name := odooDeploymentName.Name
+			tryDelete(ctx, &odoov1.OdooRestore{ObjectMeta: metav1.ObjectMeta{Name: restoreName.Name, Namespace: resourceNamespace}})
+			tryDelete(ctx, &odoov1.OdooBackup{ObjectMeta: metav1.ObjectMeta{Name: name + "-backup", Namespace: resourceNamespace}})
+			tryDelete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: resourceNamespace}})
+			tryDelete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: odooDeployment.Status.OdooDataPvcName, Namespace: resourceNamespace}})
+			tryDelete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name + "-db", Namespace: resourceNamespace}})
+			tryDelete(ctx, &odoov1.OdooDeployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: resourceNamespace}})
+		})
+
+		It("should lock the OdooDeployment and stop its database jobs before creating the job", func() {
+			By("taking the lock")
+			restore := reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseScalingDown))
+			Expect(meta.FindStatusCondition(restore.Status.Conditions, "OperatorSucceeded").Reason).To(Equal(odoov1.ReasonWaitingForScaleDown))
+			Expect(getOdooDeployment().Status.CurrentRestore).To(Equal(restoreName.Name))
+
+			By("waiting for the Deployment to scale down")
+			restore = reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseScalingDown))
+			Expect(restore.Status.JobName).To(BeEmpty())
+			scaleDownDeployment(ctx, odooDeploymentName)
+
+			By("deleting the init job started before the lock")
+			restore = reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseScalingDown))
+			Expect(meta.FindStatusCondition(restore.Status.Conditions, "OperatorSucceeded").Reason).To(Equal(odoov1.ReasonWaitingForDatabaseJobs))
+			Expect(restore.Status.JobName).To(BeEmpty())
+			err := k8sClient.Get(ctx, initJobName, &batchv1.Job{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("creating the restore job once the init job is gone")
+			restore = reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseRunning))
+			Expect(restore.Status.JobName).NotTo(BeEmpty())
+			Expect(restore.Status.RestoredModules).To(Equal([]string{"base", "web", "sale"}))
+			Expect(restore.Status.OdooImage).To(Equal("odoo:16"))
+			Expect(getOdooDeployment().Status.CurrentInitJob.Name).To(BeEmpty())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: restore.Status.JobName, Namespace: resourceNamespace}, &batchv1.Job{})).To(Succeed())
+		})
+
+		It("should unlock the OdooDeployment with the modules of the backup once the job succeeded", func() {
+			job := startRestoreJob()
+			setJobSucceeded(ctx, job)
+
+			restore := reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseSucceeded))
+			Expect(restore.Status.CompletedAt).NotTo(BeNil())
+
+			od := getOdooDeployment()
+			Expect(od.Status.CurrentRestore).To(BeEmpty())
+			Expect(od.Status.InitModulesInstalled).To(Equal([]string{"base", "web", "sale"}))
+			Expect(od.Status.LastUpgrade.Image).To(Equal("odoo:16"))
+			Expect(od.Status.CurrentInitJob.Name).To(BeEmpty())
+			Expect(meta.FindStatusCondition(od.Status.Conditions, "OperatorSucceeded").Reason).To(Equal(odoov1.ReasonRestoreSucceeded))
+		})
+
+		It("should keep the OdooDeployment locked when the job failed", func() {
+			job := startRestoreJob()
+			setJobFailed(ctx, job)
+
+			restore := reconcileRestore()
+			Expect(restore.Status.Phase).To(Equal(odoov1.RestorePhaseFailed))
+			Expect(meta.FindStatusCondition(restore.Status.Conditions, "OperatorDegraded").Reason).To(Equal(odoov1.ReasonRestoreJobFailed))
+
+			od := getOdooDeployment()
+			Expect(od.Status.CurrentRestore).To(Equal(restoreName.Name))
+			Expect(od.Status.InitModulesInstalled).To(Equal([]string{"base", "web"}))
+
+			By("keeping the lock on the next reconcile")
+			Expect(reconcileRestore().Status.Phase).To(Equal(odoov1.RestorePhaseFailed))
+			Expect(getOdooDeployment().Status.CurrentRestore).To(Equal(restoreName.Name))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+// OdooCertificateReconciler creates the cert-manager Certificate of spec.exposure.certificate for the
+// hostnames of the Ingress and the HTTPRoute, deletes it once spec.exposure.certificate is unset and
+// mirrors its Ready condition in the CertificateReady condition. cert-manager is only required in
+// the cluster while spec.exposure.certificate is set.
+type OdooCertificateReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+func (r *OdooCertificateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (*unstructured.Unstructured, error) {
+	logger := log.FromContext(ctx)
+
+	configured := r.OdooDeployment.Spec.Exposure != nil && r.OdooDeployment.Spec.Exposure.Certificate != nil
+	requested := configured && len(r.OdooDeployment.GetHostnames()) > 0
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(odoov1.CertificateGroupVersionKind)
+	createCertificate := false
+	certificateNamespacedName := types.NamespacedName{
+		Name:      r.OdooDeployment.GetCertificateName(),
+		Namespace: r.OdooDeployment.Namespace,
+	}
+	err := r.Get(ctx, certificateNamespacedName, certificate)
+	if err != nil && (errors.IsNotFound(err) || (!requested && meta.IsNoMatchError(err))) {
+		createCertificate = true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error getting %s certificate.", certificateNamespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetCertificate, fmt.Sprintf("error getting %s certificate: %v", certificateNamespacedName.Name, err), metav1.ConditionFalse)
+		return certificate, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	if !requested {
+		if configured {
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, odoov1.ConditionCertificateReady, odoov1.ReasonCertificateNoHostnames, "No certificate is issued, neither spec.ingress.hosts nor spec.exposure.gateway.hostnames is set", metav1.ConditionFalse)
+		} else {
+			meta.RemoveStatusCondition(&r.OdooDeployment.Status.Conditions, odoov1.ConditionCertificateReady)
+		}
+		// Only delete a Certificate this OdooDeployment created
+		if createCertificate || !metav1.IsControlledBy(certificate, r.OdooDeployment) {
+			return nil, nil
+		}
+		logger.Info(fmt.Sprintf("Deleting certificate %s", certificateNamespacedName.Name))
+		if err := r.Delete(ctx, certificate); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, fmt.Sprintf("error deleting %s certificate.", certificateNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteCertificate, fmt.Sprintf("error deleting %s certificate: %v", certificateNamespacedName.Name, err), metav1.ConditionFalse)
+			return certificate, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+		return nil, nil
+	}
+
+	certificateTemplate := r.OdooDeployment.GetCertificateTemplate()
+
+	if createCertificate {
+		logger.Info(fmt.Sprintf("Creating a new certificate for %s", certificateNamespacedName.Name))
+		certificate = certificateTemplate
+		ctrl.SetControllerReference(r.OdooDeployment, certificate, r.Scheme)
+		err = r.Create(ctx, certificate)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("error creating %s certificate.", certificateNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreateCertificate, fmt.Sprintf("error creating %s certificate: %v", certificateNamespacedName.Name, err), metav1.ConditionFalse)
+			return certificate, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	} else if diff := cmp.Diff(certificate.Object["spec"], certificateTemplate.Object["spec"]); diff != "" {
+		logger.V(1).Info(fmt.Sprintf("Diff: %s", diff))
+		logger.Info(fmt.Sprintf("Updating certificate %s spec", certificateNamespacedName.Name))
+		certificate.Object["spec"] = certificateTemplate.Object["spec"]
+		ctrl.SetControllerReference(r.OdooDeployment, certificate, r.Scheme)
+		err = r.Update(ctx, certificate)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("error updating %s certificate.", certificateNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedUpdateCertificate, fmt.Sprintf("error updating %s certificate: %v", certificateNamespacedName.Name, err), metav1.ConditionFalse)
+			return certificate, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	}
+
+	ready, err := odoov1.GetCertificateReadyCondition(certificate)
+	if err != nil {
+		logger.Info(fmt.Sprintf("Could not read the status of certificate %s: %v", certificateNamespacedName.Name, err))
+		ready = metav1.Condition{Status: metav1.ConditionUnknown, Reason: odoov1.ReasonCertificatePending, Message: err.Error()}
+	}
+	utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, odoov1.ConditionCertificateReady, ready.Reason, ready.Message, ready.Status)
+
+	return certificate, nil
+}
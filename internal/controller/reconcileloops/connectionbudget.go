@@ -0,0 +1,89 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/database"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooConnectionBudgetReconciler compares the connections the pods of the OdooDeployment may open
+// with the connections the database server accepts, spec.database.maxConnections or the ones queried
+// from the server once per generation. The outcome is recorded in Status.ConnectionBudget and the
+// ConnectionBudget condition, an exceeded budget only warns, the pods are still rolled out.
+type OdooConnectionBudgetReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+func (r *OdooConnectionBudgetReconciler) Reconcile(ctx context.Context, req ctrl.Request) error {
+	logger := log.FromContext(ctx)
+
+	dbConnectionDetails, err := r.OdooDeployment.Spec.Database.GetDbConnectionDetails(r.Client, ctx, r.OdooDeployment.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get database connection details")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, odoov1.ConditionConnectionBudget, odoov1.ReasonFailedGetDbConnectionDetails, fmt.Sprintf("Failed to get database connection details: %v", err), metav1.ConditionUnknown)
+		return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	budget := odoov1.ConnectionBudgetStatus{
+		Required: r.OdooDeployment.GetConnectionBudget(dbConnectionDetails.MaxConn),
+	}
+	current := r.OdooDeployment.Status.ConnectionBudget
+	switch {
+	case r.OdooDeployment.Spec.Database.MaxConnections > 0:
+		budget.Available = r.OdooDeployment.Spec.Database.MaxConnections
+	case current != nil && current.Available > 0 && current.ObservedGeneration == r.OdooDeployment.Generation:
+		budget.Available = current.Available
+		budget.ObservedGeneration = current.ObservedGeneration
+	default:
+		available, err := r.getAvailableConnections(ctx, dbConnectionDetails)
+		if err != nil {
+			// The database may not be up yet, the budget is checked again on the next reconcile
+			logger.Info(fmt.Sprintf("Could not query the connections the database accepts: %v", err))
+			r.OdooDeployment.Status.ConnectionBudget = &budget
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, odoov1.ConditionConnectionBudget, odoov1.ReasonConnectionBudgetUnknown, fmt.Sprintf("Could not query the connections the database accepts, set spec.database.maxConnections to skip the query: %v", err), metav1.ConditionUnknown)
+			return nil
+		}
+		budget.Available = int32(available)
+		budget.ObservedGeneration = r.OdooDeployment.Generation
+	}
+	r.OdooDeployment.Status.ConnectionBudget = &budget
+
+	message := fmt.Sprintf("Odoo may open %d connections, maxConn %d × (%d workers + %d cron threads + 1) × %d replicas, the database accepts %d",
+		budget.Required,
+		dbConnectionDetails.MaxConn,
+		r.OdooDeployment.Spec.Config.Workers,
+		r.OdooDeployment.Spec.Config.MaxCronThreads,
+		r.OdooDeployment.Spec.Replicas,
+		budget.Available,
+	)
+	if r.OdooDeployment.UsesPooler() {
+		message += fmt.Sprintf(", the pooler opens at most %d", r.OdooDeployment.GetPoolerConnections())
+	}
+	if budget.Required > budget.Available {
+		logger.Info(message)
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, odoov1.ConditionConnectionBudget, odoov1.ReasonConnectionBudgetExceeded, message, metav1.ConditionFalse)
+		return nil
+	}
+	utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, odoov1.ConditionConnectionBudget, odoov1.ReasonWithinConnectionBudget, message, metav1.ConditionTrue)
+	return nil
+}
+
+func (r *OdooConnectionBudgetReconciler) getAvailableConnections(ctx context.Context, dbConnectionDetails odoov1.DatabaseConnectionDetails) (int, error) {
+	dbConfig, err := getDatabaseConfig(ctx, r.Client, r.OdooDeployment, dbConnectionDetails)
+	if err != nil {
+		return 0, err
+	}
+	return database.GetAvailableConnections(ctx, dbConfig)
+}
@@ -26,6 +26,9 @@ type OdooDatabaseInitJobReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
 	OdooDeployment *odoov1.OdooDeployment
+	// CheckDatabase runs the pre-flight check of the database before an init job is created,
+	// checkDatabase when nil
+	CheckDatabase func(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment) (bool, error)
 }
 
 // Reconcile handles the reconciliation of the OdooDatabaseInitJob
@@ -55,6 +58,21 @@ func (r *OdooDatabaseInitJobReconciler) Reconcile(ctx context.Context, req ctrl.
 			// The current init job has succeeded, so we can clear it
 			logger.Info("Current InitJob succeeded")
 			logger.Info("New modules installed: " + fmt.Sprint(r.OdooDeployment.Status.CurrentInitJob.Modules))
+			if len(r.OdooDeployment.Status.InitModulesInstalled) == 0 && len(currentInitJob.Spec.Template.Spec.Containers) > 0 {
+				// A fresh database is already up to date with the image it was initialised with
+				now := metav1.Now()
+				digest, err := utils.GetJobImageDigest(r.Client, ctx, currentInitJob, "odoo")
+				if err != nil {
+					logger.Info(fmt.Sprintf("Could not get the image digest of InitJob %s: %v", currentInitJob.Name, err))
+				}
+				r.OdooDeployment.Status.LastUpgrade = odoov1.OdooUpgrade{
+					Image:       currentInitJob.Spec.Template.Spec.Containers[0].Image,
+					ImageDigest: digest,
+					Modules:     r.OdooDeployment.Spec.Upgrade.Modules,
+					Token:       r.OdooDeployment.Spec.Upgrade.Token,
+					CompletedAt: &now,
+				}
+			}
 			r.OdooDeployment.Status.CurrentInitJob.Name = ""
 			r.OdooDeployment.Status.CurrentInitJob.Namespace = ""
 			r.OdooDeployment.Status.InitModulesInstalled = append(r.OdooDeployment.Status.InitModulesInstalled, r.OdooDeployment.Status.CurrentInitJob.Modules...)
@@ -97,7 +115,7 @@ func (r *OdooDatabaseInitJobReconciler) Reconcile(ctx context.Context, req ctrl.
 		} else if currentInitJob.Status.Failed > 0 {
 			// If job failed, update the status of the OdooDeployment
 			// logger.Error("Current InitJob")
-			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", "FailedInitJob", fmt.Sprintf("Init job %s in namespace %s failed", r.OdooDeployment.Status.CurrentInitJob.Name, r.OdooDeployment.Status.CurrentInitJob.Namespace), metav1.ConditionFalse)
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", "FailedInitJob", fmt.Sprintf("Init job %s in namespace %s failed%s", r.OdooDeployment.Status.CurrentInitJob.Name, r.OdooDeployment.Status.CurrentInitJob.Namespace, rollbackHint(r.OdooDeployment.Status.CurrentInitJob.Backup)), metav1.ConditionFalse)
 			return ctrl.Result{}, utilerrors.NewAggregate([]error{nil, r.Status().Update(ctx, r.OdooDeployment)}), true
 		}
 	}
@@ -110,11 +128,41 @@ func (r *OdooDatabaseInitJobReconciler) Reconcile(ctx context.Context, req ctrl.
 		// Create a new InitJob to install all modules
 		logger.Info("Creating a new InitJob to install modules")
 
+		// Installing modules into a database in use changes its schema, a fresh database has nothing to back up
+		backupName := ""
+		if len(r.OdooDeployment.Status.InitModulesInstalled) > 0 {
+			var ready bool
+			var err error
+			backupName, ready, err = waitForPreUpgradeBackup(ctx, r.Client, r.OdooDeployment)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+			} else if !ready {
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+			}
+		}
+
+		// Wrong credentials or an unsupported server would only surface as a failed init job
+		check := r.CheckDatabase
+		if check == nil {
+			check = checkDatabase
+		}
+		ready, err := check(ctx, r.Client, r.OdooDeployment)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+		} else if !ready {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+		}
+
 		initJob, modulesToInstall := r.OdooDeployment.GetDbInitJobTemplate()
+		if err := r.OdooDeployment.PatchJobPodSpec(&initJob.Spec.Template.Spec); err != nil {
+			logger.Error(err, "Failed to patch the pod spec of the InitJob")
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.jobs: %v", err), metav1.ConditionFalse)
+			return ctrl.Result{}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+		}
 		logger.Info("New modules to install: " + fmt.Sprint(modulesToInstall))
 		ctrl.SetControllerReference(r.OdooDeployment, &initJob, r.Scheme)
 
-		err := r.Create(ctx, &initJob)
+		err = r.Create(ctx, &initJob)
 		if err != nil {
 			logger.Error(err, fmt.Sprintf("error creating %s init job.", req.Name))
 			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", "InitJobCreationFailed", fmt.Sprintf("error creating %s init job: %v", req.Name, err), metav1.ConditionFalse)
@@ -126,7 +174,9 @@ func (r *OdooDatabaseInitJobReconciler) Reconcile(ctx context.Context, req ctrl.
 			Name:      initJob.Name,
 			Namespace: r.OdooDeployment.Namespace,
 			Modules:   modulesToInstall,
+			Backup:    backupName,
 		}
+		r.OdooDeployment.Status.PreUpgradeBackup = ""
 		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", "InitJobCreated", fmt.Sprintf("InitJob %s created", initJob.Name), metav1.ConditionTrue)
 
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{nil, r.Status().Update(ctx, r.OdooDeployment)}), true
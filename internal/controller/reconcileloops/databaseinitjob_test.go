@@ -8,6 +8,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -19,6 +20,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
 )
 
 // specCounter generates unique resource names per test so that lingering
@@ -75,9 +77,9 @@ var _ = Describe("DatabaseInitJob Reconcile Loop", func() {
 						LocalObjectReference: corev1.LocalObjectReference{Name: "test-dbinitjob-db-secret"},
 						Key:                  "name",
 					},
-					SSLFromSecret: corev1.SecretKeySelector{
+					SSLModeFromSecret: corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{Name: "test-dbinitjob-db-secret"},
-						Key:                  "ssl",
+						Key:                  "sslmode",
 					},
 					MaxConnFromSecret: corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{Name: "test-dbinitjob-db-secret"},
@@ -132,6 +134,10 @@ var _ = Describe("DatabaseInitJob Reconcile Loop", func() {
 			Client:         k8sClient,
 			Scheme:         k8sClient.Scheme(),
 			OdooDeployment: odooDeployment,
+			// There is no database server in envtest
+			CheckDatabase: func(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment) (bool, error) {
+				return true, nil
+			},
 		}
 		req = ctrl.Request{
 			NamespacedName: client.ObjectKey{Name: resourceName, Namespace: resourceNamespace},
@@ -279,4 +285,26 @@ var _ = Describe("DatabaseInitJob Reconcile Loop", func() {
 		Expect(odooDeployment.Status.CurrentInitJob.Name).To(BeEmpty())
 		Expect(odooDeployment.Status.CurrentInitJob.Modules).To(BeEmpty())
 	})
+
+	// Scenario F: the database fails the pre-flight check → no job, retried later
+	It("F: does not create an InitJob when the database fails the pre-flight check", func() {
+		reconciler.CheckDatabase = func(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment) (bool, error) {
+			utils.UpdateStatus(&odooDeployment.Status.Conditions, odoov1.ConditionDatabaseReady, odoov1.ReasonAuthFailed, "authentication failed", metav1.ConditionFalse)
+			return false, nil
+		}
+		result, err, requeue := reconciler.Reconcile(ctx, req)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeue).To(BeTrue())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+		Expect(odooDeployment.Status.CurrentInitJob.Name).To(BeEmpty())
+		Expect(meta.IsStatusConditionFalse(odooDeployment.Status.Conditions, odoov1.ConditionDatabaseReady)).To(BeTrue())
+
+		job := &batchv1.Job{}
+		err = k8sClient.Get(ctx, types.NamespacedName{
+			Name:      resourceName + "-init",
+			Namespace: resourceNamespace,
+		}, job)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
 })
@@ -0,0 +1,67 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooDatabaseLockReconciler releases a database lock of an OdooDeployment, e.g. the restore
+// or the migration lock, whose holder no longer exists. The lock is taken and released by the
+// controller of the holder, a failed holder keeps it so Odoo is not started on a half replaced
+// database until the holder is deleted.
+type OdooDatabaseLockReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+	// Lock is the status field of the OdooDeployment holding the name of the holder,
+	// e.g. &OdooDeployment.Status.CurrentRestore
+	Lock *string
+	// Holder is an empty object of the kind holding the lock, e.g. &odoov1.OdooRestore{}
+	Holder client.Object
+	// The condition reasons of a released lock and of a holder that could not be read
+	ReasonReleased  string
+	ReasonFailedGet string
+}
+
+// Reconcile returns true while the holder holds the lock of the OdooDeployment
+func (r *OdooDatabaseLockReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error, bool) {
+	logger := log.FromContext(ctx)
+
+	holderName := *r.Lock
+	if holderName == "" {
+		return ctrl.Result{}, nil, false
+	}
+	kind := "holder"
+	if gvk, err := apiutil.GVKForObject(r.Holder, r.Scheme); err == nil {
+		kind = gvk.Kind
+	}
+
+	err := r.Get(ctx, types.NamespacedName{Name: holderName, Namespace: r.OdooDeployment.Namespace}, r.Holder)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info(fmt.Sprintf("%s %s not found, releasing its lock", kind, holderName))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", r.ReasonReleased, fmt.Sprintf("%s %s was deleted, lock released", kind, holderName), metav1.ConditionTrue)
+		*r.Lock = ""
+		return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment), false
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("Failed to get %s", kind))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", r.ReasonFailedGet, fmt.Sprintf("Failed to get %s %s: %v", kind, holderName, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+
+	logger.Info(fmt.Sprintf("%s %s in progress, skipping the database jobs", kind, holderName))
+	return ctrl.Result{}, nil, true
+}
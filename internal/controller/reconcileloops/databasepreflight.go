@@ -0,0 +1,112 @@
+package reconcileloops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/database"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// getDatabaseSSLFile returns the content of an SSL file of the database connection, or nil when
+// its secret is unset
+func getDatabaseSSLFile(ctx context.Context, c client.Client, namespace string, selector corev1.SecretKeySelector) ([]byte, error) {
+	if selector.Name == "" {
+		return nil, nil
+	}
+	value, err := utils.GetSecretValue(c, ctx, namespace, selector.Name, selector.Key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+// setDatabaseSSLFiles sets the CA certificate of sslRootCertFromSecret and the client certificate
+// and key of sslCertFromSecret and sslKeyFromSecret on the config
+func setDatabaseSSLFiles(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment, dbConfig *database.Config) error {
+	dbSpec := odooDeployment.Spec.Database
+	var err error
+	if dbConfig.RootCert, err = getDatabaseSSLFile(ctx, c, odooDeployment.Namespace, dbSpec.SSLRootCertFromSecret); err != nil {
+		return fmt.Errorf("root certificate of secret %s: %w", dbSpec.SSLRootCertFromSecret.Name, err)
+	}
+	if dbConfig.ClientCert, err = getDatabaseSSLFile(ctx, c, odooDeployment.Namespace, dbSpec.SSLCertFromSecret); err != nil {
+		return fmt.Errorf("client certificate of secret %s: %w", dbSpec.SSLCertFromSecret.Name, err)
+	}
+	if dbConfig.ClientKey, err = getDatabaseSSLFile(ctx, c, odooDeployment.Namespace, dbSpec.SSLKeyFromSecret); err != nil {
+		return fmt.Errorf("client key of secret %s: %w", dbSpec.SSLKeyFromSecret.Name, err)
+	}
+	return nil
+}
+
+// getDatabaseConfig returns the config connecting to the database with the credentials Odoo uses
+func getDatabaseConfig(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment, dbConnectionDetails odoov1.DatabaseConnectionDetails) (database.Config, error) {
+	dbConfig := database.Config{
+		Host:     dbConnectionDetails.Host,
+		Port:     dbConnectionDetails.Port,
+		User:     dbConnectionDetails.User,
+		Password: dbConnectionDetails.Password,
+		Database: dbConnectionDetails.Name,
+		SSLMode:  string(dbConnectionDetails.SSLMode),
+	}
+	if err := setDatabaseSSLFiles(ctx, c, odooDeployment, &dbConfig); err != nil {
+		return database.Config{}, err
+	}
+	return dbConfig, nil
+}
+
+// getDatabaseCheckReason returns the reason of the DatabaseReady condition for an error of database.Check
+func getDatabaseCheckReason(err error) string {
+	switch {
+	case errors.Is(err, database.ErrUnreachable):
+		return odoov1.ReasonDatabaseUnreachable
+	case errors.Is(err, database.ErrAuthFailed):
+		return odoov1.ReasonAuthFailed
+	case errors.Is(err, database.ErrDatabaseMissing):
+		return odoov1.ReasonDatabaseMissing
+	case errors.Is(err, database.ErrVersionUnsupported):
+		return odoov1.ReasonVersionUnsupported
+	case errors.Is(err, database.ErrPermissionDenied):
+		return odoov1.ReasonPermissionDenied
+	}
+	return odoov1.ReasonDatabaseCheckFailed
+}
+
+// checkDatabase gates the init job on a pre-flight check of the database, connecting with the
+// credentials Odoo uses. It returns true once the server is reachable, the credentials are accepted,
+// the server version is supported by the Odoo version and the user can create tables, and records the
+// outcome in the DatabaseReady condition. The OdooDeployment status is only modified, it is up to the
+// caller to update it. A failed check is not an error, the caller retries it later.
+func checkDatabase(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	dbConnectionDetails, err := odooDeployment.Spec.Database.GetDbConnectionDetails(c, ctx, odooDeployment.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get database connection details")
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, odoov1.ConditionDatabaseReady, odoov1.ReasonFailedGetDbConnectionDetails, fmt.Sprintf("Failed to get database connection details: %v", err), metav1.ConditionFalse)
+		return false, err
+	}
+	dbConfig, err := getDatabaseConfig(ctx, c, odooDeployment, dbConnectionDetails)
+	if err != nil {
+		logger.Error(err, "Failed to get database SSL certificates")
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, odoov1.ConditionDatabaseReady, odoov1.ReasonFailedGetDbConnectionDetails, fmt.Sprintf("Failed to get database SSL certificates: %v", err), metav1.ConditionFalse)
+		return false, err
+	}
+
+	minVersion := odoov1.GetMinPostgresVersion(odooDeployment.GetOdooMajorVersion())
+	err = database.Check(ctx, dbConfig, minVersion)
+	if err != nil {
+		logger.Info(fmt.Sprintf("Database %s failed the pre-flight check: %v", dbConnectionDetails.Name, err))
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, odoov1.ConditionDatabaseReady, getDatabaseCheckReason(err), err.Error(), metav1.ConditionFalse)
+		return false, nil
+	}
+
+	utils.UpdateStatus(&odooDeployment.Status.Conditions, odoov1.ConditionDatabaseReady, odoov1.ReasonDatabaseReady, fmt.Sprintf("Database %s on %s:%d is ready", dbConnectionDetails.Name, dbConnectionDetails.Host, dbConnectionDetails.Port), metav1.ConditionTrue)
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/database"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooDatabaseProvisioningReconciler creates the role, the database and the extensions of
+// spec.database.provisioning before the init job runs, connecting with the superuser secret.
+// Provisioning runs again whenever the spec changes and is reported in the DatabaseProvisioned condition.
+type OdooDatabaseProvisioningReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+// Reconcile handles the reconciliation of the database provisioning
+// Returns ctrl.Result, error, bool (indicating whether to requeue)
+func (r *OdooDatabaseProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error, bool) {
+	logger := log.FromContext(ctx)
+
+	provisioning := r.OdooDeployment.Spec.Database.Provisioning
+	if provisioning == nil {
+		meta.RemoveStatusCondition(&r.OdooDeployment.Status.Conditions, odoov1.ConditionDatabaseProvisioned)
+		return ctrl.Result{}, nil, false
+	}
+
+	condition := meta.FindStatusCondition(r.OdooDeployment.Status.Conditions, odoov1.ConditionDatabaseProvisioned)
+	if condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == r.OdooDeployment.Generation {
+		return ctrl.Result{}, nil, false
+	}
+
+	dbConnectionDetails, err := r.OdooDeployment.Spec.Database.GetDbConnectionDetails(r.Client, ctx, r.OdooDeployment.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get database connection details")
+		r.setCondition(odoov1.ReasonFailedGetDbConnectionDetails, fmt.Sprintf("Failed to get database connection details: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+
+	superuser, err := r.getSuperuserConfig(ctx, dbConnectionDetails)
+	if err != nil {
+		logger.Error(err, "Failed to get the superuser credentials")
+		r.setCondition(odoov1.ReasonFailedGetSuperuserSecret, fmt.Sprintf("Failed to get the superuser credentials: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+
+	logger.Info(fmt.Sprintf("Provisioning database %s owned by %s", dbConnectionDetails.Name, dbConnectionDetails.User))
+	err = database.Provision(ctx, superuser, database.Database{
+		Name:          dbConnectionDetails.Name,
+		Owner:         dbConnectionDetails.User,
+		OwnerPassword: dbConnectionDetails.Password,
+		Collation:     provisioning.Collation,
+		Extensions:    provisioning.Extensions,
+	})
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("Failed to provision database %s", dbConnectionDetails.Name))
+		r.setCondition(odoov1.ReasonDatabaseProvisioningFailed, fmt.Sprintf("Failed to provision database %s: %v", dbConnectionDetails.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+
+	r.setCondition(odoov1.ReasonDatabaseProvisioned, fmt.Sprintf("Database %s owned by %s provisioned", dbConnectionDetails.Name, dbConnectionDetails.User), metav1.ConditionTrue)
+	return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment), false
+}
+
+// setCondition sets the DatabaseProvisioned condition for the current generation, a True condition
+// of an earlier generation provisions the database again
+func (r *OdooDatabaseProvisioningReconciler) setCondition(reason, message string, status metav1.ConditionStatus) {
+	meta.SetStatusCondition(&r.OdooDeployment.Status.Conditions, metav1.Condition{
+		Type:               odoov1.ConditionDatabaseProvisioned,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: r.OdooDeployment.Generation,
+		LastTransitionTime: metav1.Now(),
+	})
+}
+
+// getSuperuserConfig returns the config connecting the superuser of the superuser secret to the
+// maintenance database of the server Odoo connects to
+func (r *OdooDatabaseProvisioningReconciler) getSuperuserConfig(ctx context.Context, dbConnectionDetails odoov1.DatabaseConnectionDetails) (database.Config, error) {
+	dbConfig := r.OdooDeployment.Spec.Database
+	namespace := r.OdooDeployment.Namespace
+
+	secretName := dbConfig.Provisioning.SuperuserSecretName
+	user, err := utils.GetSecretValue(r.Client, ctx, namespace, secretName, "username")
+	if err != nil {
+		return database.Config{}, fmt.Errorf("username of secret %s: %w", secretName, err)
+	}
+	password, err := utils.GetSecretValue(r.Client, ctx, namespace, secretName, "password")
+	if err != nil {
+		return database.Config{}, fmt.Errorf("password of secret %s: %w", secretName, err)
+	}
+
+	superuser := database.Config{
+		Host:     dbConnectionDetails.Host,
+		Port:     dbConnectionDetails.Port,
+		User:     user,
+		Password: password,
+		Database: dbConfig.Provisioning.MaintenanceDatabase,
+		SSLMode:  string(dbConnectionDetails.SSLMode),
+	}
+	if err := setDatabaseSSLFiles(ctx, r.Client, r.OdooDeployment, &superuser); err != nil {
+		return database.Config{}, err
+	}
+	return superuser, nil
+}
@@ -0,0 +1,125 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooDatabaseUninstallJobReconciler uninstalls the modules removed from spec.modules when the
+// module removal policy is Uninstall. A failed uninstall does not hold back the Deployment, it is
+// retried once the list of removed modules changes.
+type OdooDatabaseUninstallJobReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+// Reconcile handles the reconciliation of the OdooDatabaseUninstallJob
+// Returns ctrl.Result, error, bool (indicating whether to requeue)
+func (r *OdooDatabaseUninstallJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error, bool) {
+	logger := log.FromContext(ctx)
+
+	uninstallJob, modulesToUninstall := r.OdooDeployment.GetDbUninstallJobTemplate()
+
+	current := r.OdooDeployment.Status.CurrentUninstallJob
+	if current.Name != "" && current.Namespace != "" {
+		logger.Info("Current UninstallJob defined, checking status")
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: current.Name, Namespace: current.Namespace}, job)
+		if err != nil && errors.IsNotFound(err) {
+			logger.Info("Current UninstallJob not found")
+			r.OdooDeployment.Status.CurrentUninstallJob = odoov1.DBInitjob{}
+		} else if err != nil {
+			logger.Error(err, "Failed to get current UninstallJob")
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetUninstallJob, fmt.Sprintf("Failed to get current UninstallJob: %v", err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+		} else if job.Status.Succeeded > 0 {
+			logger.Info("Current UninstallJob succeeded", "modules", current.Modules)
+			r.OdooDeployment.Status.InitModulesInstalled = utils.Difference(r.OdooDeployment.Status.InitModulesInstalled, current.Modules)
+			if r.OdooDeployment.Status.InitModulesInstalled == nil {
+				r.OdooDeployment.Status.InitModulesInstalled = []string{}
+			}
+			r.OdooDeployment.Status.CurrentUninstallJob = odoov1.DBInitjob{}
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonUninstallJobSucceeded, fmt.Sprintf("UninstallJob %s succeeded", job.Name), metav1.ConditionTrue)
+			if err := utils.DeleteJob(r.Client, ctx, job); err != nil {
+				logger.Error(err, "Failed to delete UninstallJob")
+				utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteUninstallJob, fmt.Sprintf("Failed to delete UninstallJob %s: %v", job.Name, err), metav1.ConditionFalse)
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+			}
+			return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment), false
+		} else if utils.IsJobFailed(job) {
+			if len(utils.Difference(modulesToUninstall, current.Modules)) > 0 || len(utils.Difference(current.Modules, modulesToUninstall)) > 0 {
+				// The removed modules changed since the failed uninstall, retry with the new ones
+				logger.Info("Current UninstallJob failed and the removed modules changed, deleting it", "job", job.Name)
+				r.OdooDeployment.Status.CurrentUninstallJob = odoov1.DBInitjob{}
+				if err := utils.DeleteJob(r.Client, ctx, job); err != nil {
+					logger.Error(err, "Failed to delete UninstallJob")
+					utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteUninstallJob, fmt.Sprintf("Failed to delete UninstallJob %s: %v", job.Name, err), metav1.ConditionFalse)
+					return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+				}
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+			}
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonUninstallJobFailed, fmt.Sprintf("Uninstall job %s in namespace %s failed%s", current.Name, current.Namespace, rollbackHint(current.Backup)), metav1.ConditionFalse)
+			return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment), false
+		} else {
+			logger.Info("Current UninstallJob still running, requeueing")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, nil, true
+		}
+	}
+
+	if len(modulesToUninstall) == 0 {
+		return ctrl.Result{}, nil, false
+	}
+
+	backupName, ready, err := waitForPreUpgradeBackup(ctx, r.Client, r.OdooDeployment)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	} else if !ready {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+	}
+
+	logger.Info("Creating a new UninstallJob", "modules", modulesToUninstall)
+	if err := r.OdooDeployment.PatchJobPodSpec(&uninstallJob.Spec.Template.Spec); err != nil {
+		logger.Error(err, "Failed to patch the pod spec of the UninstallJob")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.jobs: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+	ctrl.SetControllerReference(r.OdooDeployment, &uninstallJob, r.Scheme)
+
+	err = r.Create(ctx, &uninstallJob)
+	if err != nil && errors.IsAlreadyExists(err) {
+		// A previous uninstall job is still being deleted
+		logger.Info(fmt.Sprintf("UninstallJob %s already exists, requeueing", uninstallJob.Name))
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil, true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error creating %s uninstall job.", req.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonUninstallJobCreationFailed, fmt.Sprintf("error creating %s uninstall job: %v", req.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+
+	logger.Info(fmt.Sprintf("UninstallJob %s created", uninstallJob.Name))
+	r.OdooDeployment.Status.CurrentUninstallJob = odoov1.DBInitjob{
+		Name:      uninstallJob.Name,
+		Namespace: r.OdooDeployment.Namespace,
+		Modules:   modulesToUninstall,
+		Backup:    backupName,
+	}
+	r.OdooDeployment.Status.PreUpgradeBackup = ""
+	utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonUninstallJobCreated, fmt.Sprintf("UninstallJob %s created", uninstallJob.Name), metav1.ConditionTrue)
+
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+}
@@ -0,0 +1,202 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooDatabaseUpgradeJobReconciler runs the module upgrades of spec.upgrade. The Deployment is
+// only updated once the upgrade job succeeded, a failed upgrade keeps the previous pods running
+// until spec.upgrade or the image is changed again.
+type OdooDatabaseUpgradeJobReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+// Reconcile handles the reconciliation of the OdooDatabaseUpgradeJob
+// Returns ctrl.Result, error, bool (true while an upgrade is pending or running)
+func (r *OdooDatabaseUpgradeJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error, bool) {
+	logger := log.FromContext(ctx)
+
+	if r.OdooDeployment.Status.LastUpgrade.Image == "" && len(r.OdooDeployment.Status.InitModulesInstalled) > 0 {
+		if err := r.adoptDeployedImage(ctx); err != nil {
+			logger.Error(err, "Failed to get the deployed image")
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetDeployedImage, fmt.Sprintf("Failed to get the deployed image: %v", err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+		}
+	}
+
+	pending, upgradePending := r.OdooDeployment.GetPendingUpgrade()
+
+	current := r.OdooDeployment.Status.CurrentUpgradeJob
+	if current.Name != "" && current.Namespace != "" {
+		logger.Info("Current UpgradeJob defined, checking status")
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: current.Name, Namespace: current.Namespace}, job)
+		if err != nil && errors.IsNotFound(err) {
+			logger.Info("Current UpgradeJob not found")
+			r.OdooDeployment.Status.CurrentUpgradeJob = odoov1.DBUpgradeJob{}
+		} else if err != nil {
+			logger.Error(err, "Failed to get current UpgradeJob")
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetUpgradeJob, fmt.Sprintf("Failed to get current UpgradeJob: %v", err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+		} else if job.Status.Succeeded > 0 {
+			logger.Info("Current UpgradeJob succeeded", "image", current.Upgrade.Image, "modules", current.Upgrade.Modules)
+			now := metav1.Now()
+			r.OdooDeployment.Status.LastUpgrade = current.Upgrade
+			r.OdooDeployment.Status.LastUpgrade.CompletedAt = &now
+			digest, err := utils.GetJobImageDigest(r.Client, ctx, job, "odoo")
+			if err != nil {
+				logger.Info(fmt.Sprintf("Could not get the image digest of UpgradeJob %s: %v", job.Name, err))
+			}
+			r.OdooDeployment.Status.LastUpgrade.ImageDigest = digest
+			r.OdooDeployment.Status.CurrentUpgradeJob = odoov1.DBUpgradeJob{}
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonUpgradeJobSucceeded, fmt.Sprintf("UpgradeJob %s succeeded", job.Name), metav1.ConditionTrue)
+			if err := utils.DeleteJob(r.Client, ctx, job); err != nil {
+				logger.Error(err, "Failed to delete UpgradeJob")
+				utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteUpgradeJob, fmt.Sprintf("Failed to delete UpgradeJob %s: %v", job.Name, err), metav1.ConditionFalse)
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+			}
+			// Roll out the Deployment on the next reconcile, once the job is gone
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+		} else if utils.IsJobFailed(job) {
+			if !upgradePending || !current.Upgrade.IsSameUpgrade(pending) {
+				// The spec changed since the failed upgrade, retry with the new one
+				logger.Info("Current UpgradeJob failed and the upgrade changed, deleting it", "job", job.Name)
+				r.OdooDeployment.Status.CurrentUpgradeJob = odoov1.DBUpgradeJob{}
+				if err := utils.DeleteJob(r.Client, ctx, job); err != nil {
+					logger.Error(err, "Failed to delete UpgradeJob")
+					utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteUpgradeJob, fmt.Sprintf("Failed to delete UpgradeJob %s: %v", job.Name, err), metav1.ConditionFalse)
+					return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+				}
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+			}
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonUpgradeJobFailed, fmt.Sprintf("Upgrade job %s in namespace %s failed, change spec.upgrade or the image to retry%s", current.Name, current.Namespace, rollbackHint(current.Backup)), metav1.ConditionFalse)
+			return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment), true
+		} else {
+			logger.Info("Current UpgradeJob still running, requeueing")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, nil, true
+		}
+	}
+
+	if r.OdooDeployment.IsUpgradeAwaitingApproval() {
+		approval := r.OdooDeployment.GetUpgradeApprovalValue()
+		logger.Info("Image changed, waiting for the upgrade to be approved", "image", approval)
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonUpgradeAwaitingApproval, fmt.Sprintf("Image %s is not rolled out until the upgrade is approved by setting the %s annotation to it", approval, odoov1.UpgradeApprovalAnnotation), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment), true
+	}
+
+	if !upgradePending {
+		return ctrl.Result{}, nil, false
+	}
+
+	// An init or uninstall job started before the upgrade was pending runs on the same database
+	runningJob, err := r.getRunningDatabaseJob(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to get the running database jobs")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetDatabaseJob, fmt.Sprintf("Failed to get the running database jobs: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	} else if runningJob != "" {
+		logger.Info(fmt.Sprintf("Job %s still running, waiting before upgrading", runningJob))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonWaitingForDatabaseJob, fmt.Sprintf("Waiting for job %s to finish before upgrading", runningJob), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+	}
+
+	backupName, ready, err := waitForPreUpgradeBackup(ctx, r.Client, r.OdooDeployment)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	} else if !ready {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+	}
+
+	logger.Info("Creating a new UpgradeJob", "image", pending.Image, "modules", pending.Modules)
+	upgradeJob := r.OdooDeployment.GetDbUpgradeJobTemplate(pending)
+	if err := r.OdooDeployment.PatchJobPodSpec(&upgradeJob.Spec.Template.Spec); err != nil {
+		logger.Error(err, "Failed to patch the pod spec of the UpgradeJob")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.jobs: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+	ctrl.SetControllerReference(r.OdooDeployment, &upgradeJob, r.Scheme)
+
+	err = r.Create(ctx, &upgradeJob)
+	if err != nil && errors.IsAlreadyExists(err) {
+		// A previous upgrade job is still being deleted
+		logger.Info(fmt.Sprintf("UpgradeJob %s already exists, requeueing", upgradeJob.Name))
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil, true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error creating %s upgrade job.", req.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonUpgradeJobCreationFailed, fmt.Sprintf("error creating %s upgrade job: %v", req.Name, err), metav1.ConditionFalse)
+		return ctrl.Result{}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)}), true
+	}
+
+	logger.Info(fmt.Sprintf("UpgradeJob %s created", upgradeJob.Name))
+	r.OdooDeployment.Status.CurrentUpgradeJob = odoov1.DBUpgradeJob{
+		Name:      upgradeJob.Name,
+		Namespace: r.OdooDeployment.Namespace,
+		Upgrade:   pending,
+		Backup:    backupName,
+	}
+	r.OdooDeployment.Status.PreUpgradeBackup = ""
+	utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonUpgradeJobCreated, fmt.Sprintf("UpgradeJob %s created", upgradeJob.Name), metav1.ConditionTrue)
+
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, r.Status().Update(ctx, r.OdooDeployment), true
+}
+
+// getRunningDatabaseJob returns the name of the current init or uninstall job when it has not
+// finished yet, or an empty string
+func (r *OdooDatabaseUpgradeJobReconciler) getRunningDatabaseJob(ctx context.Context) (string, error) {
+	for _, current := range []odoov1.DBInitjob{r.OdooDeployment.Status.CurrentInitJob, r.OdooDeployment.Status.CurrentUninstallJob} {
+		if current.Name == "" {
+			continue
+		}
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Name: current.Name, Namespace: r.OdooDeployment.Namespace}, job)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		if job.Status.Succeeded == 0 && !utils.IsJobFailed(job) {
+			return job.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// adoptDeployedImage records the image of the running Deployment as the image the database was
+// last migrated with, for databases initialised before upgrades were tracked. spec.image is used
+// when there is no Deployment yet.
+func (r *OdooDatabaseUpgradeJobReconciler) adoptDeployedImage(ctx context.Context) error {
+	image := r.OdooDeployment.Spec.Image
+	deployment := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.OdooDeployment.Name, Namespace: r.OdooDeployment.Namespace}, deployment)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if container.Name == "odoo" {
+				image = container.Image
+			}
+		}
+	}
+	log.FromContext(ctx).Info(fmt.Sprintf("Recording image %s as the image the database was last migrated with", image))
+	r.OdooDeployment.Status.LastUpgrade.Image = image
+	return nil
+}
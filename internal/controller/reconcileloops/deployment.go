@@ -45,6 +45,12 @@ func (r *DeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	deploymentTemplate := r.OdooDeployment.GetDeploymentTemplate()
+	// The patched spec is the desired state, the drift check compares the Deployment with it
+	if err := r.OdooDeployment.PatchDeploymentPodSpec(&deploymentTemplate.Spec.Template.Spec); err != nil {
+		logger.Error(err, "Failed to patch the pod spec of the Deployment")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidPodTemplatePatch, fmt.Sprintf("Failed to apply spec.podTemplatePatch.deployment: %v", err), metav1.ConditionFalse)
+		return deployment, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
 
 	if createDeployment {
 		logger.Info(fmt.Sprintf("Creating a new Deployment for %s", req.Name))
@@ -86,11 +86,11 @@ var _ = Describe("Deployment Reconcile Loop", func() {
 							},
 							Key: "name",
 						},
-						SSLFromSecret: corev1.SecretKeySelector{
+						SSLModeFromSecret: corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
 								Name: "test-db-secret",
 							},
-							Key: "ssl",
+							Key: "sslmode",
 						},
 						MaxConnFromSecret: corev1.SecretKeySelector{
 							LocalObjectReference: corev1.LocalObjectReference{
@@ -129,7 +129,7 @@ var _ = Describe("Deployment Reconcile Loop", func() {
 				"user":     []byte("db-user"),
 				"password": []byte("db-password"),
 				"name":     []byte("db-name"),
-				"ssl":      []byte("false"),
+				"sslmode":  []byte("disable"),
 				"maxconn":  []byte("20"),
 			},
 		}
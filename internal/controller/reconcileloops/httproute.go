@@ -0,0 +1,102 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+// OdooHTTPRouteReconciler creates the Gateway API HTTPRoute of spec.exposure.gateway, deletes it once
+// spec.exposure.gateway is unset and reports its Accepted and ResolvedRefs conditions in the status.
+// The Gateway API is only required in the cluster while spec.exposure.gateway is set.
+type OdooHTTPRouteReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+func (r *OdooHTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (*unstructured.Unstructured, error) {
+	logger := log.FromContext(ctx)
+
+	requested := r.OdooDeployment.Spec.Exposure != nil && r.OdooDeployment.Spec.Exposure.Gateway != nil
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(odoov1.HTTPRouteGroupVersionKind)
+	createRoute := false
+	routeNamespacedName := types.NamespacedName{
+		Name:      r.OdooDeployment.GetHTTPRouteName(),
+		Namespace: r.OdooDeployment.Namespace,
+	}
+	err := r.Get(ctx, routeNamespacedName, route)
+	if err != nil && (errors.IsNotFound(err) || (!requested && meta.IsNoMatchError(err))) {
+		createRoute = true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error getting %s httproute.", routeNamespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetHTTPRoute, fmt.Sprintf("error getting %s httproute: %v", routeNamespacedName.Name, err), metav1.ConditionFalse)
+		return route, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	if !requested {
+		r.OdooDeployment.Status.HTTPRoute = nil
+		// Only delete an HTTPRoute this OdooDeployment created
+		if createRoute || !metav1.IsControlledBy(route, r.OdooDeployment) {
+			return nil, nil
+		}
+		logger.Info(fmt.Sprintf("Deleting httproute %s", routeNamespacedName.Name))
+		if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, fmt.Sprintf("error deleting %s httproute.", routeNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteHTTPRoute, fmt.Sprintf("error deleting %s httproute: %v", routeNamespacedName.Name, err), metav1.ConditionFalse)
+			return route, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+		return nil, nil
+	}
+
+	routeTemplate := r.OdooDeployment.GetHTTPRouteTemplate()
+
+	if createRoute {
+		logger.Info(fmt.Sprintf("Creating a new httproute for %s", routeNamespacedName.Name))
+		route = routeTemplate
+		ctrl.SetControllerReference(r.OdooDeployment, route, r.Scheme)
+		err = r.Create(ctx, route)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("error creating %s httproute.", routeNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreateHTTPRoute, fmt.Sprintf("error creating %s httproute: %v", routeNamespacedName.Name, err), metav1.ConditionFalse)
+			return route, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	} else if diff := cmp.Diff(route.Object["spec"], routeTemplate.Object["spec"]); diff != "" {
+		logger.V(1).Info(fmt.Sprintf("Diff: %s", diff))
+		logger.Info(fmt.Sprintf("Updating httproute %s spec", routeNamespacedName.Name))
+		route.Object["spec"] = routeTemplate.Object["spec"]
+		ctrl.SetControllerReference(r.OdooDeployment, route, r.Scheme)
+		err = r.Update(ctx, route)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("error updating %s httproute.", routeNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedUpdateHTTPRoute, fmt.Sprintf("error updating %s httproute: %v", routeNamespacedName.Name, err), metav1.ConditionFalse)
+			return route, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	}
+
+	routeStatus, err := odoov1.GetHTTPRouteStatus(route)
+	if err != nil {
+		logger.Info(fmt.Sprintf("Could not read the status of httproute %s: %v", routeNamespacedName.Name, err))
+		routeStatus = &odoov1.HTTPRouteStatus{Name: route.GetName()}
+	}
+	r.OdooDeployment.Status.HTTPRoute = routeStatus
+
+	return route, nil
+}
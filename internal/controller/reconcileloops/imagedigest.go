@@ -0,0 +1,110 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// OdooImageDigestReconciler resolves the digest of spec.image every ImageDigestCheckInterval with a
+// pod pulling it, so a tag pushed again is detected as an image change by the upgrade job reconciler.
+// It only runs when imagePullPolicy is Always, otherwise the nodes keep the image they pulled before.
+type OdooImageDigestReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+// Reconcile handles the reconciliation of the image digest pod. The OdooDeployment status is only
+// modified, it is up to the caller to update it. The result requeues the next check.
+func (r *OdooImageDigestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	podNamespacedName := types.NamespacedName{
+		Name:      r.OdooDeployment.GetImageDigestPodName(),
+		Namespace: r.OdooDeployment.Namespace,
+	}
+	pod := corev1.Pod{}
+	err := r.Get(ctx, podNamespacedName, &pod)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to get the image digest pod")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetImageDigestPod, fmt.Sprintf("Failed to get the image digest pod: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+	podFound := err == nil && metav1.IsControlledBy(&pod, r.OdooDeployment)
+
+	if !r.OdooDeployment.IsImageDigestResolved() {
+		r.OdooDeployment.Status.ResolvedImage = odoov1.ResolvedImage{}
+		if podFound {
+			return ctrl.Result{}, r.deletePod(ctx, &pod)
+		}
+		return ctrl.Result{}, nil
+	}
+	if delay := r.OdooDeployment.GetImageDigestCheckDelay(time.Now()); delay > 0 {
+		if podFound {
+			return ctrl.Result{RequeueAfter: delay}, r.deletePod(ctx, &pod)
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	if !podFound {
+		pod = r.OdooDeployment.GetImageDigestPodTemplate()
+		ctrl.SetControllerReference(r.OdooDeployment, &pod, r.Scheme)
+		logger.Info(fmt.Sprintf("Creating image digest pod %s", pod.Name), "image", r.OdooDeployment.Spec.Image)
+		if err := r.Create(ctx, &pod); err != nil && !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create the image digest pod")
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreateImageDigestPod, fmt.Sprintf("Failed to create the image digest pod: %v", err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+		// The pod is owned by the OdooDeployment, its status changes trigger the next reconcile
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	// spec.image changed since the pod was created
+	if len(pod.Spec.Containers) == 0 || pod.Spec.Containers[0].Image != r.OdooDeployment.Spec.Image {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, r.deletePod(ctx, &pod)
+	}
+
+	digest, found := utils.GetPodImageDigest(&pod, "odoo")
+	if !found {
+		if pod.Status.Phase == corev1.PodFailed {
+			// The image could not be pulled before the deadline, try again at the next check
+			logger.Info(fmt.Sprintf("Image digest pod %s failed without pulling %s", pod.Name, r.OdooDeployment.Spec.Image))
+			return ctrl.Result{RequeueAfter: odoov1.ImageDigestCheckInterval}, r.deletePod(ctx, &pod)
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	if resolved := r.OdooDeployment.Status.ResolvedImage; resolved.Image == r.OdooDeployment.Spec.Image && resolved.Digest != digest {
+		logger.Info(fmt.Sprintf("Image %s resolves to a new digest", r.OdooDeployment.Spec.Image), "digest", digest, "previous", resolved.Digest)
+	}
+	now := metav1.Now()
+	r.OdooDeployment.Status.ResolvedImage = odoov1.ResolvedImage{
+		Image:      r.OdooDeployment.Spec.Image,
+		Digest:     digest,
+		ResolvedAt: &now,
+	}
+	return ctrl.Result{RequeueAfter: odoov1.ImageDigestCheckInterval}, r.deletePod(ctx, &pod)
+}
+
+func (r *OdooImageDigestReconciler) deletePod(ctx context.Context, pod *corev1.Pod) error {
+	if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "Failed to delete the image digest pod")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteImageDigestPod, fmt.Sprintf("Failed to delete the image digest pod: %v", err), metav1.ConditionFalse)
+		return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+// OdooIngressReconciler creates the Ingress of spec.ingress and deletes it once spec.ingress is unset
+type OdooIngressReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+func (r *OdooIngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (networkingv1.Ingress, error) {
+	logger := log.FromContext(ctx)
+
+	ingress := networkingv1.Ingress{}
+	createIngress := false
+	ingressNamespacedName := types.NamespacedName{
+		Name:      r.OdooDeployment.GetIngressName(),
+		Namespace: r.OdooDeployment.Namespace,
+	}
+	err := r.Get(ctx, ingressNamespacedName, &ingress)
+	if err != nil && errors.IsNotFound(err) {
+		createIngress = true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error getting %s ingress.", ingressNamespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetIngress, fmt.Sprintf("error getting %s ingress: %v", ingressNamespacedName.Name, err), metav1.ConditionFalse)
+		return ingress, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	if r.OdooDeployment.Spec.Ingress == nil {
+		// Only delete an Ingress this OdooDeployment created
+		if createIngress || !metav1.IsControlledBy(&ingress, r.OdooDeployment) {
+			return networkingv1.Ingress{}, nil
+		}
+		logger.Info(fmt.Sprintf("Deleting ingress %s", ingressNamespacedName.Name))
+		if err := r.Delete(ctx, &ingress); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, fmt.Sprintf("error deleting %s ingress.", ingressNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeleteIngress, fmt.Sprintf("error deleting %s ingress: %v", ingressNamespacedName.Name, err), metav1.ConditionFalse)
+			return ingress, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+		return networkingv1.Ingress{}, nil
+	}
+
+	ingressTemplate := r.OdooDeployment.GetIngressTemplate()
+
+	ctrl.SetControllerReference(r.OdooDeployment, &ingress, r.Scheme)
+	if createIngress {
+		logger.Info(fmt.Sprintf("Creating a new ingress for %s", ingressNamespacedName.Name))
+		ingress.Spec = ingressTemplate.Spec
+		ingress.Annotations = ingressTemplate.Annotations
+		ingress.Name = ingressNamespacedName.Name
+		ingress.Namespace = ingressNamespacedName.Namespace
+		err = r.Create(ctx, &ingress)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("error creating %s ingress.", ingressNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreateIngress, fmt.Sprintf("error creating %s ingress: %v", ingressNamespacedName.Name, err), metav1.ConditionFalse)
+			return ingress, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	} else if diff := cmp.Diff(ingress.Spec, ingressTemplate.Spec); diff != "" || !maps.Equal(ingress.Annotations, ingressTemplate.Annotations) {
+		logger.V(1).Info(fmt.Sprintf("Diff: %s", diff))
+		logger.Info(fmt.Sprintf("Updating ingress %s spec", ingressNamespacedName.Name))
+		ingress.Spec = ingressTemplate.Spec
+		ingress.Annotations = ingressTemplate.Annotations
+		err = r.Update(ctx, &ingress)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("error updating %s ingress.", ingressNamespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedUpdateIngress, fmt.Sprintf("error updating %s ingress: %v", ingressNamespacedName.Name, err), metav1.ConditionFalse)
+			return ingress, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	}
+
+	return ingress, nil
+}
@@ -0,0 +1,159 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+	"github.com/google/go-cmp/cmp"
+)
+
+// OdooPoolerReconciler creates the PgBouncer Deployment and Service of the deployment mode of
+// spec.database.pooler and deletes them in the other modes. The sidecar of the sidecar mode is part
+// of the pod spec of Odoo, its configuration of both modes is part of the config secret.
+type OdooPoolerReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+func (r *OdooPoolerReconciler) Reconcile(ctx context.Context, req ctrl.Request) error {
+	poolerNamespacedName := types.NamespacedName{
+		Name:      r.OdooDeployment.GetPoolerName(),
+		Namespace: r.OdooDeployment.Namespace,
+	}
+	deployment := appsv1.Deployment{}
+	service := corev1.Service{}
+
+	if r.OdooDeployment.GetPoolerMode() != odoov1.PoolerModeDeployment {
+		if err := r.deleteIfControlled(ctx, poolerNamespacedName, &deployment); err != nil {
+			return err
+		}
+		return r.deleteIfControlled(ctx, poolerNamespacedName, &service)
+	}
+
+	if err := r.reconcileDeployment(ctx, poolerNamespacedName, &deployment); err != nil {
+		return err
+	}
+	return r.reconcileService(ctx, poolerNamespacedName, &service)
+}
+
+// deleteIfControlled deletes the pooler object of the name, only when this OdooDeployment created it
+func (r *OdooPoolerReconciler) deleteIfControlled(ctx context.Context, namespacedName types.NamespacedName, obj client.Object) error {
+	logger := log.FromContext(ctx)
+
+	err := r.Get(ctx, namespacedName, obj)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error getting %s pooler.", namespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetPooler, fmt.Sprintf("error getting %s pooler: %v", namespacedName.Name, err), metav1.ConditionFalse)
+		return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+	if !metav1.IsControlledBy(obj, r.OdooDeployment) {
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Deleting pooler %s", namespacedName.Name))
+	if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, fmt.Sprintf("error deleting %s pooler.", namespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedDeletePooler, fmt.Sprintf("error deleting %s pooler: %v", namespacedName.Name, err), metav1.ConditionFalse)
+		return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+	return nil
+}
+
+func (r *OdooPoolerReconciler) reconcileDeployment(ctx context.Context, namespacedName types.NamespacedName, deployment *appsv1.Deployment) error {
+	logger := log.FromContext(ctx)
+
+	createDeployment := false
+	err := r.Get(ctx, namespacedName, deployment)
+	if err != nil && errors.IsNotFound(err) {
+		createDeployment = true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error getting %s pooler deployment.", namespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetPooler, fmt.Sprintf("error getting %s pooler deployment: %v", namespacedName.Name, err), metav1.ConditionFalse)
+		return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	deploymentTemplate := r.OdooDeployment.GetPoolerDeploymentTemplate()
+
+	if createDeployment {
+		logger.Info(fmt.Sprintf("Creating a new pooler deployment for %s", r.OdooDeployment.Name))
+		*deployment = deploymentTemplate
+		ctrl.SetControllerReference(r.OdooDeployment, deployment, r.Scheme)
+		if err := r.Create(ctx, deployment); err != nil {
+			logger.Error(err, fmt.Sprintf("error creating %s pooler deployment.", namespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreatePooler, fmt.Sprintf("error creating %s pooler deployment: %v", namespacedName.Name, err), metav1.ConditionFalse)
+			return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	} else if diff := cmp.Diff(deployment.Spec, deploymentTemplate.Spec); diff != "" {
+		logger.V(1).Info(fmt.Sprintf("Diff: %s", diff))
+		logger.Info(fmt.Sprintf("Updating existing pooler deployment for %s", r.OdooDeployment.Name))
+		deployment.Spec = deploymentTemplate.Spec
+		ctrl.SetControllerReference(r.OdooDeployment, deployment, r.Scheme)
+		if err := r.Update(ctx, deployment); err != nil {
+			logger.Error(err, fmt.Sprintf("error updating %s pooler deployment.", namespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedUpdatePooler, fmt.Sprintf("error updating %s pooler deployment: %v", namespacedName.Name, err), metav1.ConditionFalse)
+			return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	}
+	return nil
+}
+
+func (r *OdooPoolerReconciler) reconcileService(ctx context.Context, namespacedName types.NamespacedName, service *corev1.Service) error {
+	logger := log.FromContext(ctx)
+
+	createService := false
+	err := r.Get(ctx, namespacedName, service)
+	if err != nil && errors.IsNotFound(err) {
+		createService = true
+	} else if err != nil {
+		logger.Error(err, fmt.Sprintf("error getting %s pooler service.", namespacedName.Name))
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetPooler, fmt.Sprintf("error getting %s pooler service: %v", namespacedName.Name, err), metav1.ConditionFalse)
+		return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	serviceTemplate := r.OdooDeployment.GetPoolerServiceTemplate()
+
+	serviceTemplate.Spec.ClusterIP = service.Spec.ClusterIP
+	serviceTemplate.Spec.ClusterIPs = service.Spec.ClusterIPs
+	serviceTemplate.Spec.IPFamilies = service.Spec.IPFamilies
+	serviceTemplate.Spec.IPFamilyPolicy = service.Spec.IPFamilyPolicy
+
+	ctrl.SetControllerReference(r.OdooDeployment, service, r.Scheme)
+	if createService {
+		logger.Info(fmt.Sprintf("Creating a new pooler service for %s", r.OdooDeployment.Name))
+		service.Spec = serviceTemplate.Spec
+		service.Name = namespacedName.Name
+		service.Namespace = namespacedName.Namespace
+		if err := r.Create(ctx, service); err != nil {
+			logger.Error(err, fmt.Sprintf("error creating %s pooler service.", namespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreatePooler, fmt.Sprintf("error creating %s pooler service: %v", namespacedName.Name, err), metav1.ConditionFalse)
+			return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	} else if diff := cmp.Diff(service.Spec, serviceTemplate.Spec); diff != "" {
+		logger.V(1).Info(fmt.Sprintf("Diff: %s", diff))
+		service.Spec = serviceTemplate.Spec
+		if err := r.Update(ctx, service); err != nil {
+			logger.Error(err, fmt.Sprintf("error updating %s pooler service.", namespacedName.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedUpdatePooler, fmt.Sprintf("error updating %s pooler service: %v", namespacedName.Name, err), metav1.ConditionFalse)
+			return utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+	}
+	return nil
+}
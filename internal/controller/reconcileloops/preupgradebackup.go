@@ -0,0 +1,72 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+// waitForPreUpgradeBackup gates the jobs changing the database schema on a backup of the database
+// and the filestore when spec.backup.preUpgrade is set. It returns the name of the succeeded
+// OdooBackup and true once the job may run, the caller records the backup on the status entry
+// of the job and clears Status.PreUpgradeBackup. The OdooDeployment status is only modified,
+// it is up to the caller to update it. A failed backup blocks the job until it is deleted.
+func waitForPreUpgradeBackup(ctx context.Context, c client.Client, odooDeployment *odoov1.OdooDeployment) (string, bool, error) {
+	logger := log.FromContext(ctx)
+
+	if !odooDeployment.Spec.Backup.PreUpgrade {
+		return "", true, nil
+	}
+
+	if odooDeployment.Status.PreUpgradeBackup == "" {
+		backup := odooDeployment.GetPreUpgradeBackupTemplate(time.Now())
+		logger.Info(fmt.Sprintf("Creating pre-upgrade backup %s", backup.Name))
+		if err := c.Create(ctx, &backup); err != nil {
+			logger.Error(err, "Failed to create pre-upgrade backup")
+			utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonPreUpgradeBackupCreationFailed, fmt.Sprintf("Failed to create pre-upgrade backup %s: %v", backup.Name, err), metav1.ConditionFalse)
+			return "", false, err
+		}
+		odooDeployment.Status.PreUpgradeBackup = backup.Name
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonPreUpgradeBackupCreated, fmt.Sprintf("Pre-upgrade backup %s created, waiting for it to succeed", backup.Name), metav1.ConditionTrue)
+		return "", false, nil
+	}
+
+	backup := &odoov1.OdooBackup{}
+	err := c.Get(ctx, types.NamespacedName{Name: odooDeployment.Status.PreUpgradeBackup, Namespace: odooDeployment.Namespace}, backup)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info(fmt.Sprintf("Pre-upgrade backup %s not found, taking a new one", odooDeployment.Status.PreUpgradeBackup))
+		odooDeployment.Status.PreUpgradeBackup = ""
+		return "", false, nil
+	} else if err != nil {
+		logger.Error(err, "Failed to get pre-upgrade backup")
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedGetPreUpgradeBackup, fmt.Sprintf("Failed to get pre-upgrade backup %s: %v", odooDeployment.Status.PreUpgradeBackup, err), metav1.ConditionFalse)
+		return "", false, err
+	}
+
+	switch backup.Status.Phase {
+	case odoov1.BackupPhaseSucceeded:
+		return backup.Name, true, nil
+	case odoov1.BackupPhaseFailed:
+		utils.UpdateStatus(&odooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonPreUpgradeBackupFailed, fmt.Sprintf("Pre-upgrade backup %s failed, delete it to take a new one", backup.Name), metav1.ConditionFalse)
+		return "", false, nil
+	}
+	logger.Info(fmt.Sprintf("Waiting for pre-upgrade backup %s", backup.Name))
+	return "", false, nil
+}
+
+// rollbackHint returns the part of a failed job message pointing to the backup taken before the job
+func rollbackHint(backupName string) string {
+	if backupName == "" {
+		return ""
+	}
+	return fmt.Sprintf(", restore OdooBackup %s to roll back", backupName)
+}
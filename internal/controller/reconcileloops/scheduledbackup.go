@@ -0,0 +1,127 @@
+package reconcileloops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+	"github.com/MohanadAbugharbia/odoo-operator/pkg/utils"
+)
+
+type OdooScheduledBackupReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	OdooDeployment *odoov1.OdooDeployment
+}
+
+// Reconcile creates an OdooBackup whenever the backup schedule is due and prunes the
+// scheduled backups that fall out of the retention policy.
+// The returned ctrl.Result requeues at the next scheduled backup.
+func (r *OdooScheduledBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !r.OdooDeployment.Spec.Backup.Enabled {
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+
+	lastScheduled := r.OdooDeployment.CreationTimestamp.Time
+	if r.OdooDeployment.Status.LastScheduledBackupTime != nil {
+		lastScheduled = r.OdooDeployment.Status.LastScheduledBackupTime.Time
+	}
+
+	due, next, err := r.OdooDeployment.Spec.Backup.GetBackupSchedule(lastScheduled, now)
+	if err != nil {
+		logger.Error(err, "Invalid backup schedule", "schedule", r.OdooDeployment.Spec.Backup.Schedule)
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonInvalidBackupSchedule, fmt.Sprintf("Invalid backup schedule %q: %v", r.OdooDeployment.Spec.Backup.Schedule, err), metav1.ConditionFalse)
+		return ctrl.Result{}, r.Status().Update(ctx, r.OdooDeployment)
+	}
+
+	if !due.IsZero() {
+		backup := r.OdooDeployment.GetScheduledBackupTemplate(due)
+		logger.Info(fmt.Sprintf("Creating scheduled backup %s", backup.Name))
+		err = r.Create(ctx, &backup)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			logger.Error(err, fmt.Sprintf("error creating %s backup.", backup.Name))
+			utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedCreateScheduledBackup, fmt.Sprintf("error creating %s backup: %v", backup.Name, err), metav1.ConditionFalse)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+		}
+		r.OdooDeployment.Status.LastScheduledBackupTime = &metav1.Time{Time: due}
+		r.OdooDeployment.Status.LastScheduledBackupName = backup.Name
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorSucceeded", odoov1.ReasonScheduledBackupCreated, fmt.Sprintf("Scheduled backup %s created", backup.Name), metav1.ConditionTrue)
+		if err := r.Status().Update(ctx, r.OdooDeployment); err != nil {
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+	}
+
+	if err := r.pruneBackups(ctx); err != nil {
+		logger.Error(err, "Failed to prune scheduled backups")
+		utils.UpdateStatus(&r.OdooDeployment.Status.Conditions, "OperatorDegraded", odoov1.ReasonFailedPruneBackups, fmt.Sprintf("Failed to prune scheduled backups: %v", err), metav1.ConditionFalse)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, utilerrors.NewAggregate([]error{err, r.Status().Update(ctx, r.OdooDeployment)})
+	}
+
+	return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+}
+
+// pruneBackups deletes the succeeded scheduled backups that are not kept by the retention policy,
+// and the failed scheduled backups that are older than the newest succeeded one.
+func (r *OdooScheduledBackupReconciler) pruneBackups(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	backups := &odoov1.OdooBackupList{}
+	err := r.List(ctx, backups,
+		client.InNamespace(r.OdooDeployment.Namespace),
+		client.MatchingLabels{
+			odoov1.OdooDeploymentLabel:  r.OdooDeployment.Name,
+			odoov1.ScheduledBackupLabel: "true",
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	succeeded := []utils.RetentionItem{}
+	newestSucceeded := time.Time{}
+	for _, backup := range backups.Items {
+		if backup.Status.Phase != odoov1.BackupPhaseSucceeded {
+			continue
+		}
+		succeeded = append(succeeded, utils.RetentionItem{Name: backup.Name, Time: backup.CreationTimestamp.Time})
+		if backup.CreationTimestamp.After(newestSucceeded) {
+			newestSucceeded = backup.CreationTimestamp.Time
+		}
+	}
+
+	_, prune := utils.ApplyRetentionPolicy(succeeded, r.OdooDeployment.Spec.Backup.GetRetentionPolicy())
+	toDelete := make(map[string]struct{}, len(prune))
+	for _, item := range prune {
+		toDelete[item.Name] = struct{}{}
+	}
+	for _, backup := range backups.Items {
+		if backup.Status.Phase == odoov1.BackupPhaseFailed && backup.CreationTimestamp.Time.Before(newestSucceeded) {
+			toDelete[backup.Name] = struct{}{}
+		}
+	}
+
+	for _, backup := range backups.Items {
+		if _, found := toDelete[backup.Name]; !found {
+			continue
+		}
+		logger.Info(fmt.Sprintf("Pruning scheduled backup %s", backup.Name))
+		err := r.Delete(ctx, &backup)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/distribution/reference"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+)
+
+// log is for logging in this package.
+var odoodeploymentlog = logf.Log.WithName("odoodeployment-resource")
+
+// SetupOdooDeploymentWebhookWithManager registers the webhook for OdooDeployment in the manager.
+func SetupOdooDeploymentWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&odoov1.OdooDeployment{}).
+		WithValidator(&OdooDeploymentCustomValidator{}).
+		WithDefaulter(&OdooDeploymentCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-odoo-abugharbia-com-v1-odoodeployment,mutating=true,failurePolicy=fail,sideEffects=None,groups=odoo.abugharbia.com,resources=odoodeployments,verbs=create;update,versions=v1,name=modoodeployment-v1.kb.io,admissionReviewVersions=v1
+
+// OdooDeploymentCustomDefaulter sizes the Odoo processes from the resources of the Odoo container
+// when spec.config.autoTune is set.
+type OdooDeploymentCustomDefaulter struct{}
+
+var _ admission.CustomDefaulter = &OdooDeploymentCustomDefaulter{}
+
+// Default implements admission.CustomDefaulter so a webhook will be registered for the type OdooDeployment.
+func (d *OdooDeploymentCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	odooDeployment, ok := obj.(*odoov1.OdooDeployment)
+	if !ok {
+		return fmt.Errorf("expected an OdooDeployment object but got %T", obj)
+	}
+	odoodeploymentlog.V(1).Info("Defaulting for OdooDeployment", "name", odooDeployment.GetName())
+
+	// Without memory the validation rejects autoTune, the annotation of an earlier computation is dropped
+	autoTuned, ok := odoov1.GetAutoTunedConfig(odooDeployment.Spec.Resources)
+	if !odooDeployment.Spec.Config.AutoTune || !ok {
+		delete(odooDeployment.Annotations, odoov1.AutoTuneAnnotation)
+		return nil
+	}
+
+	config := &odooDeployment.Spec.Config
+	config.Workers = autoTuned.Workers
+	config.MaxCronThreads = autoTuned.MaxCronThreads
+	config.LimitMemorySoft = autoTuned.LimitMemorySoft
+	config.LimitMemoryHard = autoTuned.LimitMemoryHard
+
+	annotation, err := json.Marshal(autoTuned)
+	if err != nil {
+		return err
+	}
+	if odooDeployment.Annotations == nil {
+		odooDeployment.Annotations = map[string]string{}
+	}
+	odooDeployment.Annotations[odoov1.AutoTuneAnnotation] = string(annotation)
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-odoo-abugharbia-com-v1-odoodeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=odoo.abugharbia.com,resources=odoodeployments,verbs=create;update,versions=v1,name=vodoodeployment-v1.kb.io,admissionReviewVersions=v1
+
+// OdooDeploymentCustomValidator rejects OdooDeployments the operator can not reconcile, or only into
+// pods that can not run, with an error per offending field.
+type OdooDeploymentCustomValidator struct{}
+
+var _ admission.CustomValidator = &OdooDeploymentCustomValidator{}
+
+// ValidateCreate implements admission.CustomValidator so a webhook will be registered for the type OdooDeployment.
+func (v *OdooDeploymentCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	odooDeployment, ok := obj.(*odoov1.OdooDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected an OdooDeployment object but got %T", obj)
+	}
+	odoodeploymentlog.V(1).Info("Validation for OdooDeployment upon creation", "name", odooDeployment.GetName())
+
+	return nil, toInvalidError(odooDeployment, validateOdooDeployment(odooDeployment))
+}
+
+// ValidateUpdate implements admission.CustomValidator so a webhook will be registered for the type OdooDeployment.
+func (v *OdooDeploymentCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	odooDeployment, ok := newObj.(*odoov1.OdooDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected an OdooDeployment object for the newObj but got %T", newObj)
+	}
+	oldOdooDeployment, ok := oldObj.(*odoov1.OdooDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected an OdooDeployment object for the oldObj but got %T", oldObj)
+	}
+	odoodeploymentlog.V(1).Info("Validation for OdooDeployment upon update", "name", odooDeployment.GetName())
+
+	// OdooDeployments admitted before the webhook existed stay updatable, only new errors are rejected
+	errs := ratchet(validateOdooDeployment(odooDeployment), validateOdooDeployment(oldOdooDeployment))
+	errs = append(errs, validateOdooDeploymentUpdate(odooDeployment, oldOdooDeployment)...)
+	return nil, toInvalidError(odooDeployment, errs)
+}
+
+// ValidateDelete implements admission.CustomValidator so a webhook will be registered for the type OdooDeployment.
+func (v *OdooDeploymentCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// toInvalidError returns the Invalid status error of the errors, or nil when there are none
+func toInvalidError(odooDeployment *odoov1.OdooDeployment, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(odoov1.GroupVersion.WithKind("OdooDeployment").GroupKind(), odooDeployment.Name, errs)
+}
+
+// ratchet drops the errors the old object already had for the same value
+func ratchet(errs, oldErrs field.ErrorList) field.ErrorList {
+	return slices.DeleteFunc(errs, func(err *field.Error) bool {
+		return slices.ContainsFunc(oldErrs, func(oldErr *field.Error) bool {
+			return err.Type == oldErr.Type && err.Field == oldErr.Field &&
+				fmt.Sprint(err.BadValue) == fmt.Sprint(oldErr.BadValue)
+		})
+	})
+}
+
+func validateOdooDeployment(odooDeployment *odoov1.OdooDeployment) field.ErrorList {
+	specPath := field.NewPath("spec")
+	spec := odooDeployment.Spec
+	errs := field.ErrorList{}
+
+	if _, err := reference.ParseNormalizedNamed(spec.Image); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("image"), spec.Image, err.Error()))
+	}
+
+	configPath := specPath.Child("config")
+	if _, ok := odoov1.GetAutoTunedConfig(spec.Resources); spec.Config.AutoTune && !ok {
+		errs = append(errs, field.Required(specPath.Child("resources", "limits", "memory"), "autoTune sizes Odoo from the memory limit or request"))
+	}
+	if spec.Config.LimitMemorySoft > spec.Config.LimitMemoryHard {
+		errs = append(errs, field.Invalid(configPath.Child("limitMemorySoft"), spec.Config.LimitMemorySoft,
+			fmt.Sprintf("must not be greater than limitMemoryHard %d", spec.Config.LimitMemoryHard)))
+	}
+
+	if spec.Replicas > 1 {
+		if spec.Config.Workers == 0 {
+			errs = append(errs, field.Invalid(configPath.Child("workers"), spec.Config.Workers,
+				fmt.Sprintf("must be at least 1 with %d replicas, only the multi-processing server runs behind more than one replica", spec.Replicas)))
+		}
+		if !slices.Contains(spec.OdooFilestore.AccessModes, corev1.ReadWriteMany) {
+			errs = append(errs, field.Invalid(specPath.Child("replicas"), spec.Replicas,
+				fmt.Sprintf("must be 1 unless odooFilestore.accessModes contains %s, the pods of all nodes share the filestore", corev1.ReadWriteMany)))
+		}
+	}
+
+	if spec.PodTemplate != nil {
+		errs = append(errs, validatePodTemplate(specPath.Child("podTemplate"), odooDeployment)...)
+	}
+	if spec.PodTemplatePatch != nil {
+		errs = append(errs, validatePodTemplatePatch(specPath.Child("podTemplatePatch"), odooDeployment)...)
+	}
+	return append(errs, validateDatabase(specPath.Child("database"), spec.Database)...)
+}
+
+// validatePodTemplatePatch rejects patches that do not apply to the pod specs the operator renders
+func validatePodTemplatePatch(patchPath *field.Path, odooDeployment *odoov1.OdooDeployment) field.ErrorList {
+	errs := field.ErrorList{}
+
+	deploymentPodSpec := odooDeployment.GetDeploymentTemplate().Spec.Template.Spec
+	if err := odooDeployment.PatchDeploymentPodSpec(&deploymentPodSpec); err != nil {
+		errs = append(errs, field.Invalid(patchPath.Child("deployment", "patch"), odooDeployment.Spec.PodTemplatePatch.Deployment.Patch, err.Error()))
+	}
+	jobPodSpec := odooDeployment.GetPodSpec()
+	if err := odooDeployment.PatchJobPodSpec(&jobPodSpec); err != nil {
+		errs = append(errs, field.Invalid(patchPath.Child("jobs", "patch"), odooDeployment.Spec.PodTemplatePatch.Jobs.Patch, err.Error()))
+	}
+	return errs
+}
+
+func validatePodTemplate(podTemplatePath *field.Path, odooDeployment *odoov1.OdooDeployment) field.ErrorList {
+	podTemplate := odooDeployment.Spec.PodTemplate
+	errs := field.ErrorList{}
+
+	labelsPath := podTemplatePath.Child("labels")
+	errs = append(errs, metav1validation.ValidateLabels(podTemplate.Labels, labelsPath)...)
+	// The Services select the pods by these labels, a value set for them would be silently ignored
+	for label := range odooDeployment.GetServiceSelectorLabels() {
+		if _, ok := podTemplate.Labels[label]; ok {
+			errs = append(errs, field.Forbidden(labelsPath.Key(label), "is set by the operator to select the Odoo pods"))
+		}
+	}
+	return append(errs, apivalidation.ValidateAnnotations(podTemplate.Annotations, podTemplatePath.Child("annotations"))...)
+}
+
+func validateDatabase(dbPath *field.Path, db odoov1.OdooDatabaseConfig) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if db.CNPGClusterRef == nil {
+		passwordPath := dbPath.Child("passwordFromSecret")
+		if db.PasswordFromSecret.Name == "" {
+			errs = append(errs, field.Required(passwordPath.Child("name"), "the database password is required unless cnpgClusterRef is set"))
+		}
+		if db.PasswordFromSecret.Key == "" {
+			errs = append(errs, field.Required(passwordPath.Child("key"), "the database password is required unless cnpgClusterRef is set"))
+		}
+	}
+
+	// The secret takes precedence, a value set next to it would be silently ignored
+	fromSecret := []struct {
+		name     string
+		set      bool
+		selector corev1.SecretKeySelector
+	}{
+		{name: "host", set: db.Host != "", selector: db.HostFromSecret},
+		{name: "port", set: db.Port != 0, selector: db.PortFromSecret},
+		{name: "user", set: db.User != "", selector: db.UserFromSecret},
+		{name: "name", set: db.Name != "", selector: db.NameFromSecret},
+		{name: "sslMode", set: db.SSLMode != "", selector: db.SSLModeFromSecret},
+		{name: "maxConn", set: db.MaxConn != 0, selector: db.MaxConnFromSecret},
+		{name: "replicaHost", set: db.ReplicaHost != "", selector: db.ReplicaHostFromSecret},
+		{name: "replicaPort", set: db.ReplicaPort != 0, selector: db.ReplicaPortFromSecret},
+	}
+	for _, f := range fromSecret {
+		if f.set && f.selector.Name != "" {
+			errs = append(errs, field.Forbidden(dbPath.Child(f.name), fmt.Sprintf("may not be set together with %sFromSecret", f.name)))
+		}
+	}
+	return errs
+}
+
+// validateOdooDeploymentUpdate rejects changes to the fields of the filestore a PVC can not change
+func validateOdooDeploymentUpdate(odooDeployment, oldOdooDeployment *odoov1.OdooDeployment) field.ErrorList {
+	filestorePath := field.NewPath("spec", "odooFilestore")
+	filestore := odooDeployment.Spec.OdooFilestore
+	oldFilestore := oldOdooDeployment.Spec.OdooFilestore
+	errs := field.ErrorList{}
+
+	errs = append(errs, apivalidation.ValidateImmutableField(filestore.StorageClassName, oldFilestore.StorageClassName, filestorePath.Child("storageClassName"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(filestore.AccessModes, oldFilestore.AccessModes, filestorePath.Child("accessModes"))...)
+	if filestore.Size.Cmp(oldFilestore.Size) < 0 {
+		errs = append(errs, field.Forbidden(filestorePath.Child("size"), fmt.Sprintf("may not be decreased below %s", oldFilestore.Size.String())))
+	}
+	return errs
+}
@@ -0,0 +1,269 @@
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	odoov1 "github.com/MohanadAbugharbia/odoo-operator/api/v1"
+)
+
+// validOdooDeployment returns an OdooDeployment with the defaults of the CRD that passes the validation
+func validOdooDeployment() *odoov1.OdooDeployment {
+	return &odoov1.OdooDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-odoo", Namespace: "default"},
+		Spec: odoov1.OdooDeploymentSpec{
+			Image:    "odoo:18",
+			Replicas: 1,
+			Config: odoov1.OdooConfig{
+				Workers:         2,
+				LimitMemorySoft: 2147483648,
+				LimitMemoryHard: 2684354560,
+			},
+			Database: odoov1.OdooDatabaseConfig{
+				PasswordFromSecret: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "odoo-db"},
+					Key:                  "password",
+				},
+			},
+			OdooFilestore: odoov1.PersistentVolumeClaimSpec{
+				Size:             resource.MustParse("10Gi"),
+				StorageClassName: "standard",
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			},
+		},
+	}
+}
+
+// getFields returns the field paths of the errors of an Invalid status error
+func getFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	statusErr, ok := err.(*apierrors.StatusError)
+	if !ok || !apierrors.IsInvalid(err) {
+		t.Fatalf("error = %v, want an Invalid status error", err)
+	}
+	fields := []string{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}
+
+func TestValidateCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *odoov1.OdooDeployment)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(o *odoov1.OdooDeployment) {},
+		},
+		{
+			name: "cnpg cluster without password secret",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Database.PasswordFromSecret = corev1.SecretKeySelector{}
+				o.Spec.Database.CNPGClusterRef = &odoov1.CNPGClusterReference{Name: "cluster-1"}
+			},
+		},
+		{
+			name: "scaled out with workers and a ReadWriteMany filestore",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Replicas = 3
+				o.Spec.OdooFilestore.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+			},
+		},
+		{
+			name: "unparsable image",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Image = "Odoo:18 "
+			},
+			want: []string{"spec.image"},
+		},
+		{
+			name: "soft memory limit above the hard limit",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Config.LimitMemorySoft = o.Spec.Config.LimitMemoryHard + 1
+			},
+			want: []string{"spec.config.limitMemorySoft"},
+		},
+		{
+			name: "threaded server with replicas",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Replicas = 2
+				o.Spec.Config.Workers = 0
+				o.Spec.OdooFilestore.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+			},
+			want: []string{"spec.config.workers"},
+		},
+		{
+			name: "ReadWriteOnce filestore with replicas",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Replicas = 2
+			},
+			want: []string{"spec.replicas"},
+		},
+		{
+			name: "missing password secret",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Database.PasswordFromSecret = corev1.SecretKeySelector{}
+			},
+			want: []string{"spec.database.passwordFromSecret.name", "spec.database.passwordFromSecret.key"},
+		},
+		{
+			name: "host and hostFromSecret",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Database.Host = "postgresql"
+				o.Spec.Database.HostFromSecret = corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "odoo-db"},
+					Key:                  "host",
+				}
+			},
+			want: []string{"spec.database.host"},
+		},
+		{
+			name: "autoTune without memory",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.Config.AutoTune = true
+				o.Spec.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+			},
+			want: []string{"spec.resources.limits.memory"},
+		},
+		{
+			name: "pod template labels",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.PodTemplate = &odoov1.OdooPodTemplate{
+					Labels:      map[string]string{"app": "other", "team": "erp", "bad label": "x"},
+					Annotations: map[string]string{"example.com/owner": "erp"},
+				}
+			},
+			want: []string{"spec.podTemplate.labels", "spec.podTemplate.labels[app]"},
+		},
+		{
+			name: "pod template patches that do not apply",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.PodTemplatePatch = &odoov1.OdooPodTemplatePatch{
+					Deployment: &odoov1.PodSpecPatch{Type: odoov1.PodSpecPatchTypeStrategicMerge, Patch: "containerz: []"},
+					Jobs:       &odoov1.PodSpecPatch{Type: odoov1.PodSpecPatchTypeJSONPatch, Patch: `[{"op": "remove", "path": "/containers/5"}]`},
+				}
+			},
+			want: []string{"spec.podTemplatePatch.deployment.patch", "spec.podTemplatePatch.jobs.patch"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := validOdooDeployment()
+			tc.modify(o)
+			_, err := (&OdooDeploymentCustomValidator{}).ValidateCreate(context.Background(), o)
+			if got := getFields(t, err); strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("ValidateCreate() fields = %v, want %v: %v", got, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		modifyOld func(o *odoov1.OdooDeployment)
+		modify    func(o *odoov1.OdooDeployment)
+		want      []string
+	}{
+		{
+			name:   "scaling",
+			modify: func(o *odoov1.OdooDeployment) { o.Spec.Config.Workers = 4 },
+		},
+		{
+			name:   "growing the filestore",
+			modify: func(o *odoov1.OdooDeployment) { o.Spec.OdooFilestore.Size = resource.MustParse("20Gi") },
+		},
+		{
+			name:   "shrinking the filestore",
+			modify: func(o *odoov1.OdooDeployment) { o.Spec.OdooFilestore.Size = resource.MustParse("5Gi") },
+			want:   []string{"spec.odooFilestore.size"},
+		},
+		{
+			name:   "changing the storage class",
+			modify: func(o *odoov1.OdooDeployment) { o.Spec.OdooFilestore.StorageClassName = "fast" },
+			want:   []string{"spec.odooFilestore.storageClassName"},
+		},
+		{
+			name: "changing the access modes",
+			modify: func(o *odoov1.OdooDeployment) {
+				o.Spec.OdooFilestore.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+			},
+			want: []string{"spec.odooFilestore.accessModes"},
+		},
+		{
+			name: "host defaulted by an earlier CRD next to hostFromSecret",
+			modifyOld: func(o *odoov1.OdooDeployment) {
+				o.Spec.Database.Host = "postgresql"
+				o.Spec.Database.HostFromSecret = corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "odoo-db"},
+					Key:                  "host",
+				}
+			},
+			modify: func(o *odoov1.OdooDeployment) { o.Spec.Config.Workers = 4 },
+		},
+		{
+			name:      "replicas of an existing ReadWriteOnce filestore",
+			modifyOld: func(o *odoov1.OdooDeployment) { o.Spec.Replicas = 2 },
+			modify:    func(o *odoov1.OdooDeployment) { o.Spec.Replicas = 3 },
+			want:      []string{"spec.replicas"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			old := validOdooDeployment()
+			if tc.modifyOld != nil {
+				tc.modifyOld(old)
+			}
+			o := old.DeepCopy()
+			tc.modify(o)
+			_, err := (&OdooDeploymentCustomValidator{}).ValidateUpdate(context.Background(), old, o)
+			if got := getFields(t, err); strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("ValidateUpdate() fields = %v, want %v: %v", got, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	o := validOdooDeployment()
+	o.Spec.Config.AutoTune = true
+	o.Spec.Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+
+	if err := (&OdooDeploymentCustomDefaulter{}).Default(context.Background(), o); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	config := o.Spec.Config
+	if config.Workers != 5 || config.MaxCronThreads != 2 || config.LimitMemorySoft != 429496729 || config.LimitMemoryHard != 536870912 {
+		t.Errorf("config = %+v, want 5 workers, 2 cron threads and 410/512MiB", config)
+	}
+	want := `{"workers":5,"maxCronThreads":2,"limitMemorySoft":429496729,"limitMemoryHard":536870912}`
+	if got := o.Annotations[odoov1.AutoTuneAnnotation]; got != want {
+		t.Errorf("annotation = %s, want %s", got, want)
+	}
+
+	// Turning autoTune off keeps the values and drops the annotation
+	o.Spec.Config.AutoTune = false
+	if err := (&OdooDeploymentCustomDefaulter{}).Default(context.Background(), o); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if _, ok := o.Annotations[odoov1.AutoTuneAnnotation]; ok || o.Spec.Config.Workers != 5 {
+		t.Errorf("annotations = %v and %d workers, want no annotation and 5 workers", o.Annotations, o.Spec.Config.Workers)
+	}
+}
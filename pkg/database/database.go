@@ -0,0 +1,337 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// DefaultConnectTimeout bounds the time the operator waits for a connection to the database server
+	DefaultConnectTimeout = 10 * time.Second
+	// DefaultMaintenanceDatabase is the database the superuser connects to for creating roles and databases
+	DefaultMaintenanceDatabase = "postgres"
+)
+
+// The SQLSTATE codes the checks of Check tell apart
+const (
+	codeInvalidAuthorizationSpecification = "28000"
+	codeInvalidPassword                   = "28P01"
+	codeInvalidCatalogName                = "3D000"
+	codeInsufficientPrivilege             = "42501"
+)
+
+// The reasons a database fails the checks of Check
+var (
+	ErrUnreachable        = errors.New("database server unreachable")
+	ErrAuthFailed         = errors.New("authentication failed")
+	ErrDatabaseMissing    = errors.New("database does not exist")
+	ErrVersionUnsupported = errors.New("server version unsupported")
+	ErrPermissionDenied   = errors.New("permission denied")
+)
+
+// Config holds everything needed to connect to a database of a Postgres server
+type Config struct {
+	Host     string
+	Port     int32
+	User     string
+	Password string
+	Database string
+	// The libpq sslmode, prefer when empty
+	SSLMode string
+	// The PEM encoded CA certificates verifying the server certificate, the system roots when empty
+	RootCert []byte
+	// The PEM encoded client certificate and its key presented to the server, none when empty
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// Database describes a database and the role owning it
+type Database struct {
+	Name          string
+	Owner         string
+	OwnerPassword string
+	Collation     string
+	Extensions    []string
+}
+
+// quoteValue quotes a value of a keyword/value connection string
+func quoteValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// ConnString returns the keyword/value connection string of the config
+func (c Config) ConnString() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "prefer"
+	}
+	return strings.Join([]string{
+		"host=" + quoteValue(c.Host),
+		fmt.Sprintf("port=%d", c.Port),
+		"user=" + quoteValue(c.User),
+		"password=" + quoteValue(c.Password),
+		"dbname=" + quoteValue(c.Database),
+		"sslmode=" + quoteValue(sslMode),
+	}, " ")
+}
+
+// Connect opens a connection to the database of the config
+func Connect(ctx context.Context, config Config) (*pgx.Conn, error) {
+	connConfig, err := config.parse()
+	if err != nil {
+		return nil, err
+	}
+	return pgx.ConnectConfig(ctx, connConfig)
+}
+
+// parse returns the pgx config of the config, the certificates are set on the TLS config of
+// the connection and of its fallbacks, e.g. the TLS attempt of sslmode prefer
+func (c Config) parse() (*pgx.ConnConfig, error) {
+	connConfig, err := pgx.ParseConfig(c.ConnString())
+	if err != nil {
+		return nil, err
+	}
+	connConfig.ConnectTimeout = DefaultConnectTimeout
+
+	var rootCAs *x509.CertPool
+	if len(c.RootCert) > 0 {
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(c.RootCert) {
+			return nil, fmt.Errorf("no certificate found in the root certificate")
+		}
+	}
+	var certificates []tls.Certificate
+	if len(c.ClientCert) > 0 || len(c.ClientKey) > 0 {
+		certificate, err := tls.X509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading the client certificate: %w", err)
+		}
+		certificates = []tls.Certificate{certificate}
+	}
+
+	tlsConfigs := []*tls.Config{connConfig.TLSConfig}
+	for _, fallback := range connConfig.Fallbacks {
+		tlsConfigs = append(tlsConfigs, fallback.TLSConfig)
+	}
+	for _, tlsConfig := range tlsConfigs {
+		if tlsConfig == nil {
+			continue
+		}
+		if rootCAs != nil {
+			tlsConfig.RootCAs = rootCAs
+		}
+		if certificates != nil {
+			tlsConfig.Certificates = certificates
+		}
+	}
+	return connConfig, nil
+}
+
+// quoteLiteral quotes a string literal of a statement that can not take parameters
+func quoteLiteral(conn *pgx.Conn, value string) (string, error) {
+	escaped, err := conn.PgConn().EscapeString(value)
+	if err != nil {
+		return "", err
+	}
+	return "'" + escaped + "'", nil
+}
+
+// exists runs a query selecting a single row and returns whether it found one
+func exists(ctx context.Context, conn *pgx.Conn, query string, args ...any) (bool, error) {
+	var found bool
+	err := conn.QueryRow(ctx, "SELECT EXISTS("+query+")", args...).Scan(&found)
+	return found, err
+}
+
+// Provision creates the owner role, the database and its extensions with the superuser of the config,
+// every step is skipped when its object already exists. The password of an existing role is reset.
+func Provision(ctx context.Context, superuser Config, db Database) error {
+	if superuser.Database == "" {
+		superuser.Database = DefaultMaintenanceDatabase
+	}
+	conn, err := Connect(ctx, superuser)
+	if err != nil {
+		return fmt.Errorf("connecting to %s as %s: %w", superuser.Database, superuser.User, err)
+	}
+	defer conn.Close(ctx)
+
+	if err := ensureRole(ctx, conn, db.Owner, db.OwnerPassword); err != nil {
+		return err
+	}
+	if err := ensureDatabase(ctx, conn, db); err != nil {
+		return err
+	}
+
+	// Extensions are created in the database itself
+	superuser.Database = db.Name
+	dbConn, err := Connect(ctx, superuser)
+	if err != nil {
+		return fmt.Errorf("connecting to %s as %s: %w", db.Name, superuser.User, err)
+	}
+	defer dbConn.Close(ctx)
+
+	for _, extension := range db.Extensions {
+		if _, err := dbConn.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS "+pgx.Identifier{extension}.Sanitize()); err != nil {
+			return fmt.Errorf("creating extension %s: %w", extension, err)
+		}
+	}
+	return nil
+}
+
+// ensureRole creates a login role with the given password, or sets the password of an existing role
+func ensureRole(ctx context.Context, conn *pgx.Conn, name, password string) error {
+	found, err := exists(ctx, conn, "SELECT 1 FROM pg_roles WHERE rolname = $1", name)
+	if err != nil {
+		return fmt.Errorf("looking up role %s: %w", name, err)
+	}
+	passwordLiteral, err := quoteLiteral(conn, password)
+	if err != nil {
+		return err
+	}
+
+	statement := "CREATE ROLE %s LOGIN PASSWORD %s"
+	if found {
+		statement = "ALTER ROLE %s LOGIN PASSWORD %s"
+	}
+	if _, err := conn.Exec(ctx, fmt.Sprintf(statement, pgx.Identifier{name}.Sanitize(), passwordLiteral)); err != nil {
+		return fmt.Errorf("provisioning role %s: %w", name, err)
+	}
+	return nil
+}
+
+// ensureDatabase creates the database as Odoo does, from template0 in UTF8, or hands an existing
+// database over to its owner. The encoding and collation of an existing database are left untouched.
+func ensureDatabase(ctx context.Context, conn *pgx.Conn, db Database) error {
+	found, err := exists(ctx, conn, "SELECT 1 FROM pg_database WHERE datname = $1", db.Name)
+	if err != nil {
+		return fmt.Errorf("looking up database %s: %w", db.Name, err)
+	}
+	name := pgx.Identifier{db.Name}.Sanitize()
+	owner := pgx.Identifier{db.Owner}.Sanitize()
+
+	if found {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", name, owner)); err != nil {
+			return fmt.Errorf("changing the owner of database %s: %w", db.Name, err)
+		}
+		return nil
+	}
+
+	statement := fmt.Sprintf("CREATE DATABASE %s OWNER %s ENCODING 'UTF8' TEMPLATE template0", name, owner)
+	if db.Collation != "" {
+		collation, err := quoteLiteral(conn, db.Collation)
+		if err != nil {
+			return err
+		}
+		statement += " LC_COLLATE " + collation
+	}
+	if _, err := conn.Exec(ctx, statement); err != nil {
+		return fmt.Errorf("creating database %s: %w", db.Name, err)
+	}
+	return nil
+}
+
+// FormatVersion formats a server_version_num, e.g. 160004 as 16.4 and 90624 as 9.6.24
+func FormatVersion(version int) string {
+	if version >= 100000 {
+		return fmt.Sprintf("%d.%d", version/10000, version%10000)
+	}
+	return fmt.Sprintf("%d.%d.%d", version/10000, version/100%100, version%100)
+}
+
+// classifyConnectError wraps the error of a failed connection in ErrAuthFailed, ErrDatabaseMissing
+// or ErrUnreachable
+func classifyConnectError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case codeInvalidPassword, codeInvalidAuthorizationSpecification:
+			return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+		case codeInvalidCatalogName:
+			return fmt.Errorf("%w: %w", ErrDatabaseMissing, err)
+		}
+	}
+	return fmt.Errorf("%w: %w", ErrUnreachable, err)
+}
+
+// GetAvailableConnections returns the connections the server accepts from roles without the
+// superuser or pg_use_reserved_connections privileges, max_connections less the reserved connections
+func GetAvailableConnections(ctx context.Context, config Config) (int, error) {
+	conn, err := Connect(ctx, config)
+	if err != nil {
+		return 0, classifyConnectError(err)
+	}
+	defer conn.Close(ctx)
+
+	// reserved_connections only exists since PostgreSQL 16
+	var available int
+	err = conn.QueryRow(ctx, `SELECT current_setting('max_connections')::int
+		- current_setting('superuser_reserved_connections')::int
+		- coalesce(current_setting('reserved_connections', true)::int, 0)`).Scan(&available)
+	return available, err
+}
+
+// Check connects to the database of the config and verifies that the server version is at least
+// minVersion, a server_version_num, and that the user can create tables. A database that does not
+// exist yet passes when the user may create it, Odoo creates it when initializing it. The returned
+// error wraps one of the Err variables of the package.
+func Check(ctx context.Context, config Config, minVersion int) error {
+	conn, err := Connect(ctx, config)
+	missing := false
+	if err != nil {
+		err = classifyConnectError(err)
+		if !errors.Is(err, ErrDatabaseMissing) {
+			return err
+		}
+		missing = true
+		maintenance := config
+		maintenance.Database = DefaultMaintenanceDatabase
+		if conn, err = Connect(ctx, maintenance); err != nil {
+			return classifyConnectError(err)
+		}
+	}
+	defer conn.Close(ctx)
+
+	var version int
+	if err := conn.QueryRow(ctx, "SELECT current_setting('server_version_num')::int").Scan(&version); err != nil {
+		return err
+	}
+	if version < minVersion {
+		return fmt.Errorf("%w: PostgreSQL %s is older than %s", ErrVersionUnsupported, FormatVersion(version), FormatVersion(minVersion))
+	}
+
+	if missing {
+		var createDB bool
+		if err := conn.QueryRow(ctx, "SELECT rolcreatedb OR rolsuper FROM pg_roles WHERE rolname = current_user").Scan(&createDB); err != nil {
+			return err
+		}
+		if !createDB {
+			return fmt.Errorf("%w: database %s does not exist and role %s may not create it", ErrDatabaseMissing, config.Database, config.User)
+		}
+		return nil
+	}
+
+	// The table only exists in the transaction, it is never committed
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, "CREATE TABLE odoo_operator_preflight (id integer)"); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == codeInsufficientPrivilege {
+			return fmt.Errorf("%w: role %s can not create tables: %w", ErrPermissionDenied, config.User, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package database
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestConnString(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{
+			name:   "defaults to prefer",
+			config: Config{Host: "cluster-1-rw", Port: 5432, User: "postgres", Password: "secret", Database: "postgres"},
+			want:   `host='cluster-1-rw' port=5432 user='postgres' password='secret' dbname='postgres' sslmode='prefer'`,
+		},
+		{
+			name:   "quotes and backslashes",
+			config: Config{Host: "db", Port: 5433, User: "odoo", Password: `it's\secret`, Database: "odoo", SSLMode: "require"},
+			want:   `host='db' port=5433 user='odoo' password='it\'s\\secret' dbname='odoo' sslmode='require'`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.config.ConnString()
+			if got != tc.want {
+				t.Errorf("ConnString() = %s, want %s", got, tc.want)
+			}
+			parsed, err := pgx.ParseConfig(got)
+			if err != nil {
+				t.Fatalf("ParseConfig() error = %v", err)
+			}
+			if parsed.Password != tc.config.Password {
+				t.Errorf("parsed password = %q, want %q", parsed.Password, tc.config.Password)
+			}
+		})
+	}
+}
+
+// newCertificate returns a self-signed PEM encoded certificate and its key
+func newCertificate(t *testing.T, commonName string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestConfigParseTLS(t *testing.T) {
+	rootCert, _ := newCertificate(t, "root")
+	clientCert, clientKey := newCertificate(t, "odoo")
+	_, otherKey := newCertificate(t, "other")
+
+	tests := []struct {
+		name       string
+		config     Config
+		wantErr    bool
+		wantCert   bool
+		wantRootCA bool
+	}{
+		{
+			name:   "no certificates",
+			config: Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "require"},
+		},
+		{
+			name:       "client certificate on require",
+			config:     Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "require", RootCert: rootCert, ClientCert: clientCert, ClientKey: clientKey},
+			wantCert:   true,
+			wantRootCA: true,
+		},
+		{
+			name:     "client certificate on the TLS attempt of prefer",
+			config:   Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "prefer", ClientCert: clientCert, ClientKey: clientKey},
+			wantCert: true,
+		},
+		{
+			name:       "client certificate on verify-full",
+			config:     Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "verify-full", RootCert: rootCert, ClientCert: clientCert, ClientKey: clientKey},
+			wantCert:   true,
+			wantRootCA: true,
+		},
+		{
+			name:    "client certificate without key",
+			config:  Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "require", ClientCert: clientCert},
+			wantErr: true,
+		},
+		{
+			name:    "client key not matching the certificate",
+			config:  Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "require", ClientCert: clientCert, ClientKey: otherKey},
+			wantErr: true,
+		},
+		{
+			name:    "invalid root certificate",
+			config:  Config{Host: "db", Port: 5432, User: "odoo", Database: "odoo", SSLMode: "require", RootCert: []byte("invalid")},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			connConfig, err := tc.config.parse()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			tlsConfigs := []*tls.Config{connConfig.TLSConfig}
+			for _, fallback := range connConfig.Fallbacks {
+				tlsConfigs = append(tlsConfigs, fallback.TLSConfig)
+			}
+			found := 0
+			for _, tlsConfig := range tlsConfigs {
+				if tlsConfig == nil {
+					continue
+				}
+				found++
+				if got := len(tlsConfig.Certificates) == 1; got != tc.wantCert {
+					t.Errorf("client certificate set = %v, want %v", got, tc.wantCert)
+				}
+				if got := tlsConfig.RootCAs != nil; got != tc.wantRootCA {
+					t.Errorf("root CAs set = %v, want %v", got, tc.wantRootCA)
+				}
+			}
+			if found == 0 {
+				t.Errorf("no TLS config found")
+			}
+		})
+	}
+}
+
+// newPostgresConfig returns the superuser config of the Postgres server configured through
+// POSTGRES_HOST, POSTGRES_PORT, POSTGRES_USER and POSTGRES_PASSWORD, e.g. the one started by
+// `make test-postgres`. The test is skipped when POSTGRES_HOST is not set.
+func newPostgresConfig(t *testing.T) Config {
+	t.Helper()
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		t.Skip("POSTGRES_HOST is not set")
+	}
+	port := int64(5432)
+	if value := os.Getenv("POSTGRES_PORT"); value != "" {
+		var err error
+		if port, err = strconv.ParseInt(value, 10, 32); err != nil {
+			t.Fatalf("invalid POSTGRES_PORT: %v", err)
+		}
+	}
+	return Config{
+		Host:     host,
+		Port:     int32(port),
+		User:     os.Getenv("POSTGRES_USER"),
+		Password: os.Getenv("POSTGRES_PASSWORD"),
+		Database: DefaultMaintenanceDatabase,
+		SSLMode:  "disable",
+	}
+}
+
+func TestProvision(t *testing.T) {
+	superuser := newPostgresConfig(t)
+	ctx := context.Background()
+	db := Database{
+		Name:          "odoo_provision_test",
+		Owner:         "odoo_provision_test",
+		OwnerPassword: "it's secret",
+		Collation:     "C",
+		Extensions:    []string{"unaccent", "pg_trgm"},
+	}
+
+	conn, err := Connect(ctx, superuser)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	// Cleanups run last in first out, the connection is closed after dropping the database
+	t.Cleanup(func() { conn.Close(ctx) })
+	t.Cleanup(func() {
+		_, _ = conn.Exec(ctx, "DROP DATABASE IF EXISTS "+pgx.Identifier{db.Name}.Sanitize())
+		_, _ = conn.Exec(ctx, "DROP ROLE IF EXISTS "+pgx.Identifier{db.Owner}.Sanitize())
+	})
+
+	// Provisioning twice must succeed, the second time everything already exists
+	for range 2 {
+		if err := Provision(ctx, superuser, db); err != nil {
+			t.Fatalf("Provision() error = %v", err)
+		}
+	}
+
+	var owner, encoding, collation string
+	err = conn.QueryRow(ctx,
+		`SELECT pg_get_userbyid(datdba), pg_encoding_to_char(encoding), datcollate FROM pg_database WHERE datname = $1`,
+		db.Name,
+	).Scan(&owner, &encoding, &collation)
+	if err != nil {
+		t.Fatalf("querying database %s: %v", db.Name, err)
+	}
+	if owner != db.Owner || encoding != "UTF8" || collation != db.Collation {
+		t.Errorf("database = owner %s, encoding %s, collation %s, want %s, UTF8, %s", owner, encoding, collation, db.Owner, db.Collation)
+	}
+
+	// The owner logs in with its password and sees the extensions
+	ownerConn, err := Connect(ctx, Config{
+		Host:     superuser.Host,
+		Port:     superuser.Port,
+		User:     db.Owner,
+		Password: db.OwnerPassword,
+		Database: db.Name,
+		SSLMode:  superuser.SSLMode,
+	})
+	if err != nil {
+		t.Fatalf("Connect() as owner error = %v", err)
+	}
+	defer ownerConn.Close(ctx)
+
+	for _, extension := range db.Extensions {
+		var found bool
+		if err := ownerConn.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pg_extension WHERE extname = $1)", extension).Scan(&found); err != nil {
+			t.Fatalf("querying extension %s: %v", extension, err)
+		}
+		if !found {
+			t.Errorf("extension %s was not created", extension)
+		}
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{version: 160004, want: "16.4"},
+		{version: 130000, want: "13.0"},
+		{version: 90624, want: "9.6.24"},
+	}
+
+	for _, tc := range tests {
+		if got := FormatVersion(tc.version); got != tc.want {
+			t.Errorf("FormatVersion(%d) = %q, want %q", tc.version, got, tc.want)
+		}
+	}
+}
+
+func TestClassifyConnectError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "wrong password", err: &pgconn.PgError{Code: "28P01"}, want: ErrAuthFailed},
+		{name: "unknown role", err: &pgconn.PgError{Code: "28000"}, want: ErrAuthFailed},
+		{name: "unknown database", err: &pgconn.PgError{Code: "3D000"}, want: ErrDatabaseMissing},
+		{name: "too many connections", err: &pgconn.PgError{Code: "53300"}, want: ErrUnreachable},
+		{name: "network error", err: errors.New("dial tcp: connection refused"), want: ErrUnreachable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyConnectError(tc.err); !errors.Is(got, tc.want) || !errors.Is(got, tc.err) {
+				t.Errorf("classifyConnectError() = %v, want it to wrap %v and %v", got, tc.want, tc.err)
+			}
+		})
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	// Nothing listens on port 1
+	err := Check(context.Background(), Config{Host: "127.0.0.1", Port: 1, User: "odoo", Database: "odoo", SSLMode: "disable"}, 0)
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Check() error = %v, want %v", err, ErrUnreachable)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	superuser := newPostgresConfig(t)
+	ctx := context.Background()
+
+	wrongPassword := superuser
+	wrongPassword.Password += "-wrong"
+	missingDatabase := superuser
+	missingDatabase.Database = "odoo_check_missing"
+
+	// Since PostgreSQL 15 only the owner of a database may create tables in its public schema
+	conn, err := Connect(ctx, superuser)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	if _, err := conn.Exec(ctx, "CREATE ROLE odoo_check_guest LOGIN PASSWORD 'guest'"); err != nil {
+		t.Fatalf("creating role: %v", err)
+	}
+	t.Cleanup(func() { _, _ = conn.Exec(ctx, "DROP ROLE IF EXISTS odoo_check_guest") })
+	guest := superuser
+	guest.User = "odoo_check_guest"
+	guest.Password = "guest"
+
+	tests := []struct {
+		name       string
+		config     Config
+		minVersion int
+		want       error
+	}{
+		{name: "ready", config: superuser, minVersion: 130000},
+		{name: "wrong password", config: wrongPassword, want: ErrAuthFailed},
+		{name: "server too old", config: superuser, minVersion: 990000, want: ErrVersionUnsupported},
+		{name: "missing database the superuser may create", config: missingDatabase},
+		{name: "guest of the database", config: guest, want: ErrPermissionDenied},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(ctx, tc.config, tc.minVersion)
+			if tc.want == nil && err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Fatalf("Check() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetAvailableConnections(t *testing.T) {
+	superuser := newPostgresConfig(t)
+	ctx := context.Background()
+
+	conn, err := Connect(ctx, superuser)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close(ctx)
+	var maxConnections int
+	if err := conn.QueryRow(ctx, "SELECT current_setting('max_connections')::int").Scan(&maxConnections); err != nil {
+		t.Fatalf("querying max_connections: %v", err)
+	}
+
+	got, err := GetAvailableConnections(ctx, superuser)
+	if err != nil {
+		t.Fatalf("GetAvailableConnections() error = %v", err)
+	}
+	if got <= 0 || got >= maxConnections {
+		t.Errorf("GetAvailableConnections() = %d, want less than max_connections %d", got, maxConnections)
+	}
+}
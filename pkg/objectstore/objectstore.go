@@ -0,0 +1,330 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// DefaultEndpoint is used when no endpoint is configured
+	DefaultEndpoint = "s3.amazonaws.com"
+	// DefaultPartSize is the size of every part of a multipart upload except the last one
+	DefaultPartSize int64 = 64 * 1024 * 1024
+	// MinPartSize is the smallest part size accepted by S3 for all but the last part
+	MinPartSize int64 = 5 * 1024 * 1024
+)
+
+// Config holds everything needed to connect to an S3 compatible object store
+type Config struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+// Client uploads and downloads objects of a single bucket
+type Client struct {
+	core     *minio.Core
+	bucket   string
+	partSize int64
+}
+
+// ParseEndpoint splits an endpoint into the host used by the client and whether TLS must be used.
+// Endpoints without a scheme use TLS.
+func ParseEndpoint(endpoint string) (string, bool, error) {
+	if endpoint == "" {
+		return DefaultEndpoint, true, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false, fmt.Errorf("unsupported endpoint scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", false, fmt.Errorf("endpoint %q has no host", endpoint)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return "", false, fmt.Errorf("endpoint %q must not have a path", endpoint)
+	}
+	return u.Host, u.Scheme == "https", nil
+}
+
+// NewClient returns a client for the bucket of the given config
+func NewClient(config Config) (*Client, error) {
+	host, secure, err := ParseEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket must not be empty")
+	}
+	core, err := minio.NewCore(host, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: secure,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		core:     core,
+		bucket:   config.Bucket,
+		partSize: DefaultPartSize,
+	}, nil
+}
+
+// SetPartSize changes the part size of multipart uploads, it can not be below MinPartSize
+func (c *Client) SetPartSize(partSize int64) error {
+	if partSize < MinPartSize {
+		return fmt.Errorf("part size %d is below the minimum of %d", partSize, MinPartSize)
+	}
+	c.partSize = partSize
+	return nil
+}
+
+// Key joins the parts of an object key, ignoring empty parts and surrounding slashes
+func Key(parts ...string) string {
+	cleaned := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.Trim(part, "/")
+		if part != "" {
+			cleaned = append(cleaned, part)
+		}
+	}
+	return path.Join(cleaned...)
+}
+
+// URL returns the s3:// URL of a key in the bucket of the client
+func (c *Client) URL(key string) string {
+	return fmt.Sprintf("s3://%s/%s", c.bucket, key)
+}
+
+// UploadFile uploads a local file to key using a multipart upload. If an incomplete multipart
+// upload for the same key already exists, e.g. because a previous attempt was interrupted,
+// its parts are reused and only the missing ones are sent, as long as every uploaded part holds
+// the same bytes as the file. Otherwise, e.g. because a retried backup job dumped the database
+// again, the stale upload is aborted and a new one is started.
+func (c *Client) UploadFile(ctx context.Context, key string, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size == 0 {
+		// A multipart upload needs at least one non empty part
+		_, err := c.core.PutObject(ctx, c.bucket, key, bytes.NewReader(nil), 0, "", "", minio.PutObjectOptions{
+			ContentType:          "application/octet-stream",
+			DisableContentSha256: true,
+		})
+		return err
+	}
+
+	hashes := []partHash{}
+	for offset := int64(0); offset < size; offset += c.partSize {
+		partSize := min(c.partSize, size-offset)
+		hash, err := hashPart(io.NewSectionReader(file, offset, partSize))
+		if err != nil {
+			return fmt.Errorf("reading part %d of %s: %w", len(hashes)+1, filePath, err)
+		}
+		hash.size = partSize
+		hashes = append(hashes, hash)
+	}
+
+	uploadID, uploaded, err := c.findIncompleteUpload(ctx, key)
+	if err != nil {
+		return err
+	}
+	if uploadID != "" && !partsMatch(uploaded, hashes) {
+		if err := c.core.AbortMultipartUpload(ctx, c.bucket, key, uploadID); err != nil {
+			return fmt.Errorf("aborting stale multipart upload of %s: %w", key, err)
+		}
+		uploadID, uploaded = "", nil
+	}
+	if uploadID == "" {
+		uploadID, err = c.core.NewMultipartUpload(ctx, c.bucket, key, minio.PutObjectOptions{
+			ContentType: "application/octet-stream",
+		})
+		if err != nil {
+			return fmt.Errorf("creating multipart upload for %s: %w", key, err)
+		}
+	}
+
+	parts := []minio.CompletePart{}
+	for i, hash := range hashes {
+		partNumber, offset := i+1, int64(i)*c.partSize
+		if part, found := uploaded[partNumber]; found {
+			parts = append(parts, minio.CompletePart{PartNumber: partNumber, ETag: part.ETag})
+			continue
+		}
+		// The checksums are sent with the part so the object store rejects corrupted parts
+		part, err := c.core.PutObjectPart(ctx, c.bucket, key, uploadID, partNumber,
+			io.NewSectionReader(file, offset, hash.size), hash.size, minio.PutObjectPartOptions{
+				Md5Base64:            base64.StdEncoding.EncodeToString(hash.md5),
+				Sha256Hex:            hash.sha256Hex,
+				DisableContentSha256: true,
+			})
+		if err != nil {
+			return fmt.Errorf("uploading part %d of %s: %w", partNumber, key, err)
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: partNumber, ETag: part.ETag})
+	}
+
+	_, err = c.core.CompleteMultipartUpload(ctx, c.bucket, key, uploadID, parts, minio.PutObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("completing multipart upload of %s: %w", key, err)
+	}
+	return nil
+}
+
+// partHash holds the size and the checksums of a part of a local file
+type partHash struct {
+	size      int64
+	md5       []byte
+	sha256Hex string
+}
+
+// hashPart returns the md5 and the hex encoded sha256 of a part
+func hashPart(reader io.Reader) (partHash, error) {
+	md5Hash := md5.New()
+	sha256Hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(md5Hash, sha256Hash), reader); err != nil {
+		return partHash{}, err
+	}
+	return partHash{md5: md5Hash.Sum(nil), sha256Hex: hex.EncodeToString(sha256Hash.Sum(nil))}, nil
+}
+
+// partsMatch returns whether every uploaded part has the size and the content of the part of the
+// local file with the same number. The ETag of a part is the hex encoded md5 of its content.
+func partsMatch(uploaded map[int]minio.ObjectPart, hashes []partHash) bool {
+	for partNumber, part := range uploaded {
+		if partNumber < 1 || partNumber > len(hashes) {
+			return false
+		}
+		hash := hashes[partNumber-1]
+		etag := strings.ToLower(strings.Trim(part.ETag, `"`))
+		if part.Size != hash.size || etag != hex.EncodeToString(hash.md5) {
+			return false
+		}
+	}
+	return true
+}
+
+// findIncompleteUpload returns the most recent incomplete multipart upload of key and its
+// uploaded parts by part number. The returned upload id is empty if there is none.
+func (c *Client) findIncompleteUpload(ctx context.Context, key string) (string, map[int]minio.ObjectPart, error) {
+	result, err := c.core.ListMultipartUploads(ctx, c.bucket, key, "", "", "", 1000)
+	if err != nil && minio.ToErrorResponse(err).Code == "NoSuchUpload" {
+		// Some S3 implementations answer with an error instead of an empty list
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("listing multipart uploads of %s: %w", key, err)
+	}
+	latest := minio.ObjectMultipartInfo{}
+	for _, upload := range result.Uploads {
+		if upload.Key == key && (latest.UploadID == "" || upload.Initiated.After(latest.Initiated)) {
+			latest = upload
+		}
+	}
+	if latest.UploadID == "" {
+		return "", nil, nil
+	}
+
+	parts := map[int]minio.ObjectPart{}
+	marker := 0
+	for {
+		result, err := c.core.ListObjectParts(ctx, c.bucket, key, latest.UploadID, marker, 1000)
+		if err != nil {
+			return "", nil, fmt.Errorf("listing parts of %s: %w", key, err)
+		}
+		for _, part := range result.ObjectParts {
+			parts[part.PartNumber] = part
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextPartNumberMarker
+	}
+	return latest.UploadID, parts, nil
+}
+
+// DeletePrefix deletes every object below the prefix and aborts the incomplete multipart
+// uploads below it. The prefix must not be empty, it would delete the whole bucket.
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) error {
+	prefix = Key(prefix)
+	if prefix == "" {
+		return fmt.Errorf("prefix must not be empty")
+	}
+	prefix += "/"
+
+	for object := range c.core.Client.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("listing objects below %s: %w", prefix, object.Err)
+		}
+		if err := c.core.Client.RemoveObject(ctx, c.bucket, object.Key, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("deleting %s: %w", object.Key, err)
+		}
+	}
+	for upload := range c.core.Client.ListIncompleteUploads(ctx, c.bucket, prefix, true) {
+		if upload.Err != nil {
+			return fmt.Errorf("listing multipart uploads below %s: %w", prefix, upload.Err)
+		}
+		if err := c.core.AbortMultipartUpload(ctx, c.bucket, upload.Key, upload.UploadID); err != nil {
+			return fmt.Errorf("aborting the multipart upload of %s: %w", upload.Key, err)
+		}
+	}
+	return nil
+}
+
+// GetObject returns the content of the object at key
+func (c *Client) GetObject(ctx context.Context, key string) ([]byte, error) {
+	reader, _, _, err := c.core.GetObject(ctx, c.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// DownloadFile writes the object at key to a local file
+func (c *Client) DownloadFile(ctx context.Context, key string, filePath string) error {
+	reader, _, _, err := c.core.GetObject(ctx, c.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", key, err)
+	}
+	defer reader.Close()
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return fmt.Errorf("downloading %s: %w", key, err)
+	}
+	return file.Close()
+}
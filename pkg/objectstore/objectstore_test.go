@@ -0,0 +1,301 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		wantHost   string
+		wantSecure bool
+		wantErr    bool
+	}{
+		{name: "empty defaults to AWS", endpoint: "", wantHost: DefaultEndpoint, wantSecure: true},
+		{name: "no scheme uses TLS", endpoint: "minio.example.com:9000", wantHost: "minio.example.com:9000", wantSecure: true},
+		{name: "https", endpoint: "https://s3.eu-central-1.amazonaws.com", wantHost: "s3.eu-central-1.amazonaws.com", wantSecure: true},
+		{name: "http", endpoint: "http://minio.minio.svc:9000", wantHost: "minio.minio.svc:9000", wantSecure: false},
+		{name: "trailing slash", endpoint: "http://minio:9000/", wantHost: "minio:9000", wantSecure: false},
+		{name: "unsupported scheme", endpoint: "ftp://minio:9000", wantErr: true},
+		{name: "path", endpoint: "https://minio:9000/bucket", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, secure, err := ParseEndpoint(tc.endpoint)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseEndpoint() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if host != tc.wantHost || secure != tc.wantSecure {
+				t.Errorf("ParseEndpoint() = %q, %t, want %q, %t", host, secure, tc.wantHost, tc.wantSecure)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		parts []string
+		want  string
+	}{
+		{parts: []string{"backups", "default", "odoo", "db.dump"}, want: "backups/default/odoo/db.dump"},
+		{parts: []string{"", "default", "odoo"}, want: "default/odoo"},
+		{parts: []string{"/backups/", "default/", "/odoo"}, want: "backups/default/odoo"},
+	}
+
+	for _, tc := range tests {
+		if got := Key(tc.parts...); got != tc.want {
+			t.Errorf("Key(%v) = %q, want %q", tc.parts, got, tc.want)
+		}
+	}
+}
+
+// newMinIOClient returns a client for the MinIO server configured through MINIO_ENDPOINT,
+// MINIO_ACCESS_KEY, MINIO_SECRET_KEY and MINIO_BUCKET, e.g. the one started by
+// `make test-minio`. The test is skipped when MINIO_ENDPOINT is not set.
+func newMinIOClient(t *testing.T) *Client {
+	t.Helper()
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_ENDPOINT is not set")
+	}
+	bucket := os.Getenv("MINIO_BUCKET")
+	if bucket == "" {
+		bucket = "odoo-operator-test"
+	}
+	client, err := NewClient(Config{
+		Endpoint:  endpoint,
+		Bucket:    bucket,
+		Region:    "us-east-1",
+		AccessKey: os.Getenv("MINIO_ACCESS_KEY"),
+		SecretKey: os.Getenv("MINIO_SECRET_KEY"),
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx := context.Background()
+	exists, err := client.core.BucketExists(ctx, bucket)
+	if err != nil {
+		t.Fatalf("BucketExists() error = %v", err)
+	}
+	if !exists {
+		if err := client.core.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: "us-east-1"}); err != nil {
+			t.Fatalf("MakeBucket() error = %v", err)
+		}
+	}
+	return client
+}
+
+func writeRandomFile(t *testing.T, size int64) (string, []byte) {
+	t.Helper()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(t.TempDir(), "artifact")
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filePath, data
+}
+
+func TestUploadFile(t *testing.T) {
+	client := newMinIOClient(t)
+	if err := client.SetPartSize(MinPartSize); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		size int64
+	}{
+		{name: "empty file", size: 0},
+		{name: "single part", size: 1024},
+		{name: "multiple parts", size: 2*MinPartSize + 1024},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath, data := writeRandomFile(t, tc.size)
+			key := Key("upload", t.Name())
+			if err := client.UploadFile(ctx, key, filePath); err != nil {
+				t.Fatalf("UploadFile() error = %v", err)
+			}
+			got, err := client.GetObject(ctx, key)
+			if err != nil {
+				t.Fatalf("GetObject() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("uploaded object differs from file, got %d bytes, want %d", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestUploadFileResumesIncompleteUpload(t *testing.T) {
+	client := newMinIOClient(t)
+	if err := client.SetPartSize(MinPartSize); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	filePath, data := writeRandomFile(t, 2*MinPartSize+1024)
+	key := Key("resume", t.Name())
+
+	// Simulate an interrupted upload that only sent its first part
+	uploadID, err := client.core.NewMultipartUpload(ctx, client.bucket, key, minio.PutObjectOptions{})
+	if err != nil {
+		t.Fatalf("NewMultipartUpload() error = %v", err)
+	}
+	firstPart, err := client.core.PutObjectPart(ctx, client.bucket, key, uploadID, 1,
+		bytes.NewReader(data[:MinPartSize]), MinPartSize, minio.PutObjectPartOptions{})
+	if err != nil {
+		t.Fatalf("PutObjectPart() error = %v", err)
+	}
+
+	resumedID, parts, err := client.findIncompleteUpload(ctx, key)
+	if err != nil {
+		t.Fatalf("findIncompleteUpload() error = %v", err)
+	}
+	if resumedID != uploadID || strings.Trim(parts[1].ETag, `"`) != strings.Trim(firstPart.ETag, `"`) {
+		t.Fatalf("findIncompleteUpload() = %q, %v, want upload %q with part 1", resumedID, parts, uploadID)
+	}
+
+	if err := client.UploadFile(ctx, key, filePath); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	got, err := client.GetObject(ctx, key)
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("resumed object differs from file, got %d bytes, want %d", len(got), len(data))
+	}
+
+	resumedID, _, err = client.findIncompleteUpload(ctx, key)
+	if err != nil {
+		t.Fatalf("findIncompleteUpload() error = %v", err)
+	}
+	if resumedID != "" {
+		t.Errorf("upload %q is still incomplete after UploadFile()", resumedID)
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	client := newMinIOClient(t)
+	ctx := context.Background()
+
+	filePath, _ := writeRandomFile(t, 1024)
+	prefix := Key("delete", t.Name())
+	kept := Key("delete", t.Name()+"-kept", "manifest.json")
+	for _, key := range []string{Key(prefix, "db.dump"), Key(prefix, "nested", "manifest.json"), kept} {
+		if err := client.UploadFile(ctx, key, filePath); err != nil {
+			t.Fatalf("UploadFile() error = %v", err)
+		}
+	}
+	// An interrupted upload below the prefix is aborted as well
+	if _, err := client.core.NewMultipartUpload(ctx, client.bucket, Key(prefix, "filestore.tar.zst"), minio.PutObjectOptions{}); err != nil {
+		t.Fatalf("NewMultipartUpload() error = %v", err)
+	}
+
+	if err := client.DeletePrefix(ctx, prefix); err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+	for object := range client.core.Client.ListObjects(ctx, client.bucket, minio.ListObjectsOptions{Prefix: prefix + "/", Recursive: true}) {
+		t.Errorf("object %s is left below the prefix", object.Key)
+	}
+	if uploadID, _, err := client.findIncompleteUpload(ctx, Key(prefix, "filestore.tar.zst")); err != nil || uploadID != "" {
+		t.Errorf("findIncompleteUpload() = %q, %v, want the upload to be aborted", uploadID, err)
+	}
+	if _, err := client.GetObject(ctx, kept); err != nil {
+		t.Errorf("GetObject(%s) error = %v, objects sharing the prefix as a string must be kept", kept, err)
+	}
+
+	if err := client.DeletePrefix(ctx, "/"); err == nil {
+		t.Errorf("DeletePrefix() of the whole bucket succeeded, want an error")
+	}
+}
+
+func TestPartsMatch(t *testing.T) {
+	first, err := hashPart(strings.NewReader("first part"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.size = 10
+	second, err := hashPart(strings.NewReader("last"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.size = 4
+	hashes := []partHash{first, second}
+	firstETag := `"` + hex.EncodeToString(first.md5) + `"`
+
+	tests := []struct {
+		name     string
+		uploaded map[int]minio.ObjectPart
+		want     bool
+	}{
+		{name: "no parts", uploaded: map[int]minio.ObjectPart{}, want: true},
+		{name: "same content", uploaded: map[int]minio.ObjectPart{1: {PartNumber: 1, Size: 10, ETag: firstETag}}, want: true},
+		{name: "same size, other content", uploaded: map[int]minio.ObjectPart{1: {PartNumber: 1, Size: 10, ETag: `"0123456789abcdef0123456789abcdef"`}}},
+		{name: "other size", uploaded: map[int]minio.ObjectPart{1: {PartNumber: 1, Size: 9, ETag: firstETag}}},
+		{name: "part beyond the file", uploaded: map[int]minio.ObjectPart{3: {PartNumber: 3, Size: 4, ETag: firstETag}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := partsMatch(tc.uploaded, hashes); got != tc.want {
+				t.Errorf("partsMatch() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUploadFileRestartsStaleUpload(t *testing.T) {
+	client := newMinIOClient(t)
+	if err := client.SetPartSize(MinPartSize); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	filePath, data := writeRandomFile(t, 2*MinPartSize+1024)
+	_, staleData := writeRandomFile(t, MinPartSize)
+	key := Key("restart", t.Name())
+
+	// Simulate an interrupted upload of an earlier dump, its first part has the size but not the content of the file
+	uploadID, err := client.core.NewMultipartUpload(ctx, client.bucket, key, minio.PutObjectOptions{})
+	if err != nil {
+		t.Fatalf("NewMultipartUpload() error = %v", err)
+	}
+	if _, err := client.core.PutObjectPart(ctx, client.bucket, key, uploadID, 1,
+		bytes.NewReader(staleData), MinPartSize, minio.PutObjectPartOptions{}); err != nil {
+		t.Fatalf("PutObjectPart() error = %v", err)
+	}
+
+	if err := client.UploadFile(ctx, key, filePath); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	got, err := client.GetObject(ctx, key)
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("uploaded object differs from file, the stale part was reused")
+	}
+}
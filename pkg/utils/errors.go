@@ -16,3 +16,10 @@ var ErrFailedToGetDbPassword = errors.New("failed to get database password")
 var ErrFailedToGetDbName = errors.New("failed to get database name")
 var ErrFailedToGetDbSslMode = errors.New("failed to get database ssl mode")
 var ErrFailedToGetDbMaxConns = errors.New("failed to get database max connections")
+var ErrFailedToGetDbReplicaHost = errors.New("failed to get database replica host")
+var ErrFailedToGetDbReplicaPort = errors.New("failed to get database replica port")
+var ErrFailedToGetCNPGCluster = errors.New("failed to get CloudNativePG cluster")
+
+// Job related errors
+var ErrJobTerminationMessageNotFound = errors.New("job termination message not found")
+var ErrJobImageDigestNotFound = errors.New("job image digest not found")
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetJobTerminationMessage returns the termination message of the first container
+// of a job pod that terminated successfully
+func GetJobTerminationMessage(c client.Client, ctx context.Context, job *batchv1.Job) (string, error) {
+	pods := &corev1.PodList{}
+	err := c.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			terminated := containerStatus.State.Terminated
+			if terminated != nil && terminated.ExitCode == 0 && terminated.Message != "" {
+				return terminated.Message, nil
+			}
+		}
+	}
+	return "", ErrJobTerminationMessageNotFound
+}
+
+// IsJobFailed returns true once the job has given up retrying
+func IsJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteJob deletes the job together with its pods, a job that is already gone is not an error
+func DeleteJob(c client.Client, ctx context.Context, job *batchv1.Job) error {
+	err := c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// GetJobImageDigest returns the digest of the image the named container of a job pod ran with,
+// or the image ID reported by the kubelet when it holds no digest
+func GetJobImageDigest(c client.Client, ctx context.Context, job *batchv1.Job, containerName string) (string, error) {
+	pods := &corev1.PodList{}
+	err := c.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if digest, found := GetPodImageDigest(&pod, containerName); found {
+			return digest, nil
+		}
+	}
+	return "", ErrJobImageDigestNotFound
+}
+
+// GetPodImageDigest returns the digest of the image the named container of the pod runs or ran
+// with, or the image ID reported by the kubelet when it holds no digest. It returns false while
+// the image is not pulled yet.
+func GetPodImageDigest(pod *corev1.Pod, containerName string) (string, bool) {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != containerName || containerStatus.ImageID == "" {
+			continue
+		}
+		if _, digest, found := strings.Cut(containerStatus.ImageID, "@"); found {
+			return digest, true
+		}
+		return containerStatus.ImageID, true
+	}
+	return "", false
+}
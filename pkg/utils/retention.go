@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RetentionItem is a named point in time, e.g. a backup and the time it was taken
+type RetentionItem struct {
+	Name string
+	Time time.Time
+}
+
+// RetentionPolicy is a grandfather-father-son retention policy. Each field is the number
+// of most recent days, ISO weeks and months for which the newest item is kept.
+type RetentionPolicy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+// ApplyRetentionPolicy splits items into the ones to keep and the ones to prune.
+// For every period (day, ISO week, month) the newest item of that period is a candidate,
+// and the candidates of the most recent periods are kept up to the count configured in
+// the policy. An item kept by any of the periods is kept. Both returned slices are sorted
+// from newest to oldest. All times are compared in UTC.
+func ApplyRetentionPolicy(items []RetentionItem, policy RetentionPolicy) (keep []RetentionItem, prune []RetentionItem) {
+	sorted := make([]RetentionItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+
+	kept := make(map[int]struct{}, len(sorted))
+	markNewestPerPeriod := func(count int, period func(time.Time) string) {
+		seen := make(map[string]struct{}, count)
+		for i, item := range sorted {
+			if len(seen) >= count {
+				return
+			}
+			key := period(item.Time.UTC())
+			if _, found := seen[key]; found {
+				continue
+			}
+			seen[key] = struct{}{}
+			kept[i] = struct{}{}
+		}
+	}
+
+	markNewestPerPeriod(policy.Daily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	markNewestPerPeriod(policy.Weekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	markNewestPerPeriod(policy.Monthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	for i, item := range sorted {
+		if _, found := kept[i]; found {
+			keep = append(keep, item)
+		} else {
+			prune = append(prune, item)
+		}
+	}
+	return keep, prune
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// dailyItems returns one item per day at 02:00 UTC, starting at start and going back in time
+func dailyItems(start time.Time, days int) []RetentionItem {
+	items := make([]RetentionItem, 0, days)
+	for i := 0; i < days; i++ {
+		t := start.AddDate(0, 0, -i)
+		items = append(items, RetentionItem{Name: t.Format("2006-01-02"), Time: t})
+	}
+	return items
+}
+
+func names(items []RetentionItem) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.Name)
+	}
+	return result
+}
+
+func TestApplyRetentionPolicy(t *testing.T) {
+	// Sunday 2025-03-16, so ISO weeks end on the day itself
+	start := time.Date(2025, time.March, 16, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		items    []RetentionItem
+		policy   RetentionPolicy
+		wantKeep []string
+	}{
+		{
+			name:     "no items",
+			items:    nil,
+			policy:   RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12},
+			wantKeep: nil,
+		},
+		{
+			name:     "empty policy prunes everything",
+			items:    dailyItems(start, 3),
+			policy:   RetentionPolicy{},
+			wantKeep: nil,
+		},
+		{
+			name:     "daily keeps the newest days only",
+			items:    dailyItems(start, 5),
+			policy:   RetentionPolicy{Daily: 3},
+			wantKeep: []string{"2025-03-16", "2025-03-15", "2025-03-14"},
+		},
+		{
+			name: "daily keeps only the newest item of a day",
+			items: []RetentionItem{
+				{Name: "morning", Time: start},
+				{Name: "evening", Time: start.Add(18 * time.Hour)},
+				{Name: "yesterday", Time: start.AddDate(0, 0, -1)},
+			},
+			policy:   RetentionPolicy{Daily: 2},
+			wantKeep: []string{"evening", "yesterday"},
+		},
+		{
+			name:  "weekly keeps the newest item of each ISO week",
+			items: dailyItems(start, 21),
+			// 2025-03-16, 2025-03-09 and 2025-03-02 are the Sundays closing each week
+			policy:   RetentionPolicy{Weekly: 3},
+			wantKeep: []string{"2025-03-16", "2025-03-09", "2025-03-02"},
+		},
+		{
+			name:     "monthly keeps the newest item of each month",
+			items:    dailyItems(start, 60),
+			policy:   RetentionPolicy{Monthly: 3},
+			wantKeep: []string{"2025-03-16", "2025-02-28", "2025-01-31"},
+		},
+		{
+			name:  "grandfather-father-son keeps the union of all periods",
+			items: dailyItems(start, 60),
+			policy: RetentionPolicy{
+				Daily:   3,
+				Weekly:  2,
+				Monthly: 2,
+			},
+			wantKeep: []string{"2025-03-16", "2025-03-15", "2025-03-14", "2025-03-09", "2025-02-28"},
+		},
+		{
+			name: "unsorted input is handled",
+			items: []RetentionItem{
+				{Name: "old", Time: start.AddDate(0, 0, -2)},
+				{Name: "new", Time: start},
+				{Name: "mid", Time: start.AddDate(0, 0, -1)},
+			},
+			policy:   RetentionPolicy{Daily: 1},
+			wantKeep: []string{"new"},
+		},
+		{
+			name: "times are compared in UTC",
+			items: []RetentionItem{
+				// 2025-03-16 01:00 in UTC+2 is still 2025-03-15 in UTC
+				{Name: "late", Time: time.Date(2025, time.March, 16, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))},
+				{Name: "early", Time: time.Date(2025, time.March, 15, 1, 0, 0, 0, time.UTC)},
+			},
+			policy:   RetentionPolicy{Daily: 2},
+			wantKeep: []string{"late"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keep, prune := ApplyRetentionPolicy(tc.items, tc.policy)
+
+			if got := fmt.Sprint(names(keep)); got != fmt.Sprint(emptyIfNil(tc.wantKeep)) {
+				t.Errorf("keep = %v, want %v", got, tc.wantKeep)
+			}
+
+			// Everything that is not kept must be pruned, newest first
+			wantPrune := []string{}
+			for _, name := range names(sortedNewestFirst(tc.items)) {
+				if !slices.Contains(tc.wantKeep, name) {
+					wantPrune = append(wantPrune, name)
+				}
+			}
+			if got := fmt.Sprint(names(prune)); got != fmt.Sprint(wantPrune) {
+				t.Errorf("prune = %v, want %v", got, wantPrune)
+			}
+		})
+	}
+}
+
+func emptyIfNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func sortedNewestFirst(items []RetentionItem) []RetentionItem {
+	sorted := make([]RetentionItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+	return sorted
+}
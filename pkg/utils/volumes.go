@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetPvcNodeName returns the node the PVC is mounted on by a running pod, or the node its volume
+// is still attached to when no pod mounts it. It is empty when the volume is not attached.
+func GetPvcNodeName(c client.Client, ctx context.Context, namespace string, pvcName string) (string, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName {
+				return pod.Spec.NodeName, nil
+			}
+		}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: namespace}, pvc); err != nil {
+		return "", err
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", nil
+	}
+	attachments := &storagev1.VolumeAttachmentList{}
+	if err := c.List(ctx, attachments); err != nil {
+		return "", err
+	}
+	for _, attachment := range attachments.Items {
+		source := attachment.Spec.Source.PersistentVolumeName
+		if source != nil && *source == pvc.Spec.VolumeName && attachment.Status.Attached && attachment.DeletionTimestamp == nil {
+			return attachment.Spec.NodeName, nil
+		}
+	}
+	return "", nil
+}